@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"connectrpc.com/connect"
@@ -51,10 +52,21 @@ var (
 	ErrSecretsEngineNotAvailable = errors.New("secrets engine is not available")
 )
 
+const (
+	// SocketPathEnv is consulted by [New] for the engine socket path when
+	// no [WithSocketPath] or [WithDialContext] option is provided.
+	SocketPathEnv = "SECRETS_SOCK"
+	// DockerSocketPathEnv is consulted by [New] after [SocketPathEnv].
+	DockerSocketPathEnv = "DOCKER_SECRETS_SOCKET"
+)
+
 var _ secrets.Resolver = &client{}
 
 type Option func(c *config) error
 
+// WithSocketPath sets the unix socket the client dials to reach the engine.
+//
+// It takes precedence over the socket path environment variables, see [New].
 func WithSocketPath(path string) Option {
 	return func(s *config) error {
 		if path == "" {
@@ -112,12 +124,27 @@ func WithResponseTimeout(responseTimeout time.Duration) Option {
 	}
 }
 
+// WithReconnect makes the client retry a request once on a new connection
+// when it never reached the engine, e.g. because the engine was restarted and
+// the connection kept alive by the client is dead.
+//
+// Only the requests without side effects, like resolving secrets, listing
+// plugins or getting the version, are retried. Requests are retried at most
+// once, never after a timeout and never after the request context is done.
+func WithReconnect(reconnect bool) Option {
+	return func(s *config) error {
+		s.reconnect = reconnect
+		return nil
+	}
+}
+
 type dial func(ctx context.Context, network, addr string) (net.Conn, error)
 
 type config struct {
 	dialContext     dial
 	requestTimeout  time.Duration
 	responseTimeout time.Duration
+	reconnect       bool
 }
 
 var (
@@ -142,6 +169,30 @@ func (c client) GetSecrets(ctx context.Context, pattern secrets.Pattern) ([]secr
 	return envelopes, nil
 }
 
+// GetSecretsMany resolves several patterns in a single request and returns
+// the union of the secrets matching any of them, sorted with
+// [secrets.SortEnvelopes]. A secret matched by more than one pattern is
+// returned once. Engines that cannot resolve several patterns at once are
+// sent a request per pattern, concurrently.
+//
+// Patterns matching no secret are ignored, [ErrSecretNotFound] is only returned
+// when none of the patterns matched a secret.
+func (c client) GetSecretsMany(ctx context.Context, patterns ...secrets.Pattern) ([]secrets.Envelope, error) {
+	if len(patterns) == 0 {
+		return nil, errors.New("at least one pattern is required")
+	}
+	if m, ok := c.resolverClient.(resolver.ManyResolver); ok {
+		envelopes, err := m.GetSecretsMany(ctx, patterns...)
+		if !errors.Is(err, resolver.ErrPatternsUnsupported) {
+			if isDialError(err) {
+				return nil, fmt.Errorf("%w: %w", ErrSecretsEngineNotAvailable, err)
+			}
+			return envelopes, err
+		}
+	}
+	return secrets.GetSecretsMany(ctx, c, patterns...)
+}
+
 func (c client) Version(ctx context.Context) (DaemonVersion, error) {
 	resp, err := c.versionClient.GetVersion(ctx, connect.NewRequest(healthv1.GetVersionRequest_builder{}.Build()))
 	if isDialError(err) {
@@ -161,8 +212,16 @@ func (c client) Version(ctx context.Context) (DaemonVersion, error) {
 type Client interface {
 	secrets.Resolver
 
+	// GetSecretsMany returns the secrets matching any of the patterns in a
+	// single request.
+	GetSecretsMany(ctx context.Context, patterns ...secrets.Pattern) ([]secrets.Envelope, error)
+
 	// Version returns the name and version reported by the daemon.
 	Version(ctx context.Context) (DaemonVersion, error)
+
+	// GetSecretToMemfd writes the value of the secret into an anonymous file
+	// and returns it positioned at its start.
+	GetSecretToMemfd(ctx context.Context, id secrets.ID) (*os.File, error)
 }
 
 type PluginManagement interface {
@@ -190,6 +249,23 @@ func isDialError(err error) bool {
 	return false
 }
 
+// socketPath resolves the socket path used when no dial option was provided.
+func socketPath() string {
+	for _, env := range []string{SocketPathEnv, DockerSocketPathEnv} {
+		if path := os.Getenv(env); path != "" {
+			return path
+		}
+	}
+	return api.DaemonSocketPath()
+}
+
+// New creates a new [Client] connected to the secrets engine.
+//
+// The socket path is resolved in the following order:
+//  1. [WithSocketPath] or [WithDialContext], if provided
+//  2. the [SocketPathEnv] environment variable (SECRETS_SOCK)
+//  3. the [DockerSocketPathEnv] environment variable (DOCKER_SECRETS_SOCKET)
+//  4. [api.DaemonSocketPath]
 func New(options ...Option) (Client, error) {
 	cfg := &config{
 		requestTimeout:  api.DefaultClientRequestTimeout,
@@ -201,7 +277,7 @@ func New(options ...Option) (Client, error) {
 		}
 	}
 	if cfg.dialContext == nil {
-		cfg.dialContext = dialFromPath(api.DaemonSocketPath())
+		cfg.dialContext = dialFromPath(socketPath())
 	}
 	c := &http.Client{
 		Transport: &http.Transport{
@@ -224,10 +300,14 @@ func New(options ...Option) (Client, error) {
 		// it can be overwritten with [WithTimeout]
 		Timeout: cfg.requestTimeout,
 	}
+	var httpClient connect.HTTPClient = c
+	if cfg.reconnect {
+		httpClient = &reconnectingClient{client: c}
+	}
 	return &client{
-		resolverClient: resolver.NewResolverClient(c),
-		engineClient:   pluginsv1connect.NewPluginManagementServiceClient(c, "http://unix"),
-		versionClient:  healthv1connect.NewVersionServiceClient(c, "http://unix"),
+		resolverClient: resolver.NewResolverClient(httpClient),
+		engineClient:   pluginsv1connect.NewPluginManagementServiceClient(httpClient, "http://unix"),
+		versionClient:  healthv1connect.NewVersionServiceClient(httpClient, "http://unix"),
 	}, nil
 }
 
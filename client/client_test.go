@@ -16,10 +16,16 @@ package client
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -33,6 +39,9 @@ import (
 	"github.com/docker/secrets-engine/x/api/health/v1/healthv1connect"
 	pluginsv1 "github.com/docker/secrets-engine/x/api/plugins/v1"
 	"github.com/docker/secrets-engine/x/api/plugins/v1/pluginsv1connect"
+	"github.com/docker/secrets-engine/x/api/resolver"
+	resolverv1 "github.com/docker/secrets-engine/x/api/resolver/v1"
+	"github.com/docker/secrets-engine/x/api/resolver/v1/resolverv1connect"
 	"github.com/docker/secrets-engine/x/secrets"
 	"github.com/docker/secrets-engine/x/testhelper"
 )
@@ -248,6 +257,41 @@ func Test_Version(t *testing.T) {
 	})
 }
 
+func Test_socketPathFromEnv(t *testing.T) {
+	t.Run("defaults to daemon socket", func(t *testing.T) {
+		t.Setenv(SocketPathEnv, "")
+		t.Setenv(DockerSocketPathEnv, "")
+		assert.Equal(t, api.DaemonSocketPath(), socketPath())
+	})
+	t.Run("DOCKER_SECRETS_SOCKET", func(t *testing.T) {
+		t.Setenv(SocketPathEnv, "")
+		t.Setenv(DockerSocketPathEnv, "/run/docker-secrets.sock")
+		assert.Equal(t, "/run/docker-secrets.sock", socketPath())
+	})
+	t.Run("SECRETS_SOCK takes precedence over DOCKER_SECRETS_SOCKET", func(t *testing.T) {
+		t.Setenv(SocketPathEnv, "/run/secrets.sock")
+		t.Setenv(DockerSocketPathEnv, "/run/docker-secrets.sock")
+		assert.Equal(t, "/run/secrets.sock", socketPath())
+	})
+	t.Run("New dials the socket from the environment", func(t *testing.T) {
+		socket := mockVersionEngine(t, "v1.2.3", "2026-03-26", "abc1234")
+		t.Setenv(SocketPathEnv, socket)
+		c, err := New()
+		require.NoError(t, err)
+		dv, err := c.Version(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "v1.2.3", dv.Version.String())
+	})
+	t.Run("WithSocketPath overrides the environment", func(t *testing.T) {
+		socket := mockVersionEngine(t, "v1.2.3", "2026-03-26", "abc1234")
+		t.Setenv(SocketPathEnv, testhelper.RandomShortSocketName())
+		c, err := New(WithSocketPath(socket))
+		require.NoError(t, err)
+		_, err = c.Version(t.Context())
+		require.NoError(t, err)
+	})
+}
+
 func TestSecretsEngineUnavailable(t *testing.T) {
 	socketPath := testhelper.RandomShortSocketName()
 	client, err := New(WithSocketPath(socketPath))
@@ -260,6 +304,102 @@ func TestSecretsEngineUnavailable(t *testing.T) {
 	require.ErrorIs(t, err, ErrSecretsEngineNotAvailable)
 }
 
+type errResolver struct {
+	err error
+}
+
+func (e errResolver) GetSecrets(context.Context, secrets.Pattern) ([]secrets.Envelope, error) {
+	return nil, e.err
+}
+
+func TestGetSecretsMany(t *testing.T) {
+	c := client{resolverClient: testhelper.MockResolver{Store: map[secrets.ID]string{
+		secrets.MustParseID("db/password"): "pw",
+		secrets.MustParseID("db/user"):     "user",
+		secrets.MustParseID("api/token"):   "token",
+	}}}
+	t.Run("returns the union without duplicates", func(t *testing.T) {
+		envelopes, err := c.GetSecretsMany(t.Context(),
+			secrets.MustParsePattern("db/password"),
+			secrets.MustParsePattern("api/token"),
+			secrets.MustParsePattern("db/*"),
+		)
+		require.NoError(t, err)
+		var ids []string
+		for _, e := range envelopes {
+			ids = append(ids, e.ID.String())
+		}
+		assert.Equal(t, []string{"api/token", "db/password", "db/user"}, ids)
+	})
+	t.Run("no match", func(t *testing.T) {
+		_, err := c.GetSecretsMany(t.Context(), secrets.MustParsePattern("unknown"))
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+	t.Run("no pattern", func(t *testing.T) {
+		_, err := c.GetSecretsMany(t.Context())
+		assert.Error(t, err)
+	})
+	t.Run("errors other than not found are returned", func(t *testing.T) {
+		c := client{resolverClient: errResolver{err: errors.New("boom")}}
+		_, err := c.GetSecretsMany(t.Context(), secrets.MustParsePattern("**"))
+		assert.ErrorContains(t, err, "boom")
+	})
+
+	ids := func(envelopes []secrets.Envelope) []string {
+		var ids []string
+		for _, e := range envelopes {
+			ids = append(ids, e.ID.String())
+		}
+		return ids
+	}
+	engine := func(t *testing.T, h resolverv1connect.ResolverServiceHandler) (Client, *atomic.Int32) {
+		t.Helper()
+		var requests atomic.Int32
+		pattern, resolverHandler := resolverv1connect.NewResolverServiceHandler(h)
+		socketPath := testhelper.RandomShortSocketName()
+		muxServer(t, socketPath, []handler{wrapHandler(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			resolverHandler.ServeHTTP(w, r)
+		}))})
+		c, err := New(WithSocketPath(socketPath))
+		require.NoError(t, err)
+		return c, &requests
+	}
+	patterns := []secrets.Pattern{
+		secrets.MustParsePattern("db/password"),
+		secrets.MustParsePattern("api/token"),
+		secrets.MustParsePattern("db/*"),
+	}
+	t.Run("resolves all the patterns in a single request", func(t *testing.T) {
+		c, requests := engine(t, resolver.NewResolverHandler(c.resolverClient))
+		envelopes, err := c.GetSecretsMany(t.Context(), patterns...)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api/token", "db/password", "db/user"}, ids(envelopes))
+		assert.EqualValues(t, 1, requests.Load())
+	})
+	t.Run("falls back to a request per pattern on older engines", func(t *testing.T) {
+		c, requests := engine(t, legacyResolverService{resolver.NewResolverHandler(c.resolverClient)})
+		envelopes, err := c.GetSecretsMany(t.Context(), patterns...)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api/token", "db/password", "db/user"}, ids(envelopes))
+		assert.EqualValues(t, 1+len(patterns), requests.Load())
+	})
+}
+
+// legacyResolverService behaves like an engine predating the patterns field
+// of GetSecretsRequest, which only resolves the singular pattern.
+type legacyResolverService struct {
+	resolverv1connect.ResolverServiceHandler
+}
+
+func (l legacyResolverService) GetSecrets(ctx context.Context, req *connect.Request[resolverv1.GetSecretsRequest]) (*connect.Response[resolverv1.GetSecretsResponse], error) {
+	if _, err := secrets.ParsePattern(req.Msg.GetPattern()); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	req.Msg.SetPatterns(nil)
+	return l.ResolverServiceHandler.GetSecrets(ctx, req)
+}
+
 func TestIsDialError(t *testing.T) {
 	require.True(t, isDialError(&net.OpError{
 		Op: "dial",
@@ -269,3 +409,122 @@ func TestIsDialError(t *testing.T) {
 	}))
 	require.False(t, isDialError(nil))
 }
+
+// staleConn simulates a connection to an engine that was restarted without
+// the client noticing: once stale, reads fail as if the peer closed it.
+type staleConn struct {
+	net.Conn
+	stale *atomic.Bool
+}
+
+func (s *staleConn) Read(b []byte) (int, error) {
+	n, err := s.Conn.Read(b)
+	if s.stale.Load() {
+		return 0, io.EOF
+	}
+	return n, err
+}
+
+func TestReconnect(t *testing.T) {
+	socketPath := mockVersionEngine(t, "v1.0.0", "", "")
+	newClient := func(reconnect bool) (Client, func()) {
+		var mu sync.Mutex
+		var conns []*atomic.Bool
+		dial := dialFromPath(socketPath)
+		c, err := New(WithReconnect(reconnect), WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			stale := &atomic.Bool{}
+			conns = append(conns, stale)
+			return &staleConn{Conn: conn, stale: stale}, nil
+		}))
+		require.NoError(t, err)
+		restartEngine := func() {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, stale := range conns {
+				stale.Store(true)
+			}
+		}
+		return c, restartEngine
+	}
+
+	t.Run("recovers on the next call", func(t *testing.T) {
+		c, restartEngine := newClient(true)
+		_, err := c.Version(t.Context())
+		require.NoError(t, err)
+
+		restartEngine()
+		dv, err := c.Version(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "v1.0.0", dv.Version.String())
+	})
+	t.Run("fails without reconnect", func(t *testing.T) {
+		c, restartEngine := newClient(false)
+		_, err := c.Version(t.Context())
+		require.NoError(t, err)
+
+		restartEngine()
+		_, err = c.Version(t.Context())
+		assert.Error(t, err)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestReconnectRetries(t *testing.T) {
+	attempts := func(t *testing.T, procedure string, err error) int {
+		t.Helper()
+		var calls int
+		r := &reconnectingClient{client: &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			calls++
+			return nil, err
+		})}}
+		req, reqErr := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://unix"+procedure, strings.NewReader("{}"))
+		require.NoError(t, reqErr)
+		_, doErr := r.Do(req)
+		assert.Error(t, doErr)
+		return calls
+	}
+	refused := &net.OpError{Op: "dial", Net: "unix", Err: syscall.ECONNREFUSED}
+
+	t.Run("refused idempotent requests are retried", func(t *testing.T) {
+		assert.Equal(t, 2, attempts(t, resolverv1connect.ResolverServiceGetSecretsProcedure, refused))
+	})
+	t.Run("non-idempotent requests are not retried", func(t *testing.T) {
+		assert.Equal(t, 1, attempts(t, pluginsv1connect.PluginManagementServiceEnablePluginProcedure, refused))
+	})
+	t.Run("timeouts are not retried", func(t *testing.T) {
+		assert.Equal(t, 1, attempts(t, resolverv1connect.ResolverServiceGetSecretsProcedure, os.ErrDeadlineExceeded))
+	})
+	t.Run("errors on new connections are not retried", func(t *testing.T) {
+		assert.Equal(t, 1, attempts(t, resolverv1connect.ResolverServiceGetSecretsProcedure, io.EOF))
+	})
+}
+
+func TestGetSecretToMemfd(t *testing.T) {
+	c := client{resolverClient: testhelper.MockResolver{Store: map[secrets.ID]string{
+		secrets.MustParseID("db/password"):     "pw",
+		secrets.MustParseID("db/password/old"): "old",
+	}}}
+	t.Run("the file contains the secret value", func(t *testing.T) {
+		f, err := c.GetSecretToMemfd(t.Context(), secrets.MustParseID("db/password"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = f.Close() })
+		value, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "pw", string(value))
+	})
+	t.Run("unknown secret", func(t *testing.T) {
+		_, err := c.GetSecretToMemfd(t.Context(), secrets.MustParseID("unknown"))
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+}
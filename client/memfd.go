@@ -0,0 +1,80 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docker/secrets-engine/x/secrets"
+)
+
+// GetSecretToMemfd resolves the secret with the given ID and writes its value
+// into an anonymous file, returning the file positioned at its start.
+//
+// On Linux the file is created with memfd_create(2) and never touches disk.
+// It is sealed once written, so that its content cannot be changed.
+// On other platforms a temporary file is created and unlinked right away,
+// which keeps it out of the filesystem namespace but not off the disk. As
+// Windows cannot unlink open files, there the file is created with
+// FILE_FLAG_DELETE_ON_CLOSE instead and removed once its last handle is closed.
+//
+// The returned file can be handed to a child process through
+// [os/exec.Cmd.ExtraFiles]. The caller is responsible for closing it.
+func (c client) GetSecretToMemfd(ctx context.Context, id secrets.ID) (*os.File, error) {
+	// a valid ID is always a valid pattern that only matches itself
+	pattern, err := secrets.ParsePattern(id.String())
+	if err != nil {
+		return nil, err
+	}
+	envelopes, err := c.GetSecrets(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, envelope := range envelopes {
+			clear(envelope.Value)
+		}
+	}()
+
+	for _, envelope := range envelopes {
+		if envelope.ID.String() == id.String() {
+			return writeAnonymousFile(id.String(), envelope.Value)
+		}
+	}
+	return nil, secrets.ErrNotFound
+}
+
+func writeAnonymousFile(name string, value []byte) (*os.File, error) {
+	f, err := newAnonymousFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("creating anonymous file: %w", err)
+	}
+	if _, err := f.Write(value); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("writing secret: %w", err)
+	}
+	if err := sealAnonymousFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("sealing anonymous file: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
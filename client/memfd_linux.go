@@ -0,0 +1,55 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxMemfdName is the longest name memfd_create(2) accepts, the kernel
+// prefixing it with "memfd:" within NAME_MAX.
+const maxMemfdName = 249
+
+func newAnonymousFile(name string) (*os.File, error) {
+	// the name only shows up in /proc/<pid>/fd, so long IDs are truncated
+	name = "secret:" + name
+	if len(name) > maxMemfdName {
+		name = name[:maxMemfdName]
+	}
+	fd, err := unix.MemfdCreate(name, unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "memfd:"+name), nil
+}
+
+// sealAnonymousFile prevents the content of the memfd from being changed,
+// including by the processes it is handed to.
+func sealAnonymousFile(f *os.File) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sealErr error
+	err = conn.Control(func(fd uintptr) {
+		_, sealErr = unix.FcntlInt(fd, unix.F_ADD_SEALS, unix.F_SEAL_WRITE|unix.F_SEAL_GROW|unix.F_SEAL_SHRINK)
+	})
+	if err != nil {
+		return err
+	}
+	return sealErr
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestWriteAnonymousFile(t *testing.T) {
+	t.Run("long names are truncated", func(t *testing.T) {
+		f, err := writeAnonymousFile(strings.Repeat("a/", 200)+"b", []byte("pw"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = f.Close() })
+		value, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "pw", string(value))
+	})
+	t.Run("the file is sealed", func(t *testing.T) {
+		f, err := writeAnonymousFile("db/password", []byte("pw"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = f.Close() })
+
+		seals, err := unix.FcntlInt(f.Fd(), unix.F_GET_SEALS, 0)
+		require.NoError(t, err)
+		assert.Equal(t, unix.F_SEAL_WRITE|unix.F_SEAL_GROW|unix.F_SEAL_SHRINK, seals)
+		_, err = f.WriteAt([]byte("x"), 0)
+		assert.ErrorIs(t, err, unix.EPERM)
+		assert.ErrorIs(t, f.Truncate(0), unix.EPERM)
+
+		// the seals hold for the processes the file is handed to
+		reopened, err := os.OpenFile("/proc/self/fd/"+strconv.Itoa(int(f.Fd())), os.O_WRONLY, 0)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = reopened.Close() })
+		_, err = reopened.Write([]byte("x"))
+		assert.ErrorIs(t, err, unix.EPERM)
+	})
+}
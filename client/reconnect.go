@@ -0,0 +1,99 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"syscall"
+
+	"github.com/docker/secrets-engine/x/api/health/v1/healthv1connect"
+	"github.com/docker/secrets-engine/x/api/plugins/v1/pluginsv1connect"
+	"github.com/docker/secrets-engine/x/api/resolver/v1/resolverv1connect"
+)
+
+// idempotentProcedures are the procedures a request can be replayed for,
+// as they have no side effect on the engine.
+var idempotentProcedures = map[string]bool{
+	resolverv1connect.ResolverServiceGetSecretsProcedure:         true,
+	pluginsv1connect.PluginManagementServiceListPluginsProcedure: true,
+	healthv1connect.VersionServiceGetVersionProcedure:            true,
+}
+
+// reconnectingClient retries a request once on a new connection when it
+// never reached the engine, e.g. because the engine restarted and the pooled
+// connection is dead. Only the requests of [idempotentProcedures] are
+// retried.
+type reconnectingClient struct {
+	client *http.Client
+}
+
+func (r *reconnectingClient) Do(req *http.Request) (*http.Response, error) {
+	if !idempotentProcedures[req.URL.Path] {
+		return r.client.Do(req)
+	}
+
+	// the body is consumed by the first attempt, keep a copy to replay it
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var reused bool
+	first := req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused = info.Reused
+		},
+	}))
+	if body != nil {
+		first.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.client.Do(first)
+	if err == nil || req.Context().Err() != nil || !neverReachedEngine(err, reused) {
+		return resp, err
+	}
+
+	// drop the pooled connections so that the retry dials the engine again
+	r.client.CloseIdleConnections()
+	retry := req.Clone(req.Context())
+	if body != nil {
+		retry.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return r.client.Do(retry)
+}
+
+// neverReachedEngine reports whether err means that the request could not
+// have been handled by the engine: the connection was refused, or a pooled
+// connection was closed by the engine. Timeouts are never retried, the
+// request may still be running.
+func neverReachedEngine(err error, reused bool) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	return reused && (errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET))
+}
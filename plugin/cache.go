@@ -0,0 +1,185 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/docker/secrets-engine/x/secrets"
+)
+
+const (
+	// maxStale bounds how long past its ttl an entry is served when
+	// refreshing it fails. The entry is dropped afterwards.
+	maxStale = time.Hour
+	// maxCacheEntries bounds the number of patterns cached by
+	// [CachingResolver].
+	maxCacheEntries = 1024
+)
+
+type cacheEntry struct {
+	envelopes []Envelope
+	// freshUntil is when the entry must be fetched again from the inner
+	// resolver.
+	freshUntil time.Time
+	// staleUntil is when the entry is dropped, after which it must never be
+	// served, not even on errors. It is the earliest [Envelope.ExpiresAt] of
+	// the entry, or freshUntil plus [maxStale].
+	staleUntil time.Time
+}
+
+type cachingResolver struct {
+	inner Resolver
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*cacheEntry
+	nextSweep time.Time
+}
+
+// CachingResolver wraps inner so that the result of each pattern is reused
+// for ttl instead of calling inner on every request. Concurrent requests for
+// a pattern that is not cached share a single call to inner, see
+// [secrets.Coalesce].
+//
+// Entries are never cached past the [Envelope.ExpiresAt] of any of their
+// envelopes. When refreshing an entry fails, the previous result is served
+// as long as none of its envelopes expired and for at most an hour past ttl,
+// so that a provider backed by a remote service keeps working through
+// transient failures. [ErrNotFound] is not considered a failure, it drops
+// the entry and is returned as is. Errors and empty results are never
+// cached.
+//
+// Dropped entries have their values cleared. At most 1024 patterns are
+// cached, the entries closest to being dropped are evicted first.
+//
+// The returned resolver is safe for concurrent use.
+func CachingResolver(inner Resolver, ttl time.Duration) Resolver {
+	return &cachingResolver{
+		inner:   secrets.Coalesce(inner),
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+func (c *cachingResolver) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	key := pattern.String()
+	now := c.now()
+
+	c.mu.Lock()
+	c.sweep(now)
+	if entry, ok := c.entries[key]; ok && now.Before(entry.freshUntil) {
+		defer c.mu.Unlock()
+		return cloneEnvelopes(entry.envelopes), nil
+	}
+	c.mu.Unlock()
+
+	envelopes, err := c.inner.GetSecrets(ctx, pattern)
+	if err != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if errors.Is(err, ErrNotFound) {
+			c.drop(key)
+			return nil, err
+		}
+		if entry, ok := c.entries[key]; ok && now.Before(entry.staleUntil) {
+			return cloneEnvelopes(entry.envelopes), nil
+		}
+		return nil, err
+	}
+	if len(envelopes) == 0 {
+		return envelopes, nil
+	}
+
+	entry := &cacheEntry{
+		envelopes:  cloneEnvelopes(envelopes),
+		freshUntil: now.Add(c.ttl),
+	}
+	entry.staleUntil = entry.freshUntil.Add(maxStale)
+	for _, envelope := range envelopes {
+		if !envelope.ExpiresAt.IsZero() && envelope.ExpiresAt.Before(entry.staleUntil) {
+			entry.staleUntil = envelope.ExpiresAt
+		}
+	}
+	if entry.staleUntil.Before(entry.freshUntil) {
+		entry.freshUntil = entry.staleUntil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drop(key)
+	if len(c.entries) >= maxCacheEntries {
+		c.evict()
+	}
+	c.entries[key] = entry
+	return envelopes, nil
+}
+
+// sweep drops the entries that can no longer be served, at most once per
+// ttl. It must be called with c.mu held.
+func (c *cachingResolver) sweep(now time.Time) {
+	if now.Before(c.nextSweep) {
+		return
+	}
+	c.nextSweep = now.Add(c.ttl)
+	for key, entry := range c.entries {
+		if !now.Before(entry.staleUntil) {
+			c.drop(key)
+		}
+	}
+}
+
+// evict drops the entry closest to being dropped. It must be called with c.mu
+// held.
+func (c *cachingResolver) evict() {
+	var oldest string
+	var oldestEntry *cacheEntry
+	for key, entry := range c.entries {
+		if oldestEntry == nil || entry.staleUntil.Before(oldestEntry.staleUntil) {
+			oldest, oldestEntry = key, entry
+		}
+	}
+	if oldestEntry != nil {
+		c.drop(oldest)
+	}
+}
+
+// drop removes the entry of key and clears its values. It must be called with
+// c.mu held.
+func (c *cachingResolver) drop(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	for _, envelope := range entry.envelopes {
+		clear(envelope.Value)
+	}
+	delete(c.entries, key)
+}
+
+// cloneEnvelopes deep copies the envelopes so that callers clearing the
+// returned values can't corrupt the cache and vice-versa.
+func cloneEnvelopes(envelopes []Envelope) []Envelope {
+	result := make([]Envelope, len(envelopes))
+	for i, envelope := range envelopes {
+		result[i] = envelope.Clone()
+	}
+	return result
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/x/secrets"
+)
+
+type countingResolver struct {
+	calls     atomic.Int32
+	mu        sync.Mutex
+	envelopes []Envelope
+	err       error
+	// release blocks the calls until closed, when set
+	release chan struct{}
+}
+
+func (c *countingResolver) set(envelopes []Envelope, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.envelopes, c.err = envelopes, err
+}
+
+func (c *countingResolver) GetSecrets(context.Context, Pattern) ([]Envelope, error) {
+	c.calls.Add(1)
+	if c.release != nil {
+		<-c.release
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	return cloneEnvelopes(c.envelopes), nil
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func newTestCachingResolver(inner Resolver, ttl time.Duration) (Resolver, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	r := CachingResolver(inner, ttl)
+	r.(*cachingResolver).now = clock.Now
+	return r, clock
+}
+
+func TestCachingResolver(t *testing.T) {
+	pattern := secrets.MustParsePattern("foo/*")
+	envelope := Envelope{ID: secrets.MustParseID("foo/bar"), Value: []byte("bar")}
+
+	t.Run("serves from the cache until the ttl passed", func(t *testing.T) {
+		inner := &countingResolver{envelopes: []Envelope{envelope}}
+		r, clock := newTestCachingResolver(inner, time.Minute)
+
+		for range 3 {
+			result, err := r.GetSecrets(t.Context(), pattern)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("bar"), result[0].Value)
+		}
+		assert.EqualValues(t, 1, inner.calls.Load())
+
+		clock.Advance(time.Minute)
+		_, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, inner.calls.Load())
+	})
+	t.Run("caches per pattern", func(t *testing.T) {
+		inner := &countingResolver{envelopes: []Envelope{envelope}}
+		r, _ := newTestCachingResolver(inner, time.Minute)
+		_, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		_, err = r.GetSecrets(t.Context(), secrets.MustParsePattern("foo/bar"))
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, inner.calls.Load())
+	})
+	t.Run("clearing a returned value does not affect the cache", func(t *testing.T) {
+		inner := &countingResolver{envelopes: []Envelope{envelope}}
+		r, _ := newTestCachingResolver(inner, time.Minute)
+		result, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		clear(result[0].Value)
+		result, err = r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("bar"), result[0].Value)
+	})
+	t.Run("does not cache past the envelope expiry", func(t *testing.T) {
+		inner := &countingResolver{}
+		r, clock := newTestCachingResolver(inner, time.Minute)
+		expiring := envelope
+		expiring.ExpiresAt = clock.Now().Add(time.Second)
+		inner.set([]Envelope{expiring}, nil)
+
+		_, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		clock.Advance(time.Second)
+		_, err = r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, inner.calls.Load())
+	})
+	t.Run("does not serve an envelope created with a ttl after it expired", func(t *testing.T) {
+		inner := &countingResolver{}
+		r, clock := newTestCachingResolver(inner, time.Minute)
+		inner.set([]Envelope{secrets.NewEnvelopeWithTTL(envelope.ID, []byte("first"), time.Second)}, nil)
+
+		result, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("first"), result[0].Value)
+
+		inner.set([]Envelope{secrets.NewEnvelopeWithTTL(envelope.ID, []byte("second"), time.Second)}, nil)
+		clock.Advance(2 * time.Second)
+		result, err = r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("second"), result[0].Value, "the expired envelope must be fetched again")
+		assert.EqualValues(t, 2, inner.calls.Load())
+	})
+	t.Run("serves stale entries on errors", func(t *testing.T) {
+		inner := &countingResolver{envelopes: []Envelope{envelope}}
+		r, clock := newTestCachingResolver(inner, time.Minute)
+		_, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+
+		inner.set(nil, errors.New("service unavailable"))
+		clock.Advance(30 * time.Minute)
+		result, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("bar"), result[0].Value)
+		assert.EqualValues(t, 2, inner.calls.Load())
+	})
+	t.Run("drops entries past the stale window", func(t *testing.T) {
+		inner := &countingResolver{envelopes: []Envelope{envelope}}
+		r, clock := newTestCachingResolver(inner, time.Minute)
+		_, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+		cached := r.(*cachingResolver).entries[pattern.String()].envelopes[0]
+
+		errUnavailable := errors.New("service unavailable")
+		inner.set(nil, errUnavailable)
+		clock.Advance(time.Minute + maxStale)
+		_, err = r.GetSecrets(t.Context(), pattern)
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.Empty(t, r.(*cachingResolver).entries)
+		assert.Equal(t, []byte{0, 0, 0}, cached.Value, "dropped values must be cleared")
+	})
+	t.Run("bounds the number of entries", func(t *testing.T) {
+		inner := &countingResolver{envelopes: []Envelope{envelope}}
+		r, clock := newTestCachingResolver(inner, time.Minute)
+		for i := range maxCacheEntries + 1 {
+			clock.Advance(time.Millisecond)
+			_, err := r.GetSecrets(t.Context(), secrets.MustParsePattern(fmt.Sprintf("foo/%d/*", i)))
+			require.NoError(t, err)
+		}
+		entries := r.(*cachingResolver).entries
+		assert.Len(t, entries, maxCacheEntries)
+		assert.NotContains(t, entries, "foo/0/*", "the oldest entry must be evicted")
+	})
+	t.Run("coalesces concurrent refreshes", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			inner := &countingResolver{envelopes: []Envelope{envelope}, release: make(chan struct{})}
+			r, _ := newTestCachingResolver(inner, time.Minute)
+			var wg sync.WaitGroup
+			for range 8 {
+				wg.Go(func() {
+					result, err := r.GetSecrets(t.Context(), pattern)
+					if assert.NoError(t, err) {
+						assert.Equal(t, []byte("bar"), result[0].Value)
+					}
+				})
+			}
+			synctest.Wait()
+			close(inner.release)
+			wg.Wait()
+			assert.EqualValues(t, 1, inner.calls.Load())
+		})
+	})
+	t.Run("does not serve expired entries on errors", func(t *testing.T) {
+		inner := &countingResolver{}
+		r, clock := newTestCachingResolver(inner, time.Minute)
+		expiring := envelope
+		expiring.ExpiresAt = clock.Now().Add(time.Second)
+		inner.set([]Envelope{expiring}, nil)
+		_, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+
+		errUnavailable := errors.New("service unavailable")
+		inner.set(nil, errUnavailable)
+		clock.Advance(time.Second)
+		_, err = r.GetSecrets(t.Context(), pattern)
+		assert.ErrorIs(t, err, errUnavailable)
+	})
+	t.Run("not found drops the entry", func(t *testing.T) {
+		inner := &countingResolver{envelopes: []Envelope{envelope}}
+		r, clock := newTestCachingResolver(inner, time.Minute)
+		_, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+
+		inner.set(nil, ErrNotFound)
+		clock.Advance(time.Minute)
+		_, err = r.GetSecrets(t.Context(), pattern)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		inner.set(nil, errors.New("service unavailable"))
+		_, err = r.GetSecrets(t.Context(), pattern)
+		assert.Error(t, err)
+	})
+	t.Run("errors are not cached", func(t *testing.T) {
+		inner := &countingResolver{err: ErrNotFound}
+		r, _ := newTestCachingResolver(inner, time.Minute)
+		_, err := r.GetSecrets(t.Context(), pattern)
+		assert.ErrorIs(t, err, ErrNotFound)
+		inner.set([]Envelope{envelope}, nil)
+		_, err = r.GetSecrets(t.Context(), pattern)
+		assert.NoError(t, err)
+	})
+	t.Run("concurrent use", func(t *testing.T) {
+		inner := &countingResolver{envelopes: []Envelope{envelope}}
+		r, clock := newTestCachingResolver(inner, time.Millisecond)
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Go(func() {
+				for range 50 {
+					clock.Advance(time.Millisecond / 2)
+					result, err := r.GetSecrets(t.Context(), pattern)
+					if assert.NoError(t, err) {
+						assert.Equal(t, []byte("bar"), result[0].Value)
+					}
+				}
+			})
+		}
+		wg.Wait()
+	})
+}
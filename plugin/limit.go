@@ -0,0 +1,110 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type concurrencyLimitedResolver struct {
+	inner Resolver
+	slots chan struct{}
+}
+
+// LimitConcurrency wraps inner so that at most n calls to GetSecrets run at
+// the same time, e.g. to protect a backend with a limited number of
+// connections.
+//
+// Calls over the limit block until a call returns or their context is done,
+// in which case the context error is returned. A limit below 1 means no
+// limit, inner is then returned as is.
+//
+// The returned resolver is safe for concurrent use.
+func LimitConcurrency(inner Resolver, n int) Resolver {
+	if n < 1 {
+		return inner
+	}
+	return &concurrencyLimitedResolver{
+		inner: inner,
+		slots: make(chan struct{}, n),
+	}
+}
+
+func (c *concurrencyLimitedResolver) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+	defer func() { <-c.slots }()
+	return c.inner.GetSecrets(ctx, pattern)
+}
+
+type rateLimitedResolver struct {
+	inner    Resolver
+	interval time.Duration
+
+	mu sync.Mutex
+	// next is the earliest time the next call may start.
+	next time.Time
+}
+
+// RateLimit wraps inner so that calls to GetSecrets start at most rps times
+// per second, e.g. to stay within the quota of a cloud API.
+//
+// Calls are spread evenly: a call starting less than 1/rps after the previous
+// one blocks until then, or until its context is done, in which case the
+// context error is returned and its turn is given back. A rate that is not
+// positive means no limit, inner is then returned as is.
+//
+// The returned resolver is safe for concurrent use.
+func RateLimit(inner Resolver, rps float64) Resolver {
+	if rps <= 0 {
+		return inner
+	}
+	return &rateLimitedResolver{
+		inner:    inner,
+		interval: time.Duration(float64(time.Second) / rps),
+	}
+}
+
+func (r *rateLimitedResolver) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	r.mu.Lock()
+	start := time.Now()
+	if r.next.After(start) {
+		start = r.next
+	}
+	r.next = start.Add(r.interval)
+	r.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			r.mu.Lock()
+			// give the turn back unless later calls queued up behind it
+			if r.next.Equal(start.Add(r.interval)) {
+				r.next = start
+			}
+			r.mu.Unlock()
+			return nil, context.Cause(ctx)
+		}
+	}
+	return r.inner.GetSecrets(ctx, pattern)
+}
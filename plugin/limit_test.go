@@ -0,0 +1,132 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/x/secrets"
+)
+
+// blockingResolver records how many calls run at the same time and blocks
+// them until release is closed.
+type blockingResolver struct {
+	release  chan struct{}
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+	started  chan struct{}
+}
+
+func (b *blockingResolver) GetSecrets(context.Context, Pattern) ([]Envelope, error) {
+	n := b.inFlight.Add(1)
+	defer b.inFlight.Add(-1)
+	for {
+		seen := b.maxSeen.Load()
+		if n <= seen || b.maxSeen.CompareAndSwap(seen, n) {
+			break
+		}
+	}
+	b.started <- struct{}{}
+	<-b.release
+	return nil, ErrNotFound
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	pattern := secrets.MustParsePattern("**")
+
+	t.Run("caps concurrent calls", func(t *testing.T) {
+		inner := &blockingResolver{release: make(chan struct{}), started: make(chan struct{}, 10)}
+		r := LimitConcurrency(inner, 3)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				_, err := r.GetSecrets(t.Context(), pattern)
+				assert.ErrorIs(t, err, ErrNotFound)
+			})
+		}
+		for range 3 {
+			<-inner.started
+		}
+		// give the other calls a chance to get through if the limit was broken
+		time.Sleep(50 * time.Millisecond)
+		assert.EqualValues(t, 3, inner.inFlight.Load())
+
+		close(inner.release)
+		wg.Wait()
+		assert.EqualValues(t, 3, inner.maxSeen.Load())
+	})
+	t.Run("blocked calls honor their context", func(t *testing.T) {
+		inner := &blockingResolver{release: make(chan struct{}), started: make(chan struct{}, 1)}
+		r := LimitConcurrency(inner, 1)
+		go func() { _, _ = r.GetSecrets(t.Context(), pattern) }()
+		<-inner.started
+
+		ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+		defer cancel()
+		_, err := r.GetSecrets(ctx, pattern)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		close(inner.release)
+	})
+	t.Run("no limit", func(t *testing.T) {
+		inner := &countingResolver{}
+		assert.Same(t, Resolver(inner), LimitConcurrency(inner, 0))
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	pattern := secrets.MustParsePattern("**")
+
+	t.Run("spreads calls under load", func(t *testing.T) {
+		inner := &countingResolver{}
+		r := RateLimit(inner, 100)
+
+		start := time.Now()
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				_, err := r.GetSecrets(t.Context(), pattern)
+				assert.NoError(t, err)
+			})
+		}
+		wg.Wait()
+		// the first call starts right away, the other 9 are 10ms apart
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+		assert.EqualValues(t, 10, inner.calls.Load())
+	})
+	t.Run("blocked calls honor their context", func(t *testing.T) {
+		inner := &countingResolver{}
+		r := RateLimit(inner, 1)
+		_, err := r.GetSecrets(t.Context(), pattern)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+		defer cancel()
+		_, err = r.GetSecrets(ctx, pattern)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.EqualValues(t, 1, inner.calls.Load())
+	})
+	t.Run("no limit", func(t *testing.T) {
+		inner := &countingResolver{}
+		assert.Same(t, Resolver(inner), RateLimit(inner, 0))
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Serve creates a secrets provider plugin and runs it until the secrets
+// runtime shuts it down or the process receives SIGINT or SIGTERM.
+//
+// It takes care of both launch modes: when launched by the secrets runtime,
+// the runtime provided configuration is used and opts are ignored, otherwise
+// the plugin connects to the runtime using opts.
+//
+// A minimal plugin main looks like:
+//
+//	func main() {
+//		err := plugin.Serve(&myProvider{}, plugin.Config{
+//			Version:               plugin.MustNewVersion("v1.0.0"),
+//			SecretsProviderConfig: &plugin.SecretsProviderConfig{Pattern: plugin.MustParsePattern("myprovider/**")},
+//		})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// Use [NewSecretsProvider] directly for more control over the plugin lifecycle.
+func Serve(p SecretsProvider, config Config, opts ...ManualLaunchOption) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stub, err := NewSecretsProvider(p, config, opts...)
+	if err != nil {
+		return err
+	}
+	return stub.Run(ctx)
+}
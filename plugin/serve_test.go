@@ -0,0 +1,81 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/x/api"
+	pluginsv1 "github.com/docker/secrets-engine/x/api/plugins/v1"
+	"github.com/docker/secrets-engine/x/api/plugins/v1/pluginsv1connect"
+	"github.com/docker/secrets-engine/x/ipc"
+	"github.com/docker/secrets-engine/x/secrets"
+	"github.com/docker/secrets-engine/x/testhelper"
+)
+
+func TestServe(t *testing.T) {
+	t.Run("invalid config", func(t *testing.T) {
+		err := Serve(&mockPlugin{}, Config{Version: api.MustNewVersion("v1")})
+		assert.ErrorContains(t, err, "secrets provider config is required")
+	})
+	t.Run("returns once the runtime shuts the plugin down", func(t *testing.T) {
+		socket := testhelper.RandomShortSocketName()
+		l, err := net.Listen("unix", socket)
+		require.NoError(t, err)
+		t.Cleanup(func() { l.Close() })
+
+		mRegister := &mockRegistrationHandler{}
+		httpMux := http.NewServeMux()
+		httpMux.Handle(ipc.NewHijackAcceptor(testhelper.TestLogger(t), func(ctx context.Context, conn io.ReadWriteCloser) {
+			runtimeMux := http.NewServeMux()
+			runtimeMux.Handle(pluginsv1connect.NewRegisterServiceHandler(mRegister))
+			closed := make(chan struct{})
+			_, client, err := ipc.NewServerIPC(testhelper.TestLogger(t), conn, runtimeMux, func(error) {
+				close(closed)
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			// a shutdown received during registration is only acted upon
+			// once the plugin registered, so it can be sent right away.
+			_, err = pluginsv1connect.NewPluginServiceClient(client, "http://unix").Shutdown(ctx, connect.NewRequest(pluginsv1.ShutdownRequest_builder{}.Build()))
+			assert.NoError(t, err)
+			<-closed
+		}))
+		server := &http.Server{Handler: httpMux}
+		go func() { _ = server.Serve(l) }()
+		t.Cleanup(func() { server.Close() })
+
+		conn, err := net.Dial("unix", socket)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		err = Serve(&mockPlugin{}, Config{
+			Version:               api.MustNewVersion("v1"),
+			Logger:                testhelper.TestLogger(t),
+			SecretsProviderConfig: &SecretsProviderConfig{Pattern: secrets.MustParsePattern("*")},
+		}, WithPluginName("test-plugin"), WithConnection(conn))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, mRegister.registerRequests)
+	})
+}
@@ -33,10 +33,7 @@ import (
 
 func setup(ctx context.Context, config cfg, onClose func(err error)) (io.Closer, error) {
 	httpMux := http.NewServeMux()
-	httpMux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	})
+	httpMux.HandleFunc("/health", healthHandler(config.Logger, config.HealthCheck))
 	closed := make(chan struct{})
 	once := sync.OnceFunc(func() { close(closed) })
 	httpMux.Handle(pluginsv1connect.NewPluginServiceHandler(&pluginService{func(context.Context) {
@@ -46,7 +43,11 @@ func setup(ctx context.Context, config cfg, onClose func(err error)) (io.Closer,
 	gated := connect.WithInterceptors(setupInterceptor(setupCompleted, config.registrationTimeout))
 	switch {
 	case config.secretsProviderPlugin != nil:
-		httpMux.Handle(resolverv1connect.NewResolverServiceHandler(resolverv1.NewResolverHandler(config.secretsProviderPlugin), gated))
+		var opts []resolverv1.Option
+		if config.SecretsProviderConfig != nil && config.Coalesce {
+			opts = append(opts, resolverv1.WithCoalescing(config.CoalesceScope))
+		}
+		httpMux.Handle(resolverv1connect.NewResolverServiceHandler(resolverv1.NewResolverHandler(config.secretsProviderPlugin, opts...), gated))
 	case config.accessControlModule != nil:
 		httpMux.Handle(accesscontrolv1connect.NewAccessControlServiceHandler(accesscontrol.NewAccessControlHandler(config.accessControlModule), gated))
 	default:
@@ -76,3 +77,18 @@ func setup(ctx context.Context, config cfg, onClose func(err error)) (io.Closer,
 	close(setupCompleted)
 	return ipc, nil
 }
+
+// healthHandler reports the plugin as healthy unless check returns an error.
+func healthHandler(logger Logger, check func(ctx context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			if err := check(r.Context()); err != nil {
+				logger.Warnf("Health check failed: %s", err)
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}
+}
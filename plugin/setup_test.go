@@ -16,9 +16,11 @@ package plugin
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,6 +33,7 @@ import (
 	"github.com/docker/secrets-engine/x/api"
 	pluginsv1 "github.com/docker/secrets-engine/x/api/plugins/v1"
 	"github.com/docker/secrets-engine/x/api/plugins/v1/pluginsv1connect"
+	resolverv1 "github.com/docker/secrets-engine/x/api/resolver"
 	"github.com/docker/secrets-engine/x/ipc"
 	"github.com/docker/secrets-engine/x/secrets"
 	"github.com/docker/secrets-engine/x/testhelper"
@@ -84,4 +87,103 @@ func Test_setup(t *testing.T) {
 		assert.NoError(t, closer.Close())
 		assert.NoError(t, testhelper.WaitForClosedWithTimeout(runtimeClosed))
 	})
+	t.Run("health reports the result of the health check", func(t *testing.T) {
+		a, b := net.Pipe()
+		httpMux := http.NewServeMux()
+		httpMux.Handle(pluginsv1connect.NewRegisterServiceHandler(&mockRegistrationHandler{}))
+		_, client, err := ipc.NewServerIPC(testhelper.TestLogger(t), a, httpMux, func(error) {})
+		require.NoError(t, err)
+		var healthErr error
+		closer, err := setup(t.Context(), cfg{
+			Config: Config{
+				Version: api.MustNewVersion("v1"),
+				Logger:  testhelper.TestLogger(t),
+				HealthCheck: func(context.Context) error {
+					return healthErr
+				},
+				SecretsProviderConfig: &SecretsProviderConfig{Pattern: secrets.MustParsePattern("*")},
+			},
+			secretsProviderPlugin: &mockPlugin{},
+			name:                  "foo",
+			conn:                  b,
+			registrationTimeout:   5 * time.Second,
+		}, func(error) {})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = closer.Close() })
+
+		health := func() (int, string) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://unix/health", nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			return resp.StatusCode, string(body)
+		}
+		code, body := health()
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", body)
+
+		healthErr = errors.New("vault is sealed")
+		code, body = health()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, "vault is sealed")
+	})
+	t.Run("coalesces identical requests when enabled", func(t *testing.T) {
+		a, b := net.Pipe()
+		httpMux := http.NewServeMux()
+		httpMux.Handle(pluginsv1connect.NewRegisterServiceHandler(&mockRegistrationHandler{}))
+		_, client, err := ipc.NewServerIPC(testhelper.TestLogger(t), a, httpMux, func(error) {})
+		require.NoError(t, err)
+		p := &blockingPlugin{entered: make(chan struct{}, 2), release: make(chan struct{})}
+		closer, err := setup(t.Context(), cfg{
+			Config: Config{
+				Version: api.MustNewVersion("v1"),
+				Logger:  testhelper.TestLogger(t),
+				SecretsProviderConfig: &SecretsProviderConfig{
+					Pattern:  secrets.MustParsePattern("**"),
+					Coalesce: true,
+				},
+			},
+			secretsProviderPlugin: p,
+			name:                  "foo",
+			conn:                  b,
+			registrationTimeout:   5 * time.Second,
+		}, func(error) {})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = closer.Close() })
+
+		resolver := resolverv1.NewResolverClient(client)
+		var wg sync.WaitGroup
+		for range 2 {
+			wg.Go(func() {
+				envelopes, err := resolver.GetSecrets(t.Context(), secrets.MustParsePattern("db/password"))
+				if assert.NoError(t, err) && assert.Len(t, envelopes, 1) {
+					assert.Equal(t, "value", string(envelopes[0].Value))
+				}
+			})
+		}
+		<-p.entered
+		select {
+		case <-p.entered:
+			t.Fatal("the requests were not coalesced")
+		case <-time.After(200 * time.Millisecond):
+		}
+		close(p.release)
+		wg.Wait()
+	})
+}
+
+// blockingPlugin signals each call on entered, then blocks it until release
+// is closed.
+type blockingPlugin struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingPlugin) GetSecrets(_ context.Context, pattern secrets.Pattern) ([]secrets.Envelope, error) {
+	p.entered <- struct{}{}
+	<-p.release
+	return []secrets.Envelope{{ID: secrets.MustParseID(pattern.String()), Value: []byte("value")}}, nil
 }
@@ -71,7 +71,9 @@ func newStub(config Config, setPlugin func(*cfg), opts ...ManualLaunchOption) (S
 		return nil, err
 	}
 	if config.Logger == nil {
-		config.Logger = logging.NewDefaultLogger("plugin")
+		// an invalid LOG_LEVEL falls back to the default level
+		level, _ := logging.LevelFromEnv()
+		config.Logger = logging.NewLevelLogger("plugin", level)
 	}
 	cfg, err := newCfg(opts...)
 	if err != nil {
@@ -17,6 +17,7 @@ package plugin
 import (
 	"context"
 	"errors"
+	"net/http"
 	"time"
 
 	"github.com/docker/secrets-engine/x/api"
@@ -31,6 +32,9 @@ type (
 	Resolver = secrets.Resolver
 	// Envelope is a type alias for secrets.Envelope, representing a secret envelope.
 	Envelope = secrets.Envelope
+	// Lister is a type alias for secrets.Lister, optionally implemented by
+	// plugins able to list the IDs of their secrets without fetching values.
+	Lister = secrets.Lister
 
 	// Version is a type alias for api.Version, representing the plugin version.
 	Version = api.Version
@@ -77,8 +81,14 @@ type Stub interface {
 type Config struct {
 	// Version of the plugin in semver format.
 	Version Version
-	// Logger to be used within plugin side SDK code. If nil, a default logger will be created and used.
+	// Logger to be used within plugin side SDK code. If nil, a default logger will be created and used,
+	// its level can be set through the LOG_LEVEL environment variable (see [logging.LevelFromEnv]).
 	Logger Logger
+	// HealthCheck reports whether the plugin can serve requests, e.g. whether its
+	// backend is reachable. It is called on every health probe of the runtime and
+	// a non-nil error reports the plugin as unhealthy. If nil, the plugin always
+	// reports healthy.
+	HealthCheck func(ctx context.Context) error
 
 	*SecretsProviderConfig
 	*AccessControlConfig
@@ -100,6 +110,14 @@ func (c *Config) Valid() error {
 type SecretsProviderConfig struct {
 	// Pattern to control which IDs should match this plugin. Set to `**` to match any ID.
 	Pattern Pattern
+	// Coalesce shares a single call to the plugin between concurrent GetSecrets
+	// requests for the same pattern, e.g. when many containers start at once and
+	// ask for the same secret. See [secrets.Coalesce].
+	Coalesce bool
+	// CoalesceScope returns the scope of the caller of a request from its
+	// headers when Coalesce is set: requests of different scopes never share a
+	// call. If nil, all requests are in the same scope.
+	CoalesceScope func(header http.Header) string
 }
 
 type AccessControlConfig struct{}
@@ -17,6 +17,8 @@ package commands
 import (
 	"bytes"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -45,7 +47,7 @@ func Test_SetCommand(t *testing.T) {
 	t.Parallel()
 	t.Run("ok", func(t *testing.T) {
 		mock := teststore.NewMockStore()
-		out, err := execute(t, SetCommand(), mock, "foo=bar=bar=bar")
+		out, err := execute(t, SetCommand(), mock, "foo=bar=bar=bar", "--literal")
 		assert.NoError(t, err)
 		assert.Empty(t, out)
 		assertStoredValue(t, mock, "bar=bar=bar")
@@ -59,7 +61,7 @@ func Test_SetCommand(t *testing.T) {
 	})
 	t.Run("with --metadata flag", func(t *testing.T) {
 		mock := teststore.NewMockStore()
-		out, err := execute(t, SetCommand(), mock, "foo=bar", "--metadata", "name=bob", "--metadata", "expiry=2027-03-01")
+		out, err := execute(t, SetCommand(), mock, "foo=bar", "--literal", "--metadata", "name=bob", "--metadata", "expiry=2027-03-01")
 		assert.NoError(t, err)
 		assert.Empty(t, out)
 		assertStoredValue(t, mock, "bar")
@@ -83,20 +85,20 @@ func Test_SetCommand(t *testing.T) {
 	})
 	t.Run("invalid --metadata flag (no =)", func(t *testing.T) {
 		mock := teststore.NewMockStore()
-		_, err := execute(t, SetCommand(), mock, "foo=bar", "--metadata", "invalid")
+		_, err := execute(t, SetCommand(), mock, "foo=bar", "--literal", "--metadata", "invalid")
 		assert.ErrorContains(t, err, "invalid metadata pair (expected key=value): invalid")
 	})
 	t.Run("store error", func(t *testing.T) {
 		errSave := errors.New("save error")
 		mock := teststore.NewMockStore(teststore.WithStoreSaveErr(errSave))
-		out, err := execute(t, SetCommand(), mock, "foo=bar")
+		out, err := execute(t, SetCommand(), mock, "foo=bar", "--literal")
 		assert.ErrorIs(t, err, errSave)
 		assert.Equal(t, "Error: "+errSave.Error()+"\n", out)
 	})
 	t.Run("invalid id", func(t *testing.T) {
 		errSave := errors.New("save error")
 		mock := teststore.NewMockStore(teststore.WithStoreSaveErr(errSave))
-		out, err := execute(t, SetCommand(), mock, "/foo=bar")
+		out, err := execute(t, SetCommand(), mock, "/foo=bar", "--literal")
 		errInvalidID := secrets.ErrInvalidID{ID: "/foo"}
 		assert.ErrorIs(t, err, errInvalidID)
 		assert.Equal(t, "Error: "+errInvalidID.Error()+"\n", out)
@@ -110,7 +112,7 @@ func Test_SetCommand(t *testing.T) {
 			}),
 			teststore.WithStoreSaveErr(errors.New("save should not be called when --force is set")),
 		)
-		out, err := execute(t, SetCommand(), mock, "foo=new", "--force")
+		out, err := execute(t, SetCommand(), mock, "foo=new", "--literal", "--force")
 		assert.NoError(t, err)
 		assert.Empty(t, out)
 		assertStoredValue(t, mock, "new")
@@ -118,12 +120,67 @@ func Test_SetCommand(t *testing.T) {
 	t.Run("--force surfaces upsert error", func(t *testing.T) {
 		errUpsert := errors.New("upsert error")
 		mock := teststore.NewMockStore(teststore.WithStoreUpsertErr(errUpsert))
-		out, err := execute(t, SetCommand(), mock, "foo=bar", "--force")
+		out, err := execute(t, SetCommand(), mock, "foo=bar", "--literal", "--force")
 		assert.ErrorIs(t, err, errUpsert)
 		assert.Equal(t, "Error: "+errUpsert.Error()+"\n", out)
 	})
 }
 
+// Test_SetCommandReferences is not parallel, since it sets environment
+// variables.
+func Test_SetCommandReferences(t *testing.T) {
+	t.Run("from environment variable", func(t *testing.T) {
+		t.Setenv("PASS_TEST_SECRET", "bar")
+		mock := teststore.NewMockStore()
+		out, err := execute(t, SetCommand(), mock, "foo=@env:PASS_TEST_SECRET")
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+		assertStoredValue(t, mock, "bar")
+	})
+	t.Run("from unset environment variable", func(t *testing.T) {
+		mock := teststore.NewMockStore()
+		_, err := execute(t, SetCommand(), mock, "foo=@env:PASS_TEST_UNSET")
+		assert.ErrorContains(t, err, `environment variable "PASS_TEST_UNSET" is not set`)
+	})
+	t.Run("from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "secret")
+		require.NoError(t, os.WriteFile(path, []byte("my\nmultiline\nvalue"), 0o600))
+		mock := teststore.NewMockStore()
+		out, err := execute(t, SetCommand(), mock, "foo=@file:"+path, "--metadata", "name=bob")
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+		assertStoredValue(t, mock, "my\nmultiline\nvalue")
+		assertStoredMetadata(t, mock, map[string]string{"name": "bob"})
+	})
+	t.Run("from missing file", func(t *testing.T) {
+		mock := teststore.NewMockStore()
+		_, err := execute(t, SetCommand(), mock, "foo=@file:"+filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+	t.Run("from explicit STDIN", func(t *testing.T) {
+		mock := teststore.NewMockStore()
+		out, err := executeWithStdin(t, SetCommand(), mock, `{"secret":"bar","metadata":{"name":"bob"}}`, "foo=@-")
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+		assertStoredValue(t, mock, "bar")
+		assertStoredMetadata(t, mock, map[string]string{"name": "bob"})
+	})
+	t.Run("literal is rejected without --literal", func(t *testing.T) {
+		mock := teststore.NewMockStore()
+		_, err := execute(t, SetCommand(), mock, "foo=bar")
+		assert.ErrorContains(t, err, "refusing to read the value of foo from the command line")
+		_, err = mock.Get(t.Context(), secrets.MustParseID("foo"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+	t.Run("--literal takes references as is", func(t *testing.T) {
+		mock := teststore.NewMockStore()
+		out, err := execute(t, SetCommand(), mock, "foo=@env:PASS_TEST_SECRET", "--literal")
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+		assertStoredValue(t, mock, "@env:PASS_TEST_SECRET")
+	})
+}
+
 func Test_ListCommand(t *testing.T) {
 	t.Parallel()
 	t.Run("ok", func(t *testing.T) {
@@ -135,6 +192,23 @@ func Test_ListCommand(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "baz\nfoo\n", out)
 	})
+	t.Run("json", func(t *testing.T) {
+		withMetadata := pass.NewPassValue([]byte("bar"))
+		require.NoError(t, withMetadata.SetMetadata(map[string]string{"owner": "alice"}))
+		mock := teststore.NewMockStore(teststore.WithStore(map[store.ID]store.Secret{
+			store.MustParseID("foo"): withMetadata,
+			store.MustParseID("baz"): pass.NewPassValue([]byte("0")),
+		}))
+		out, err := execute(t, ListCommand(), mock, "--json")
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"id":"baz","metadata":{}},{"id":"foo","metadata":{"owner":"alice"}}]`+"\n", out)
+		assert.NotContains(t, out, "bar")
+	})
+	t.Run("json without secrets", func(t *testing.T) {
+		out, err := execute(t, ListCommand(), teststore.NewMockStore(), "--json")
+		assert.NoError(t, err)
+		assert.Equal(t, "[]\n", out)
+	})
 	t.Run("store error", func(t *testing.T) {
 		errGetAll := errors.New("get error")
 		mock := teststore.NewMockStore(teststore.WithStoreGetAllErr(errGetAll))
@@ -196,6 +270,52 @@ func Test_RmCommand(t *testing.T) {
 		assert.ErrorContains(t, err, "either provide a secret name or use --all to remove all secrets")
 		assert.Equal(t, "Error: either provide a secret name or use --all to remove all secrets\n", out)
 	})
+	newPatternStore := func() store.Store {
+		return teststore.NewMockStore(teststore.WithStore(map[store.ID]store.Secret{
+			store.MustParseID("prod/db"):  pass.NewPassValue([]byte("0")),
+			store.MustParseID("prod/api"): pass.NewPassValue([]byte("1")),
+			store.MustParseID("dev/db"):   pass.NewPassValue([]byte("2")),
+		}))
+	}
+	t.Run("--pattern --yes", func(t *testing.T) {
+		mock := newPatternStore()
+		out, err := execute(t, RmCommand(), mock, "--pattern", "prod/**", "--yes")
+		assert.NoError(t, err)
+		assert.Equal(t, "RM: prod/api\nRM: prod/db\n", out)
+		l, err := mock.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, l, 1)
+		assert.Contains(t, l, store.MustParseID("dev/db"))
+	})
+	t.Run("--pattern asks for confirmation", func(t *testing.T) {
+		mock := newPatternStore()
+		out, err := executeWithStdin(t, RmCommand(), mock, "y\n", "--pattern", "prod/*")
+		assert.NoError(t, err)
+		assert.Equal(t, "The following 2 secret(s) will be removed:\n  prod/api\n  prod/db\nContinue? [y/N] RM: prod/api\nRM: prod/db\n", out)
+	})
+	t.Run("--pattern declined", func(t *testing.T) {
+		mock := newPatternStore()
+		out, err := executeWithStdin(t, RmCommand(), mock, "\n", "--pattern", "prod/*")
+		assert.ErrorContains(t, err, "aborted")
+		assert.NotContains(t, out, "RM:")
+		l, err := mock.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, l, 3)
+	})
+	t.Run("--pattern without match", func(t *testing.T) {
+		mock := newPatternStore()
+		_, err := execute(t, RmCommand(), mock, "--pattern", "staging/**", "--yes")
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+	t.Run("cannot mix --pattern with explicit list or --all", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"--pattern", "prod/**", "foo"},
+			{"--pattern", "prod/**", "--all"},
+		} {
+			_, err := execute(t, RmCommand(), newPatternStore(), args...)
+			assert.ErrorContains(t, err, "either provide a secret name, use --all or use --pattern, not several of them")
+		}
+	})
 }
 
 func Test_GetCommand(t *testing.T) {
@@ -208,6 +328,20 @@ func Test_GetCommand(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "ID: foo\nValue: **********\n", out)
 	})
+	t.Run("partial mask", func(t *testing.T) {
+		mock := teststore.NewMockStore(teststore.WithStore(map[store.ID]store.Secret{
+			store.MustParseID("foo"): pass.NewPassValue([]byte("my-secret-value")),
+		}))
+		out, err := execute(t, GetCommand(), mock, "--mask", "partial", "foo")
+		assert.NoError(t, err)
+		assert.Equal(t, "ID: foo\nValue: m********e (15 bytes)\n", out)
+		assert.NotContains(t, out, "secret")
+	})
+	t.Run("invalid mask", func(t *testing.T) {
+		mock := teststore.NewMockStore()
+		_, err := execute(t, GetCommand(), mock, "--mask", "none", "foo")
+		assert.ErrorContains(t, err, `invalid --mask value "none"`)
+	})
 	t.Run("store error", func(t *testing.T) {
 		errGet := errors.New("get error")
 		mock := teststore.NewMockStore(teststore.WithStoreGetErr(errGet))
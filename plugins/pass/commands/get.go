@@ -16,20 +16,36 @@ package commands
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
 	pass "github.com/docker/secrets-engine/plugins/pass/store"
 	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/x/secrets"
 )
 
+const (
+	maskFull    = "full"
+	maskPartial = "partial"
+)
+
+type getOpts struct {
+	Mask string
+}
+
 func GetCommand() *cobra.Command {
+	opts := getOpts{}
 	cmd := &cobra.Command{
 		Use:   "get NAME",
 		Args:  cobra.ExactArgs(1),
 		Short: "Get a secret from a keystore.",
 		Long:  "Retrieves a named secret from the local OS keychain. The secret value is masked in output.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			masker, err := maskerFor(opts.Mask)
+			if err != nil {
+				return err
+			}
 			id, err := store.ParseID(args[0])
 			if err != nil {
 				return err
@@ -42,13 +58,30 @@ func GetCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			_, ok := s.(*pass.PassValue)
+			p, ok := s.(*pass.PassValue)
 			if !ok {
 				return errors.New("unknown secret type")
 			}
-			cmd.Printf("ID: %s\nValue: %s\n", id.String(), "**********")
+			value, err := p.Marshal()
+			if err != nil {
+				return err
+			}
+			cmd.Printf("ID: %s\nValue: %s\n", id.String(), masker.Mask(value))
 			return nil
 		},
 	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.Mask, "mask", maskFull, `How to mask the secret value, "full" or "partial" to reveal its first and last character and its length`)
 	return cmd
 }
+
+func maskerFor(mask string) (secrets.Masker, error) {
+	switch mask {
+	case maskFull:
+		return secrets.FullMasker{}, nil
+	case maskPartial:
+		return secrets.PartialMasker{Reveal: 1, ShowLength: true}, nil
+	default:
+		return nil, fmt.Errorf("invalid --mask value %q, must be %q or %q", mask, maskFull, maskPartial)
+	}
+}
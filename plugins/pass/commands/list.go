@@ -15,17 +15,31 @@
 package commands
 
 import (
+	"cmp"
+	"encoding/json"
 	"slices"
 
 	"github.com/spf13/cobra"
 )
 
+type listOpts struct {
+	JSON bool
+}
+
+// listEntry is a secret as printed by `list --json`. It never holds the
+// secret value.
+type listEntry struct {
+	ID       string            `json:"id"`
+	Metadata map[string]string `json:"metadata"`
+}
+
 func ListCommand() *cobra.Command {
+	opts := listOpts{}
 	cmd := &cobra.Command{
 		Use:     "ls",
 		Aliases: []string{"list"},
 		Short:   "List all secrets from local keychain.",
-		Long:    "Lists the names of all secrets stored in the local OS keychain.",
+		Long:    "Lists the names of all secrets stored in the local OS keychain. Use `--json` to also print their metadata as JSON.",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			kc, err := StoreFrom(cmd.Context())
@@ -36,16 +50,27 @@ func ListCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			var idList []string
-			for id := range l {
-				idList = append(idList, id.String())
+			entries := []listEntry{}
+			for id, secret := range l {
+				metadata := secret.Metadata()
+				if metadata == nil {
+					metadata = map[string]string{}
+				}
+				entries = append(entries, listEntry{ID: id.String(), Metadata: metadata})
+			}
+			slices.SortFunc(entries, func(a, b listEntry) int {
+				return cmp.Compare(a.ID, b.ID)
+			})
+			if opts.JSON {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(entries)
 			}
-			slices.Sort(idList)
-			for _, id := range idList {
-				cmd.Println(id)
+			for _, entry := range entries {
+				cmd.Println(entry.ID)
 			}
 			return nil
 		},
 	}
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.JSON, "json", false, "Print the secrets and their metadata as a JSON array")
 	return cmd
 }
@@ -0,0 +1,49 @@
+// Copyright 2025-2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/docker/secrets-engine/x/logging"
+)
+
+// AddLogLevelFlag adds a persistent --log-level flag to root and attaches a
+// logger of that level, writing to the error output of the command, to the
+// context of the command being run (see [logging.FromContext]).
+//
+// Without the flag, the level is read from the LOG_LEVEL environment variable
+// when the command runs, so that an invalid value only fails the commands
+// not setting the flag.
+func AddLogLevelFlag(root *cobra.Command) {
+	level := logging.LevelInfo
+	root.PersistentFlags().Var(&level, "log-level", fmt.Sprintf("Log level: debug, info, warn or error (default from %s, else info)", logging.LevelEnv))
+
+	preRun := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		resolved, err := logging.LevelOrEnv(level, cmd.Flags().Changed("log-level"))
+		if err != nil {
+			return err
+		}
+		logger := logging.NewLevelLogger("", resolved, logging.WithOut(cmd.ErrOrStderr()))
+		cmd.SetContext(logging.WithLogger(cmd.Context(), logger))
+		if preRun != nil {
+			return preRun(cmd, args)
+		}
+		return nil
+	}
+}
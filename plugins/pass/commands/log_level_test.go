@@ -0,0 +1,61 @@
+// Copyright 2025-2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/x/logging"
+)
+
+func Test_LogLevelFlag(t *testing.T) {
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "pass"}
+		AddLogLevelFlag(root)
+		root.AddCommand(&cobra.Command{
+			Use: "check",
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				logger, err := logging.FromContext(cmd.Context())
+				if err != nil {
+					return err
+				}
+				logger.Printf("info record")
+				logger.Warnf("warn record")
+				return nil
+			},
+		})
+		return root
+	}
+	t.Run("attaches a logger of the level to the command context", func(t *testing.T) {
+		out, err := execute(t, newRoot(), nil, "check", "--log-level", "warn")
+		require.NoError(t, err)
+		assert.NotContains(t, out, "info record")
+		assert.Contains(t, out, "warn record")
+	})
+	t.Run("the flag overrides an invalid environment variable", func(t *testing.T) {
+		t.Setenv(logging.LevelEnv, "loud")
+		_, err := execute(t, newRoot(), nil, "check", "--log-level", "debug")
+		assert.NoError(t, err)
+	})
+	t.Run("an invalid environment variable fails the command", func(t *testing.T) {
+		t.Setenv(logging.LevelEnv, "loud")
+		_, err := execute(t, newRoot(), nil, "check")
+		assert.ErrorContains(t, err, "invalid log level")
+	})
+}
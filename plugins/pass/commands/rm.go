@@ -15,6 +15,7 @@
 package commands
 
 import (
+	"bufio"
 	"context"
 	_ "embed"
 	"errors"
@@ -32,7 +33,9 @@ import (
 var rmExample string
 
 type rmOpts struct {
-	All bool
+	All     bool
+	Pattern string
+	Yes     bool
 }
 
 func RmCommand() *cobra.Command {
@@ -41,7 +44,7 @@ func RmCommand() *cobra.Command {
 		Use:     "rm name1 name2 ...",
 		Aliases: []string{"delete", "erase", "remove"},
 		Short:   "Remove secrets from local keychain.",
-		Long:    "Removes one or more named secrets from the local OS keychain. Use `--all` to remove every stored secret at once.",
+		Long:    "Removes one or more named secrets from the local OS keychain. Use `--all` to remove every stored secret at once, or `--pattern` to remove the secrets matching a pattern.",
 		Example: strings.Trim(rmExample, "\n"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			idList, err := validateArgs(args, opts)
@@ -52,15 +55,26 @@ func RmCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return runRm(cmd.Context(), cmd.OutOrStdout(), kc, idList, opts)
+			return runRm(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout(), kc, idList, opts)
 		},
 	}
 	flags := cmd.Flags()
 	flags.BoolVar(&opts.All, "all", false, "Remove all secrets")
+	flags.StringVar(&opts.Pattern, "pattern", "", "Remove the secrets matching a pattern, e.g. 'prod/**'")
+	flags.BoolVarP(&opts.Yes, "yes", "y", false, "Do not ask for confirmation before removing the secrets matching --pattern")
 	return cmd
 }
 
 func validateArgs(args []string, opts rmOpts) ([]store.ID, error) {
+	if opts.Pattern != "" {
+		if len(args) > 0 || opts.All {
+			return nil, fmt.Errorf("either provide a secret name, use --all or use --pattern, not several of them")
+		}
+		if _, err := store.ParsePattern(opts.Pattern); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
 	if (len(args) == 0 && !opts.All) || (len(args) > 0 && opts.All) {
 		return nil, fmt.Errorf("either provide a secret name or use --all to remove all secrets")
 	}
@@ -75,7 +89,7 @@ func validateArgs(args []string, opts rmOpts) ([]store.ID, error) {
 	return result, nil
 }
 
-func runRm(ctx context.Context, out io.Writer, kc store.Store, idList []store.ID, opts rmOpts) error {
+func runRm(ctx context.Context, in io.Reader, out io.Writer, kc store.Store, idList []store.ID, opts rmOpts) error {
 	if opts.All && len(idList) == 0 {
 		l, err := kc.GetAllMetadata(ctx)
 		if err != nil {
@@ -85,7 +99,32 @@ func runRm(ctx context.Context, out io.Writer, kc store.Store, idList []store.ID
 			idList = append(idList, k)
 		}
 	}
+	if opts.Pattern != "" {
+		pattern, err := store.ParsePattern(opts.Pattern)
+		if err != nil {
+			return err
+		}
+		l, err := kc.FilterMetadata(ctx, pattern)
+		if err != nil && !errors.Is(err, store.ErrCredentialNotFound) {
+			return err
+		}
+		if len(l) == 0 {
+			return fmt.Errorf("no secrets match %s: %w", pattern, store.ErrCredentialNotFound)
+		}
+		for k := range l {
+			idList = append(idList, k)
+		}
+	}
 	slices.SortFunc(idList, func(a, b store.ID) int { return strings.Compare(a.String(), b.String()) })
+	if opts.Pattern != "" && !opts.Yes {
+		ok, err := confirmRm(in, out, idList)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("aborted, no secrets were removed")
+		}
+	}
 	var errs []error
 	for _, id := range idList {
 		if err := kc.Delete(ctx, id); err != nil {
@@ -97,3 +136,24 @@ func runRm(ctx context.Context, out io.Writer, kc store.Store, idList []store.ID
 	}
 	return errors.Join(errs...)
 }
+
+// confirmRm lists the secrets about to be removed and asks the user to
+// confirm. Anything other than "y" or "yes" declines.
+func confirmRm(in io.Reader, out io.Writer, idList []store.ID) (bool, error) {
+	fmt.Fprintf(out, "The following %d secret(s) will be removed:\n", len(idList))
+	for _, id := range idList {
+		fmt.Fprintf(out, "  %s\n", id)
+	}
+	fmt.Fprint(out, "Continue? [y/N] ")
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -38,8 +39,16 @@ var setLong string
 type setOpts struct {
 	metadata []string // raw "key=value" strings from --metadata flag
 	force    bool     // if true, overwrite existing secret instead of erroring
+	literal  bool     // if true, take the value after "=" as is instead of as a reference
 }
 
+// References to the source of a secret value, given as id=<reference>.
+const (
+	envReferencePrefix  = "@env:"
+	fileReferencePrefix = "@file:"
+	stdinReference      = "@-"
+)
+
 type stdinPayload struct {
 	Secret   string            `json:"secret"`
 	Metadata map[string]string `json:"metadata,omitempty"`
@@ -48,7 +57,7 @@ type stdinPayload struct {
 func SetCommand() *cobra.Command {
 	opts := setOpts{}
 	cmd := &cobra.Command{
-		Use:     "set id[=value]",
+		Use:     "set id[=@env:NAME|@file:PATH|@-]",
 		Aliases: []string{"store", "save"},
 		Short:   "Set a secret",
 		Long:    strings.Trim(setLong, "\n"),
@@ -65,6 +74,12 @@ func SetCommand() *cobra.Command {
 				if err != nil {
 					return err
 				}
+				if !opts.literal {
+					va, err = resolveReference(cmd.Context(), cmd.InOrStdin(), va)
+					if err != nil {
+						return err
+					}
+				}
 				s = *va
 			} else {
 				val, err := secretMappingFromSTDIN(cmd.Context(), cmd.InOrStdin(), args[0])
@@ -110,6 +125,7 @@ func SetCommand() *cobra.Command {
 	flags := cmd.Flags()
 	flags.StringArrayVar(&opts.metadata, "metadata", nil, "Non-sensitive key=value metadata (repeatable)")
 	flags.BoolVarP(&opts.force, "force", "f", false, "Overwrite existing secret if it already exists")
+	flags.BoolVar(&opts.literal, "literal", false, "Take the value after \"=\" as is, e.g. id=value (kept in the shell history)")
 	return cmd
 }
 
@@ -157,6 +173,32 @@ func parseArg(arg string) (*secret, error) {
 	return &secret{id: key, val: value}, nil
 }
 
+// resolveReference reads the value of s from the source its value refers to:
+// an environment variable (@env:NAME), a file (@file:PATH) or STDIN (@-).
+// Any other value is a literal, which is rejected since it would be kept in
+// the shell history.
+func resolveReference(ctx context.Context, stdin io.Reader, s *secret) (*secret, error) {
+	switch {
+	case s.val == stdinReference:
+		return secretMappingFromSTDIN(ctx, stdin, s.id)
+	case strings.HasPrefix(s.val, envReferencePrefix):
+		name := strings.TrimPrefix(s.val, envReferencePrefix)
+		val, ok := os.LookupEnv(name)
+		if name == "" || !ok {
+			return nil, fmt.Errorf("environment variable %q is not set", name)
+		}
+		return &secret{id: s.id, val: val}, nil
+	case strings.HasPrefix(s.val, fileReferencePrefix):
+		data, err := os.ReadFile(strings.TrimPrefix(s.val, fileReferencePrefix))
+		if err != nil {
+			return nil, err
+		}
+		defer clear(data)
+		return &secret{id: s.id, val: string(data)}, nil
+	}
+	return nil, fmt.Errorf("refusing to read the value of %s from the command line, where it is kept in the shell history: use %sNAME, %sPATH or %s, or pass --literal", s.id, envReferencePrefix, fileReferencePrefix, stdinReference)
+}
+
 func readAllWithContext(ctx context.Context, r io.Reader) ([]byte, error) {
 	var buf []byte
 	done := make(chan error, 1)
@@ -0,0 +1,71 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// AddTimeoutFlag adds a persistent --timeout flag to root that bounds the
+// context of the command being run, and with it every store operation.
+//
+// Keychain prompts, e.g. to unlock the keychain, count against the timeout.
+// A timeout of 0, the default, does not bound the command.
+func AddTimeoutFlag(root *cobra.Command) {
+	var timeout time.Duration
+	root.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Maximum duration of the command, including keychain prompts (0 means no timeout)")
+
+	preRun := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if timeout < 0 {
+			return errors.New("--timeout cannot be negative")
+		}
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+			cancelAfterRun(cmd, cancel)
+		}
+		if preRun != nil {
+			return preRun(cmd, args)
+		}
+		return nil
+	}
+}
+
+// cancelAfterRun wraps the run function of cmd so that cancel is called once
+// it returned, even on errors: cobra skips the post-run hooks of a failed
+// command. The run function of cmd is restored on its first call.
+func cancelAfterRun(cmd *cobra.Command, cancel context.CancelFunc) {
+	switch run, runE := cmd.Run, cmd.RunE; {
+	case runE != nil:
+		cmd.RunE = func(c *cobra.Command, args []string) error {
+			defer cancel()
+			cmd.RunE = runE
+			return runE(c, args)
+		}
+	case run != nil:
+		cmd.Run = func(c *cobra.Command, args []string) {
+			defer cancel()
+			cmd.Run = run
+			run(c, args)
+		}
+	default:
+		cancel()
+	}
+}
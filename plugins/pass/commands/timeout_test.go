@@ -0,0 +1,94 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/docker/secrets-engine/plugins/pass/teststore"
+	"github.com/docker/secrets-engine/store"
+)
+
+// promptingStore blocks on Get as if the keychain was waiting on the user.
+type promptingStore struct {
+	store.Store
+}
+
+func (p *promptingStore) Get(ctx context.Context, _ store.ID) (store.Secret, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newTimeoutRoot() *cobra.Command {
+	root := &cobra.Command{Use: "pass"}
+	AddTimeoutFlag(root)
+	root.AddCommand(GetCommand())
+	return root
+}
+
+func Test_TimeoutFlag(t *testing.T) {
+	t.Parallel()
+	t.Run("bounds store operations", func(t *testing.T) {
+		mock := &promptingStore{Store: teststore.NewMockStore()}
+		start := time.Now()
+		_, err := execute(t, newTimeoutRoot(), mock, "get", "--timeout", "50ms", "foo")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+	t.Run("no timeout by default", func(t *testing.T) {
+		var deadlineSet bool
+		root := &cobra.Command{Use: "pass"}
+		AddTimeoutFlag(root)
+		root.AddCommand(&cobra.Command{
+			Use: "check",
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				_, deadlineSet = cmd.Context().Deadline()
+				return nil
+			},
+		})
+		_, err := execute(t, root, nil, "check")
+		assert.NoError(t, err)
+		assert.False(t, deadlineSet)
+
+		_, err = execute(t, root, nil, "check", "--timeout", "1m")
+		assert.NoError(t, err)
+		assert.True(t, deadlineSet)
+	})
+	t.Run("releases the timeout when the command fails", func(t *testing.T) {
+		var ctx context.Context
+		root := &cobra.Command{Use: "pass"}
+		AddTimeoutFlag(root)
+		root.AddCommand(&cobra.Command{
+			Use: "fail",
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				ctx = cmd.Context()
+				return errors.New("failed")
+			},
+		})
+		_, err := execute(t, root, nil, "fail", "--timeout", "1h")
+		assert.ErrorContains(t, err, "failed")
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+	t.Run("negative timeout", func(t *testing.T) {
+		_, err := execute(t, newTimeoutRoot(), teststore.NewMockStore(), "get", "--timeout", "-1s", "foo")
+		assert.ErrorContains(t, err, "--timeout cannot be negative")
+	})
+}
@@ -22,6 +22,7 @@ import (
 	pass "github.com/docker/secrets-engine/plugins/pass/store"
 	"github.com/docker/secrets-engine/store"
 	"github.com/docker/secrets-engine/x/realms"
+	"github.com/docker/secrets-engine/x/secrets"
 )
 
 var _ plugin.Plugin = &passPlugin{}
@@ -62,6 +63,8 @@ func (m *passPlugin) GetSecrets(ctx context.Context, pattern plugin.Pattern) ([]
 		return nil, plugin.ErrNotFound
 	}
 
+	// the store returns a map, sort to not depend on its iteration order
+	secrets.SortEnvelopes(result)
 	return result, nil
 }
 
@@ -43,6 +43,20 @@ func Test_passPlugin(t *testing.T) {
 		assert.Equal(t, "bar", string(e[0].Value))
 		assert.Equal(t, envelopeSchemaVersion, e[0].Version)
 	})
+	t.Run("sorted by id", func(t *testing.T) {
+		mock := teststore.NewMockStore(teststore.WithStore(map[store.ID]store.Secret{
+			store.MustParseID("foo/c"): pass.NewPassValue([]byte("c")),
+			store.MustParseID("foo/a"): pass.NewPassValue([]byte("a")),
+			store.MustParseID("foo/b"): pass.NewPassValue([]byte("b")),
+		}))
+		p := &passPlugin{kc: mock}
+		e, err := p.GetSecrets(t.Context(), secrets.MustParsePattern("foo/*"))
+		require.NoError(t, err)
+		require.Len(t, e, 3)
+		for i, value := range []string{"a", "b", "c"} {
+			assert.Equal(t, value, string(e[i].Value))
+		}
+	})
 	t.Run("no secrets", func(t *testing.T) {
 		mock := teststore.NewMockStore(teststore.WithStore(map[store.ID]store.Secret{}))
 		p := &passPlugin{kc: mock}
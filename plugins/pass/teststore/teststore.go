@@ -19,6 +19,7 @@ import (
 	"maps"
 	"sync"
 
+	pass "github.com/docker/secrets-engine/plugins/pass/store"
 	"github.com/docker/secrets-engine/store"
 )
 
@@ -114,6 +115,16 @@ func (m *MockStore) Get(_ context.Context, id store.ID) (store.Secret, error) {
 	return secret, nil
 }
 
+func (m *MockStore) GetAll(ctx context.Context) (map[store.ID]store.Secret, error) {
+	m.lock.RLock()
+	errGetAll := m.errGetAll
+	m.lock.RUnlock()
+	if errGetAll != nil {
+		return nil, errGetAll
+	}
+	return m.Filter(ctx, store.MustParsePattern("**"))
+}
+
 func (m *MockStore) GetAllMetadata(_ context.Context) (map[store.ID]store.Secret, error) {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
@@ -161,3 +172,33 @@ func (m *MockStore) Filter(_ context.Context, pattern store.Pattern) (map[store.
 	}
 	return filtered, nil
 }
+
+func (m *MockStore) Close() error {
+	return nil
+}
+
+func (m *MockStore) FilterMetadata(_ context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if m.errFilter != nil {
+		return nil, m.errFilter
+	}
+
+	filtered := make(map[store.ID]store.Secret)
+	for id, secret := range m.store {
+		if !pattern.Match(id) {
+			continue
+		}
+		// like the keychain, only the metadata is read
+		metadata := &pass.PassValue{}
+		if err := metadata.SetMetadata(maps.Clone(secret.Metadata())); err != nil {
+			return nil, err
+		}
+		filtered[id] = metadata
+	}
+	if len(filtered) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+	return filtered, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// AbsentSaver can optionally be implemented by a [Store] that can save a
+// secret only if none is stored under its ID, see [SaveIfAbsent].
+type AbsentSaver interface {
+	SaveIfAbsent(ctx context.Context, id ID, secret Secret) error
+}
+
+// SaveIfAbsent saves secret under id like [Store.Save], unless a secret is
+// already stored under id, in which case [ErrCredentialExists] is returned
+// and nothing is written. It avoids clobbering a secret that someone else
+// just provisioned.
+//
+// Stores implementing [AbsentSaver] check and save as atomically as the
+// backend allows. For other stores, the check is a [Store.FilterMetadata]
+// call followed by a [Store.Save], so a secret saved concurrently in between
+// can still be overwritten.
+func SaveIfAbsent(ctx context.Context, s Store, id ID, secret Secret) error {
+	if a, ok := s.(AbsentSaver); ok {
+		return a.SaveIfAbsent(ctx, id, secret)
+	}
+
+	pattern, err := ParsePattern(id.String())
+	if err != nil {
+		return err
+	}
+	existing, err := s.FilterMetadata(ctx, pattern)
+	if err != nil && !errors.Is(err, ErrCredentialNotFound) {
+		return err
+	}
+	if len(existing) > 0 {
+		return ErrCredentialExists
+	}
+	return s.Save(ctx, id, secret)
+}
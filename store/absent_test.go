@@ -0,0 +1,53 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// savingStore extends [filterStore] with [Store.Save].
+type savingStore struct {
+	filterStore
+}
+
+func (s *savingStore) Save(_ context.Context, id ID, secret Secret) error {
+	s.secrets[id.String()] = secret
+	return nil
+}
+
+func TestSaveIfAbsent(t *testing.T) {
+	t.Run("saves a missing secret", func(t *testing.T) {
+		s := &savingStore{filterStore{secrets: map[string]Secret{}}}
+		secret := &testSecret{value: "new"}
+		require.NoError(t, SaveIfAbsent(t.Context(), s, MustParseID("app/a"), secret))
+		assert.Same(t, secret, s.secrets["app/a"])
+	})
+	t.Run("keeps an existing secret", func(t *testing.T) {
+		existing := &testSecret{value: "old"}
+		s := &savingStore{filterStore{secrets: map[string]Secret{"app/a": existing}}}
+		err := SaveIfAbsent(t.Context(), s, MustParseID("app/a"), &testSecret{value: "new"})
+		require.ErrorIs(t, err, ErrCredentialExists)
+		assert.Same(t, existing, s.secrets["app/a"])
+	})
+	t.Run("filter errors are returned", func(t *testing.T) {
+		err := SaveIfAbsent(t.Context(), errFilterStore{}, MustParseID("app/a"), &testSecret{})
+		assert.ErrorIs(t, err, errWrite)
+	})
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrBatchNotSupported is returned by [BeginBatch] for stores that do not
+	// implement [Batcher].
+	ErrBatchNotSupported = errors.New("batch not supported")
+	// ErrBatchClosed is returned when using a [Batch] that has already been
+	// committed or discarded.
+	ErrBatchClosed = errors.New("batch already committed or discarded")
+)
+
+// Batch stages writes to a store so that they can be applied together, e.g.
+// to import a certificate together with its key.
+//
+// Nothing is written to the store before [Batch.Commit] is called. Staging a
+// write for an ID that already has a staged write replaces it. A batch can
+// only be committed or discarded once.
+type Batch interface {
+	// Save stages saving secret under id, overwriting any existing secret.
+	Save(ctx context.Context, id ID, secret Secret) error
+	// Delete stages removing the secret stored under id.
+	Delete(ctx context.Context, id ID) error
+	// Commit applies all staged writes. If any of them fails, the writes
+	// that were already applied are rolled back as far as the store allows
+	// and the error is returned.
+	Commit(ctx context.Context) error
+	// Discard drops all staged writes without applying them.
+	Discard() error
+}
+
+// Batcher can optionally be implemented by a [Store] that supports applying
+// multiple writes atomically, or as atomically as the backend allows.
+type Batcher interface {
+	BeginBatch() (Batch, error)
+}
+
+// BeginBatch starts a new [Batch] on s, or returns [ErrBatchNotSupported] if
+// s does not implement [Batcher].
+func BeginBatch(s Store) (Batch, error) {
+	b, ok := s.(Batcher)
+	if !ok {
+		return nil, ErrBatchNotSupported
+	}
+	return b.BeginBatch()
+}
+
+// NewBestEffortBatch returns a [Batch] for stores that cannot stage writes
+// natively.
+//
+// On commit, the writes are applied one by one in the order they were first
+// staged. The current secret of each ID is read before it gets overwritten or
+// deleted, so that on failure the applied writes can be rolled back by
+// restoring the previous secrets. A rollback can itself fail, in which case
+// both errors are returned.
+func NewBestEffortBatch(s Store) Batch {
+	return &bestEffortBatch{store: s}
+}
+
+type batchOp struct {
+	id ID
+	// secret is nil for deletions
+	secret Secret
+}
+
+type bestEffortBatch struct {
+	store  Store
+	mu     sync.Mutex
+	ops    []batchOp
+	closed bool
+}
+
+func (b *bestEffortBatch) stage(op batchOp) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBatchClosed
+	}
+	if i := slices.IndexFunc(b.ops, func(o batchOp) bool { return o.id.String() == op.id.String() }); i != -1 {
+		b.ops[i] = op
+		return nil
+	}
+	b.ops = append(b.ops, op)
+	return nil
+}
+
+func (b *bestEffortBatch) Save(_ context.Context, id ID, secret Secret) error {
+	if err := Validate(secret); err != nil {
+		return err
+	}
+	return b.stage(batchOp{id: id, secret: secret})
+}
+
+func (b *bestEffortBatch) Delete(_ context.Context, id ID) error {
+	return b.stage(batchOp{id: id})
+}
+
+func (b *bestEffortBatch) Commit(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBatchClosed
+	}
+	b.closed = true
+
+	// previous holds the secret each applied op replaced, nil if there was
+	// none.
+	var previous []batchOp
+	for _, op := range b.ops {
+		prev, err := b.store.Get(ctx, op.id)
+		if errors.Is(err, ErrCredentialNotFound) {
+			prev, err = nil, nil
+		}
+		if err != nil {
+			return errors.Join(err, b.rollback(ctx, previous))
+		}
+		if op.secret != nil {
+			err = b.store.Upsert(ctx, op.id, op.secret)
+		} else if prev != nil {
+			err = b.store.Delete(ctx, op.id)
+		}
+		if err != nil {
+			return errors.Join(err, b.rollback(ctx, previous))
+		}
+		previous = append(previous, batchOp{id: op.id, secret: prev})
+	}
+	return nil
+}
+
+// rollback restores the secrets replaced by the applied ops in reverse order.
+func (b *bestEffortBatch) rollback(ctx context.Context, previous []batchOp) error {
+	var errs []error
+	for _, op := range slices.Backward(previous) {
+		var err error
+		if op.secret != nil {
+			err = b.store.Upsert(ctx, op.id, op.secret)
+		} else {
+			err = b.store.Delete(ctx, op.id)
+			if errors.Is(err, ErrCredentialNotFound) {
+				err = nil
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *bestEffortBatch) Discard() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBatchClosed
+	}
+	b.closed = true
+	b.ops = nil
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSecret struct {
+	value string
+}
+
+func (t *testSecret) Marshal() ([]byte, error)            { return []byte(t.value), nil }
+func (t *testSecret) Unmarshal(data []byte) error         { t.value = string(data); return nil }
+func (t *testSecret) Metadata() map[string]string         { return nil }
+func (t *testSecret) SetMetadata(map[string]string) error { return nil }
+
+// mapStore is a minimal in-memory [Store] that fails writes for failID.
+type mapStore struct {
+	Store
+	secrets map[string]Secret
+	failID  string
+}
+
+var errWrite = errors.New("write failed")
+
+func (m *mapStore) Get(_ context.Context, id ID) (Secret, error) {
+	s, ok := m.secrets[id.String()]
+	if !ok {
+		return nil, ErrCredentialNotFound
+	}
+	return s, nil
+}
+
+func (m *mapStore) Upsert(_ context.Context, id ID, secret Secret) error {
+	if id.String() == m.failID {
+		return errWrite
+	}
+	m.secrets[id.String()] = secret
+	return nil
+}
+
+func (m *mapStore) Delete(_ context.Context, id ID) error {
+	if id.String() == m.failID {
+		return errWrite
+	}
+	if _, ok := m.secrets[id.String()]; !ok {
+		return ErrCredentialNotFound
+	}
+	delete(m.secrets, id.String())
+	return nil
+}
+
+func TestBestEffortBatch(t *testing.T) {
+	newStore := func() *mapStore {
+		return &mapStore{secrets: map[string]Secret{
+			"cert": &testSecret{"old-cert"},
+			"ca":   &testSecret{"old-ca"},
+		}}
+	}
+
+	t.Run("nothing is written before commit", func(t *testing.T) {
+		s := newStore()
+		b := NewBestEffortBatch(s)
+		require.NoError(t, b.Save(t.Context(), MustParseID("cert"), &testSecret{"new-cert"}))
+		require.NoError(t, b.Delete(t.Context(), MustParseID("ca")))
+		assert.Equal(t, &testSecret{"old-cert"}, s.secrets["cert"])
+		assert.Contains(t, s.secrets, "ca")
+
+		require.NoError(t, b.Commit(t.Context()))
+		assert.Equal(t, &testSecret{"new-cert"}, s.secrets["cert"])
+		assert.NotContains(t, s.secrets, "ca")
+	})
+	t.Run("later writes to the same id replace earlier ones", func(t *testing.T) {
+		s := newStore()
+		b := NewBestEffortBatch(s)
+		require.NoError(t, b.Delete(t.Context(), MustParseID("cert")))
+		require.NoError(t, b.Save(t.Context(), MustParseID("cert"), &testSecret{"new-cert"}))
+		require.NoError(t, b.Delete(t.Context(), MustParseID("missing")))
+		require.NoError(t, b.Commit(t.Context()))
+		assert.Equal(t, &testSecret{"new-cert"}, s.secrets["cert"])
+	})
+	t.Run("failure rolls back applied writes", func(t *testing.T) {
+		s := newStore()
+		s.failID = "key"
+		b := NewBestEffortBatch(s)
+		require.NoError(t, b.Save(t.Context(), MustParseID("cert"), &testSecret{"new-cert"}))
+		require.NoError(t, b.Delete(t.Context(), MustParseID("ca")))
+		require.NoError(t, b.Save(t.Context(), MustParseID("new"), &testSecret{"new"}))
+		require.NoError(t, b.Save(t.Context(), MustParseID("key"), &testSecret{"new-key"}))
+
+		require.ErrorIs(t, b.Commit(t.Context()), errWrite)
+		assert.Equal(t, newStore().secrets, s.secrets)
+	})
+	t.Run("cannot be used after commit or discard", func(t *testing.T) {
+		b := NewBestEffortBatch(newStore())
+		require.NoError(t, b.Commit(t.Context()))
+		assert.ErrorIs(t, b.Commit(t.Context()), ErrBatchClosed)
+		assert.ErrorIs(t, b.Save(t.Context(), MustParseID("cert"), &testSecret{}), ErrBatchClosed)
+
+		s := newStore()
+		b = NewBestEffortBatch(s)
+		require.NoError(t, b.Delete(t.Context(), MustParseID("cert")))
+		require.NoError(t, b.Discard())
+		assert.ErrorIs(t, b.Discard(), ErrBatchClosed)
+		assert.Contains(t, s.secrets, "cert")
+	})
+}
+
+func TestBeginBatch(t *testing.T) {
+	_, err := BeginBatch(&mapStore{})
+	assert.ErrorIs(t, err, ErrBatchNotSupported)
+}
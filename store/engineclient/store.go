@@ -0,0 +1,172 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package engineclient provides a read-only [store.Store] backed by the
+// secrets engine.
+//
+// It allows code written against [store.Store] to consume the aggregated
+// view of all plugins registered with a running engine:
+//
+//	c, err := client.New()
+//	if err != nil {
+//		return err
+//	}
+//	s := engineclient.New(c, func(_ context.Context, _ store.ID) *mySecret {
+//		return &mySecret{}
+//	})
+package engineclient
+
+import (
+	"context"
+	"errors"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/x/secrets"
+)
+
+// ErrNotSupported is returned by write operations, as the engine only
+// exposes secrets for reading.
+var ErrNotSupported = errors.New("not supported by engine-backed store")
+
+var _ store.Store = &engineStore[store.Secret]{}
+
+type engineStore[T store.Secret] struct {
+	resolver secrets.Resolver
+	factory  store.Factory[T]
+}
+
+// New returns a read-only [store.Store] that resolves secrets through r,
+// which is usually a client connected to the secrets engine.
+//
+// Every resolved [secrets.Envelope] is converted into a [store.Secret]
+// created by f. Save, Upsert and Delete return [ErrNotSupported].
+func New[T store.Secret](r secrets.Resolver, f store.Factory[T]) store.Store {
+	return &engineStore[T]{
+		resolver: r,
+		factory:  f,
+	}
+}
+
+func (e *engineStore[T]) Delete(context.Context, store.ID) error {
+	return ErrNotSupported
+}
+
+func (e *engineStore[T]) Save(context.Context, store.ID, store.Secret) error {
+	return ErrNotSupported
+}
+
+func (e *engineStore[T]) Upsert(context.Context, store.ID, store.Secret) error {
+	return ErrNotSupported
+}
+
+// Close implements [store.Store]. The resolver is owned by the caller and is
+// not closed.
+func (e *engineStore[T]) Close() error {
+	return nil
+}
+
+func (e *engineStore[T]) Get(ctx context.Context, id store.ID) (store.Secret, error) {
+	// a valid ID is always a valid pattern that only matches itself
+	pattern, err := store.ParsePattern(id.String())
+	if err != nil {
+		return nil, err
+	}
+	envelopes, err := e.getSecrets(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer clearValues(envelopes)
+
+	for _, envelope := range envelopes {
+		if envelope.ID.String() == id.String() {
+			return e.toSecret(ctx, envelope, true)
+		}
+	}
+	return nil, store.ErrCredentialNotFound
+}
+
+func (e *engineStore[T]) GetAll(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return e.filter(ctx, store.MustParsePattern("**"), true)
+}
+
+func (e *engineStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return e.filter(ctx, store.MustParsePattern("**"), false)
+}
+
+func (e *engineStore[T]) Filter(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	return e.filter(ctx, pattern, true)
+}
+
+func (e *engineStore[T]) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	return e.filter(ctx, pattern, false)
+}
+
+func (e *engineStore[T]) filter(ctx context.Context, pattern store.Pattern, withValue bool) (map[store.ID]store.Secret, error) {
+	envelopes, err := e.getSecrets(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer clearValues(envelopes)
+
+	secrets := map[store.ID]store.Secret{}
+	for _, envelope := range envelopes {
+		// multiple providers may resolve the same ID, the engine returns
+		// them in order of precedence.
+		if _, ok := secrets[envelope.ID]; ok {
+			continue
+		}
+		secret, err := e.toSecret(ctx, envelope, withValue)
+		if err != nil {
+			return nil, err
+		}
+		secrets[envelope.ID] = secret
+	}
+	return secrets, nil
+}
+
+func (e *engineStore[T]) getSecrets(ctx context.Context, pattern store.Pattern) ([]secrets.Envelope, error) {
+	envelopes, err := e.resolver.GetSecrets(ctx, pattern)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return nil, store.ErrCredentialNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(envelopes) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+	return envelopes, nil
+}
+
+// toSecret converts an envelope into a secret created by the factory.
+// When withValue is false only [store.Secret.SetMetadata] is called.
+func (e *engineStore[T]) toSecret(ctx context.Context, envelope secrets.Envelope, withValue bool) (store.Secret, error) {
+	secret := e.factory(ctx, envelope.ID)
+	if err := secret.SetMetadata(envelope.Metadata); err != nil {
+		return nil, err
+	}
+	if !withValue {
+		return secret, nil
+	}
+	if err := secret.Unmarshal(envelope.Value); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func clearValues(envelopes []secrets.Envelope) {
+	for _, envelope := range envelopes {
+		clear(envelope.Value)
+	}
+}
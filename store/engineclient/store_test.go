@@ -0,0 +1,132 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engineclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/mocks"
+	"github.com/docker/secrets-engine/x/secrets"
+)
+
+type fakeResolver struct {
+	envelopes []secrets.Envelope
+	err       error
+}
+
+func (f *fakeResolver) GetSecrets(_ context.Context, pattern secrets.Pattern) ([]secrets.Envelope, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var result []secrets.Envelope
+	for _, e := range f.envelopes {
+		if pattern.Match(e.ID) {
+			// hand out a copy, as the store clears the values it received
+			e.Value = append([]byte(nil), e.Value...)
+			result = append(result, e)
+		}
+	}
+	if len(result) == 0 {
+		return nil, secrets.ErrNotFound
+	}
+	return result, nil
+}
+
+func newMockCredential(_ context.Context, _ store.ID) *mocks.MockCredential {
+	return &mocks.MockCredential{}
+}
+
+func newTestStore(envelopes ...secrets.Envelope) store.Store {
+	return New(&fakeResolver{envelopes: envelopes}, newMockCredential)
+}
+
+func TestEngineStore(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(
+		secrets.Envelope{ID: secrets.MustParseID("foo/bar"), Value: []byte("bob:pass"), Metadata: map[string]string{"a": "b"}},
+		secrets.Envelope{ID: secrets.MustParseID("foo/bar"), Value: []byte("alice:shadowed")},
+		secrets.Envelope{ID: secrets.MustParseID("foo/baz"), Value: []byte("jeff:pass")},
+		secrets.Envelope{ID: secrets.MustParseID("other"), Value: []byte("sam:pass")},
+	)
+
+	t.Run("get", func(t *testing.T) {
+		secret, err := s.Get(t.Context(), secrets.MustParseID("foo/bar"))
+		require.NoError(t, err)
+		assert.Equal(t, &mocks.MockCredential{Username: "bob", Password: "pass", Attributes: map[string]string{"a": "b"}}, secret)
+	})
+	t.Run("get unknown", func(t *testing.T) {
+		_, err := s.Get(t.Context(), secrets.MustParseID("unknown"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+	t.Run("filter returns the first envelope per ID", func(t *testing.T) {
+		result, err := s.Filter(t.Context(), secrets.MustParsePattern("foo/*"))
+		require.NoError(t, err)
+		assert.Equal(t, map[store.ID]store.Secret{
+			secrets.MustParseID("foo/bar"): &mocks.MockCredential{Username: "bob", Password: "pass", Attributes: map[string]string{"a": "b"}},
+			secrets.MustParseID("foo/baz"): &mocks.MockCredential{Username: "jeff", Password: "pass"},
+		}, result)
+	})
+	t.Run("filter without matches", func(t *testing.T) {
+		_, err := s.Filter(t.Context(), secrets.MustParsePattern("unknown/**"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+	t.Run("get all metadata does not unmarshal values", func(t *testing.T) {
+		result, err := s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, result, 3)
+		secret := result[secrets.MustParseID("foo/bar")]
+		assert.Equal(t, map[string]string{"a": "b"}, secret.Metadata())
+		assert.Empty(t, secret.(*mocks.MockCredential).Password)
+	})
+	t.Run("get all unmarshals values", func(t *testing.T) {
+		result, err := s.GetAll(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, result, 3)
+		assert.Equal(t, "pass", result[secrets.MustParseID("foo/bar")].(*mocks.MockCredential).Password)
+	})
+	t.Run("filter metadata does not unmarshal values", func(t *testing.T) {
+		result, err := s.FilterMetadata(t.Context(), secrets.MustParsePattern("foo/*"))
+		require.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Empty(t, result[secrets.MustParseID("foo/baz")].(*mocks.MockCredential).Password)
+	})
+	t.Run("writes are not supported", func(t *testing.T) {
+		id := secrets.MustParseID("foo/bar")
+		assert.ErrorIs(t, s.Save(t.Context(), id, &mocks.MockCredential{}), ErrNotSupported)
+		assert.ErrorIs(t, s.Upsert(t.Context(), id, &mocks.MockCredential{}), ErrNotSupported)
+		assert.ErrorIs(t, s.Delete(t.Context(), id), ErrNotSupported)
+	})
+}
+
+func TestEngineStoreErrors(t *testing.T) {
+	t.Parallel()
+	t.Run("resolver errors are returned", func(t *testing.T) {
+		errUnavailable := errors.New("engine unavailable")
+		s := New(&fakeResolver{err: errUnavailable}, newMockCredential)
+		_, err := s.Get(t.Context(), secrets.MustParseID("foo"))
+		assert.ErrorIs(t, err, errUnavailable)
+	})
+	t.Run("unmarshal errors are returned", func(t *testing.T) {
+		s := newTestStore(secrets.Envelope{ID: secrets.MustParseID("foo"), Value: []byte("no-separator")})
+		_, err := s.Get(t.Context(), secrets.MustParseID("foo"))
+		assert.ErrorContains(t, err, "failed to unmarshal")
+	})
+}
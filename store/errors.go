@@ -14,6 +14,14 @@
 
 package store
 
-import "github.com/docker/secrets-engine/x/secrets"
+import (
+	"errors"
+
+	"github.com/docker/secrets-engine/x/secrets"
+)
 
 var ErrCredentialNotFound = secrets.ErrNotFound
+
+// ErrCredentialExists is returned by [SaveIfAbsent] when a secret is already
+// stored under the ID.
+var ErrCredentialExists = errors.New("credential already exists")
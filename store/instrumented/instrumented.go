@@ -0,0 +1,204 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instrumented provides a [store.Store] decorator recording the
+// duration and outcome of every operation through OpenTelemetry metrics.
+//
+// Each operation records a histogram named "store.<operation>.duration", in
+// seconds, with the following attributes:
+//
+//   - backend: the name given to [Wrap], e.g. "keychain"
+//   - outcome: "success", "not_found" or "error"
+//
+// Operations returning [store.ErrCredentialNotFound] are recorded with the
+// "not_found" outcome, since a missing secret is an expected result rather
+// than a failure of the backend.
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/x/telemetry"
+)
+
+const meterName = "github.com/docker/secrets-engine/store/instrumented"
+
+const (
+	OutcomeSuccess  = "success"
+	OutcomeNotFound = "not_found"
+	OutcomeError    = "error"
+)
+
+var _ store.Store = &instrumentedStore{}
+
+type instrumentedStore struct {
+	inner     store.Store
+	tracker   telemetry.Tracker
+	backend   attribute.KeyValue
+	durations map[string]metric.Float64Histogram
+}
+
+var operations = []string{
+	"delete",
+	"get",
+	"get_all",
+	"get_all_metadata",
+	"save",
+	"save_if_absent",
+	"upsert",
+	"filter",
+	"filter_metadata",
+	"filter_by_labels",
+	"list",
+}
+
+// Wrap returns a [store.Store] recording metrics for every operation on
+// inner, tagged with backend. Metrics are recorded with the global
+// [otel.GetMeterProvider]. Errors other than [store.ErrCredentialNotFound]
+// are also reported to tracker, which may be nil.
+func Wrap(inner store.Store, tracker telemetry.Tracker, backend string) (store.Store, error) {
+	if inner == nil {
+		return nil, errors.New("store cannot be nil")
+	}
+	if backend == "" {
+		return nil, errors.New("backend name cannot be empty")
+	}
+	if tracker == nil {
+		tracker = telemetry.NoopTracker()
+	}
+
+	meter := otel.GetMeterProvider().Meter(meterName)
+	durations := make(map[string]metric.Float64Histogram, len(operations))
+	for _, op := range operations {
+		h, err := meter.Float64Histogram("store."+op+".duration",
+			metric.WithDescription("Duration of the store "+op+" operation"),
+			metric.WithUnit("s"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		durations[op] = h
+	}
+	return &instrumentedStore{
+		inner:     inner,
+		tracker:   tracker,
+		backend:   attribute.String("backend", backend),
+		durations: durations,
+	}, nil
+}
+
+// record stores the duration and outcome of the operation op started at
+// start.
+func (i *instrumentedStore) record(ctx context.Context, op string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	switch {
+	case errors.Is(err, store.ErrCredentialNotFound):
+		outcome = OutcomeNotFound
+	case err != nil:
+		outcome = OutcomeError
+		i.tracker.Notify(err, i.backend.Value.AsString(), op)
+	}
+	i.durations[op].Record(ctx, time.Since(start).Seconds(),
+		metric.WithAttributes(i.backend, attribute.String("outcome", outcome)),
+	)
+}
+
+func (i *instrumentedStore) Close() error {
+	return i.inner.Close()
+}
+
+func (i *instrumentedStore) Delete(ctx context.Context, id store.ID) (err error) {
+	defer func(start time.Time) { i.record(ctx, "delete", start, err) }(time.Now())
+	return i.inner.Delete(ctx, id)
+}
+
+func (i *instrumentedStore) Get(ctx context.Context, id store.ID) (_ store.Secret, err error) {
+	defer func(start time.Time) { i.record(ctx, "get", start, err) }(time.Now())
+	return i.inner.Get(ctx, id)
+}
+
+func (i *instrumentedStore) GetAll(ctx context.Context) (_ map[store.ID]store.Secret, err error) {
+	defer func(start time.Time) { i.record(ctx, "get_all", start, err) }(time.Now())
+	return i.inner.GetAll(ctx)
+}
+
+func (i *instrumentedStore) GetAllMetadata(ctx context.Context) (_ map[store.ID]store.Secret, err error) {
+	defer func(start time.Time) { i.record(ctx, "get_all_metadata", start, err) }(time.Now())
+	return i.inner.GetAllMetadata(ctx)
+}
+
+func (i *instrumentedStore) Save(ctx context.Context, id store.ID, secret store.Secret) (err error) {
+	defer func(start time.Time) { i.record(ctx, "save", start, err) }(time.Now())
+	return i.inner.Save(ctx, id, secret)
+}
+
+// SaveIfAbsent saves the secret unless one already exists, see
+// [store.SaveIfAbsent].
+func (i *instrumentedStore) SaveIfAbsent(ctx context.Context, id store.ID, secret store.Secret) (err error) {
+	defer func(start time.Time) { i.record(ctx, "save_if_absent", start, err) }(time.Now())
+	return store.SaveIfAbsent(ctx, i.inner, id, secret)
+}
+
+func (i *instrumentedStore) Upsert(ctx context.Context, id store.ID, secret store.Secret) (err error) {
+	defer func(start time.Time) { i.record(ctx, "upsert", start, err) }(time.Now())
+	return i.inner.Upsert(ctx, id, secret)
+}
+
+func (i *instrumentedStore) Filter(ctx context.Context, pattern store.Pattern) (_ map[store.ID]store.Secret, err error) {
+	defer func(start time.Time) { i.record(ctx, "filter", start, err) }(time.Now())
+	return i.inner.Filter(ctx, pattern)
+}
+
+func (i *instrumentedStore) FilterMetadata(ctx context.Context, pattern store.Pattern) (_ map[store.ID]store.Secret, err error) {
+	defer func(start time.Time) { i.record(ctx, "filter_metadata", start, err) }(time.Now())
+	return i.inner.FilterMetadata(ctx, pattern)
+}
+
+// List pages through the secrets of the wrapped store, see [store.List].
+func (i *instrumentedStore) List(ctx context.Context, pattern store.Pattern, opts store.ListOptions) (_ store.ListResult, err error) {
+	defer func(start time.Time) { i.record(ctx, "list", start, err) }(time.Now())
+	return store.List(ctx, i.inner, pattern, opts)
+}
+
+// FilterByLabels returns the secrets of the wrapped store carrying all of
+// labels, see [store.FilterByLabels].
+func (i *instrumentedStore) FilterByLabels(ctx context.Context, labels map[string]string) (_ map[store.ID]store.Secret, err error) {
+	defer func(start time.Time) { i.record(ctx, "filter_by_labels", start, err) }(time.Now())
+	return store.FilterByLabels(ctx, i.inner, labels)
+}
+
+// BeginBatch starts a batch on the wrapped store, see [store.BeginBatch].
+// Batched operations are not instrumented.
+func (i *instrumentedStore) BeginBatch() (store.Batch, error) {
+	return store.BeginBatch(i.inner)
+}
+
+// ListVersions lists the previous versions of a secret of the wrapped store,
+// see [store.ListVersions]. Versions are not instrumented.
+func (i *instrumentedStore) ListVersions(ctx context.Context, id store.ID) ([]store.VersionInfo, error) {
+	return store.ListVersions(ctx, i.inner, id)
+}
+
+// GetVersion returns a previous version of a secret of the wrapped store, see
+// [store.GetVersion]. Versions are not instrumented.
+func (i *instrumentedStore) GetVersion(ctx context.Context, id store.ID, version int) (store.Secret, error) {
+	return store.GetVersion(ctx, i.inner, id, version)
+}
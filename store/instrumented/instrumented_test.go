@@ -0,0 +1,148 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/mocks"
+)
+
+func setupMeter(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+	return reader
+}
+
+// outcomes returns the number of recorded durations per outcome of the
+// histogram name.
+func outcomes(t *testing.T, reader *sdkmetric.ManualReader, name string) map[string]uint64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	result := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			hist, ok := m.Data.(metricdata.Histogram[float64])
+			require.True(t, ok)
+			for _, dp := range hist.DataPoints {
+				backend, _ := dp.Attributes.Value(attribute.Key("backend"))
+				assert.Equal(t, "mock", backend.AsString())
+				outcome, _ := dp.Attributes.Value(attribute.Key("outcome"))
+				result[outcome.AsString()] += dp.Count
+			}
+		}
+	}
+	return result
+}
+
+type failingStore struct {
+	mocks.MockStore
+}
+
+func (f *failingStore) Get(context.Context, store.ID) (store.Secret, error) {
+	return nil, errors.New("backend unavailable")
+}
+
+type recordingTracker struct {
+	errs []error
+}
+
+func (r *recordingTracker) TrackEvent(any) {}
+
+func (r *recordingTracker) Notify(err error, _ ...interface{}) {
+	r.errs = append(r.errs, err)
+}
+
+func (r *recordingTracker) Flush(context.Context) error {
+	return nil
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("records every operation", func(t *testing.T) {
+		reader := setupMeter(t)
+		s, err := Wrap(&mocks.MockStore{}, nil, "mock")
+		require.NoError(t, err)
+
+		id := store.MustParseID("foo/bar")
+		pattern := store.MustParsePattern("foo/*")
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pw"}))
+		require.NoError(t, s.Upsert(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pw2"}))
+		_, err = s.Get(t.Context(), id)
+		require.NoError(t, err)
+		_, err = s.GetAll(t.Context())
+		require.NoError(t, err)
+		_, err = s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		_, err = s.Filter(t.Context(), pattern)
+		require.NoError(t, err)
+		_, err = s.FilterMetadata(t.Context(), pattern)
+		require.NoError(t, err)
+		_, err = store.FilterByLabels(t.Context(), s, nil)
+		require.NoError(t, err)
+		_, err = store.List(t.Context(), s, pattern, store.ListOptions{})
+		require.NoError(t, err)
+		require.NoError(t, store.SaveIfAbsent(t.Context(), s, store.MustParseID("foo/baz"), &mocks.MockCredential{Username: "alice", Password: "pw"}))
+		require.NoError(t, s.Delete(t.Context(), id))
+
+		for _, op := range operations {
+			assert.Equal(t, map[string]uint64{OutcomeSuccess: 1}, outcomes(t, reader, "store."+op+".duration"), op)
+		}
+	})
+	t.Run("not found is a distinct outcome", func(t *testing.T) {
+		reader := setupMeter(t)
+		tracker := &recordingTracker{}
+		s, err := Wrap(&mocks.MockStore{}, tracker, "mock")
+		require.NoError(t, err)
+
+		_, err = s.Get(t.Context(), store.MustParseID("missing"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+		assert.Equal(t, map[string]uint64{OutcomeNotFound: 1}, outcomes(t, reader, "store.get.duration"))
+		assert.Empty(t, tracker.errs)
+	})
+	t.Run("errors are recorded and reported", func(t *testing.T) {
+		reader := setupMeter(t)
+		tracker := &recordingTracker{}
+		s, err := Wrap(&failingStore{}, tracker, "mock")
+		require.NoError(t, err)
+
+		_, err = s.Get(t.Context(), store.MustParseID("foo"))
+		assert.ErrorContains(t, err, "backend unavailable")
+		assert.Equal(t, map[string]uint64{OutcomeError: 1}, outcomes(t, reader, "store.get.duration"))
+		assert.Len(t, tracker.errs, 1)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := Wrap(nil, nil, "mock")
+		assert.Error(t, err)
+		_, err = Wrap(&mocks.MockStore{}, nil, "")
+		assert.Error(t, err)
+	})
+}
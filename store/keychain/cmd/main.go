@@ -15,17 +15,23 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"path"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/docker/secrets-engine/store"
 	"github.com/docker/secrets-engine/store/keychain"
 	"github.com/docker/secrets-engine/store/mocks"
+	"github.com/docker/secrets-engine/x/logging"
 )
 
 // newCommand creates an example CLI that uses the keychain library
@@ -49,11 +55,16 @@ func newCommand(ctx context.Context) (*cobra.Command, error) {
 	if err != nil {
 		return nil, err
 	}
+	var withValues bool
 	list := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls"},
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			secrets, err := kc.GetAllMetadata(cmd.Context())
+			getAll := kc.GetAllMetadata
+			if withValues {
+				getAll = kc.GetAll
+			}
+			secrets, err := getAll(cmd.Context())
 			if errors.Is(err, store.ErrCredentialNotFound) {
 				fmt.Println("No Secrets found")
 				return nil
@@ -65,10 +76,18 @@ func newCommand(ctx context.Context) (*cobra.Command, error) {
 			for id, v := range secrets {
 				fmt.Printf("\nID: %s\n", id)
 				fmt.Printf("\nMetadata: %+v", v.Metadata())
+				if withValues {
+					val, err := v.Marshal()
+					if err != nil {
+						return err
+					}
+					fmt.Printf("\nValue: %s", val)
+				}
 			}
 			return nil
 		},
 	}
+	list.Flags().BoolVar(&withValues, "values", false, "Also print the secret values")
 
 	var (
 		username string
@@ -126,8 +145,69 @@ func newCommand(ctx context.Context) (*cobra.Command, error) {
 			return kc.Delete(cmd.Context(), id)
 		},
 	}
-	root := &cobra.Command{}
-	root.AddCommand(list, save, get, erase)
+	listAll := &cobra.Command{
+		Use:   "list-all",
+		Short: "List the secret IDs of every service the keychain can reach",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			services, err := keychain.ListAllServices(cmd.Context(), kc)
+			if err != nil {
+				return err
+			}
+			keys := slices.SortedFunc(maps.Keys(services), func(a, b keychain.ServiceKey) int {
+				return cmp.Or(strings.Compare(a.Group, b.Group), strings.Compare(a.Name, b.Name))
+			})
+			for _, key := range keys {
+				fmt.Printf("%s/%s\n", key.Group, key.Name)
+				for _, id := range services[key] {
+					fmt.Printf("  %s\n", id)
+				}
+			}
+			return nil
+		},
+	}
+	debug := &cobra.Command{
+		Use:    "debug",
+		Short:  "Diagnostic commands, not meant for regular use",
+		Hidden: true,
+	}
+	debug.AddCommand(listAll)
+
+	var (
+		timeout  time.Duration
+		logLevel = logging.LevelInfo
+	)
+	root := &cobra.Command{
+		// bound each operation, keychain prompts included, when --timeout is set
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if timeout < 0 {
+				return errors.New("--timeout cannot be negative")
+			}
+			level, err := logging.LevelOrEnv(logLevel, cmd.Flags().Changed("log-level"))
+			if err != nil {
+				return err
+			}
+			cmd.SetContext(logging.WithLogger(cmd.Context(), logging.NewLevelLogger("", level)))
+			if timeout > 0 {
+				ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+				cmd.SetContext(ctx)
+				// cobra skips the post-run hooks when RunE fails, release
+				// the timeout once RunE returned instead
+				if runE := cmd.RunE; runE != nil {
+					cmd.RunE = func(cmd *cobra.Command, args []string) error {
+						defer cancel()
+						return runE(cmd, args)
+					}
+				} else {
+					cancel()
+				}
+			}
+			return nil
+		},
+	}
+	root.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Maximum duration of each operation, including keychain prompts (0 means no timeout)")
+	root.PersistentFlags().Var(&logLevel, "log-level", fmt.Sprintf("Log level: debug, info, warn or error (default from %s, else info)", logging.LevelEnv))
+	root.AddCommand(list, save, get, erase, debug)
 
 	return root, nil
 }
@@ -248,9 +248,11 @@ func (s *SecretService) Obj(path dbus.ObjectPath) dbus.BusObject {
 //
 // https://specifications.freedesktop.org/secret-service-spec/latest/index.html
 const (
-	collectionsProperty  = "org.freedesktop.Secret.Service.Collections"
-	readAliasMethod      = "org.freedesktop.Secret.Service.ReadAlias"
-	collectionLockedProp = "org.freedesktop.Secret.Collection.Locked"
+	collectionsProperty    = "org.freedesktop.Secret.Service.Collections"
+	readAliasMethod        = "org.freedesktop.Secret.Service.ReadAlias"
+	createCollectionMethod = "org.freedesktop.Secret.Service.CreateCollection"
+	collectionLockedProp   = "org.freedesktop.Secret.Collection.Locked"
+	collectionLabelProp    = "org.freedesktop.Secret.Collection.Label"
 )
 
 // Collections returns the object paths of every collection known to the secret
@@ -278,6 +280,35 @@ func (s *SecretService) ReadAlias(alias string) (dbus.ObjectPath, error) {
 	return path, nil
 }
 
+// CreateCollection creates a collection with the given label and assigns it
+// to alias (e.g. "default"), returning its object path. The secret service
+// usually prompts the user for the password protecting the new collection.
+func (s *SecretService) CreateCollection(label, alias string) (dbus.ObjectPath, error) {
+	properties := map[string]dbus.Variant{collectionLabelProp: dbus.MakeVariant(label)}
+	var collection, prompt dbus.ObjectPath
+	err := s.ServiceObj().
+		Call(createCollectionMethod, NilFlags, properties, alias).
+		Store(&collection, &prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create collection: %w", err)
+	}
+	if prompt == NullPrompt {
+		return collection, nil
+	}
+	result, err := s.PromptAndWait(prompt)
+	if err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", errors.New("create collection prompt returned no collection")
+	}
+	collection, ok := result.Value().(dbus.ObjectPath)
+	if !ok {
+		return "", errors.New("unexpected type for created collection")
+	}
+	return collection, nil
+}
+
 // IsLocked reports whether the given collection is currently locked.
 func (s *SecretService) IsLocked(collection dbus.ObjectPath) (bool, error) {
 	variant, err := s.Obj(collection).GetProperty(collectionLockedProp)
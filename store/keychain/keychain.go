@@ -17,19 +17,26 @@ package keychain
 import (
 	"context"
 	"errors"
+	"fmt"
 	"maps"
 	"slices"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/docker/secrets-engine/store"
 )
 
-var _ store.Store = &keychainStore[store.Secret]{}
+var (
+	_ store.Store       = &keychainStore[store.Secret]{}
+	_ store.AbsentSaver = &keychainStore[store.Secret]{}
+)
 
 // ErrNoDefaultCollection is returned when the secret service has no usable
 // default collection (no 'login' collection and no collection assigned to the
 // 'default' alias). This typically happens on headless hosts where the keyring
-// has not been initialized.
+// has not been initialized, see [WithCreateDefaultCollection].
 //
 // NOTE: this condition is currently specific to the Linux keyring (the
 // freedesktop Secret Service). macOS and Windows have no equivalent "default
@@ -81,6 +88,7 @@ func (f optionFunc[K]) apply(s K) error { return f(s) }
 
 type darwinOptions interface {
 	setUseDataProtectionKeychain(bool)
+	setAccessibility(Accessibility)
 }
 
 type DarwinOptions optionFunc[darwinOptions]
@@ -106,6 +114,143 @@ func WithUseDataProtectionKeychain() DarwinOptions {
 	}
 }
 
+// Accessibility controls when the items saved in the macOS keychain can be
+// read, see [WithAccessibility].
+type Accessibility int
+
+const (
+	// AccessibleAfterFirstUnlock items can be read once the device has been
+	// unlocked after a restart. It is the default.
+	AccessibleAfterFirstUnlock Accessibility = iota
+	// AccessibleWhenUnlocked items can only be read while the device is
+	// unlocked, e.g. not while the screen is locked.
+	AccessibleWhenUnlocked
+	// AccessibleAfterFirstUnlockThisDeviceOnly is like
+	// [AccessibleAfterFirstUnlock] but items never leave the device, e.g.
+	// through backups or iCloud keychain.
+	AccessibleAfterFirstUnlockThisDeviceOnly
+	// AccessibleWhenUnlockedThisDeviceOnly is like [AccessibleWhenUnlocked]
+	// but items never leave the device.
+	AccessibleWhenUnlockedThisDeviceOnly
+	// AccessibleWhenPasscodeSetThisDeviceOnly items can only be read while
+	// the device is unlocked and has a passcode set. They never leave the
+	// device and are removed when the passcode is.
+	AccessibleWhenPasscodeSetThisDeviceOnly
+)
+
+// WithAccessibility sets when the saved items can be read, e.g.
+// [AccessibleWhenUnlocked] for secrets that must not be readable while the
+// screen is locked. It only applies to items saved afterwards.
+func WithAccessibility(class Accessibility) DarwinOptions {
+	return func(do darwinOptions) error {
+		if class < AccessibleAfterFirstUnlock || class > AccessibleWhenPasscodeSetThisDeviceOnly {
+			return fmt.Errorf("invalid keychain accessibility: %d", class)
+		}
+		do.setAccessibility(class)
+		return nil
+	}
+}
+
+type labelOptions interface {
+	setItemLabelFunc(func(id store.ID) string)
+}
+
+// WithItemLabelFunc customizes the label of the items shown by the OS
+// keychain UI, e.g. to display "Docker — db/password (production)" instead
+// of the default "group:name:id" label.
+//
+// The label is only used for display and is set when a secret is saved. On
+// Windows the credential target name is used to look secrets up, so it
+// keeps the default label and the custom label is stored as the credential
+// comment instead.
+func WithItemLabelFunc(f func(id store.ID) string) Option {
+	return optionFunc[any](func(settings any) error {
+		if f == nil {
+			return errors.New("item label func cannot be nil")
+		}
+		s, ok := settings.(labelOptions)
+		if !ok {
+			return errSkipOptions
+		}
+		s.setItemLabelFunc(f)
+		return nil
+	})
+}
+
+type serviceRetryOptions interface {
+	setServiceRetry(attempts int, backoff time.Duration)
+}
+
+// WithServiceRetry retries connecting to the keychain backend up to attempts
+// times, waiting backoff before the first retry and doubling it after each
+// one. It smooths over a backend that is briefly unavailable, e.g. right
+// after login. Once all attempts failed, the last error is returned.
+//
+// Only the Linux backend connects to a service (the Secret Service over
+// D-Bus) for every operation; the option is ignored on other platforms.
+// It defaults to 3 attempts with a 50ms backoff.
+func WithServiceRetry(attempts int, backoff time.Duration) Option {
+	return optionFunc[any](func(settings any) error {
+		if attempts < 1 {
+			return errors.New("service retry attempts must be at least 1")
+		}
+		if backoff < 0 {
+			return errors.New("service retry backoff cannot be negative")
+		}
+		s, ok := settings.(serviceRetryOptions)
+		if !ok {
+			return errSkipOptions
+		}
+		s.setServiceRetry(attempts, backoff)
+		return nil
+	})
+}
+
+type sessionReuseOptions interface {
+	setSessionReuse(bool)
+}
+
+// WithSessionReuse shares a single Secret Service connection and session
+// across all operations of the store instead of opening new ones for each
+// operation, which speeds up bulk operations. Operations of the store are
+// serialized and the connection is released by [store.Store.Close].
+//
+// Only the Linux backend connects to a service for every operation; the
+// option is ignored on other platforms.
+func WithSessionReuse() Option {
+	return optionFunc[any](func(settings any) error {
+		s, ok := settings.(sessionReuseOptions)
+		if !ok {
+			return errSkipOptions
+		}
+		s.setSessionReuse(true)
+		return nil
+	})
+}
+
+type createDefaultCollectionOptions interface {
+	setCreateDefaultCollection(bool)
+}
+
+// WithCreateDefaultCollection creates a collection assigned to the 'default'
+// alias when the Secret Service has neither a 'login' collection nor a
+// 'default' one, e.g. on a fresh headless or CI host, instead of failing
+// with [ErrNoDefaultCollection]. Creating the collection may prompt the user
+// for its password.
+//
+// Only the Linux backend has collections; the option is ignored on other
+// platforms. It is disabled by default.
+func WithCreateDefaultCollection(create bool) Option {
+	return optionFunc[any](func(settings any) error {
+		s, ok := settings.(createDefaultCollectionOptions)
+		if !ok {
+			return errSkipOptions
+		}
+		s.setCreateDefaultCollection(create)
+		return nil
+	})
+}
+
 // New creates a new keychain store.
 //
 // It takes ServiceGroup and ServiceName and a [Factory] as input.
@@ -128,6 +273,17 @@ func WithUseDataProtectionKeychain() DarwinOptions {
 // important to keep the service name unchanged once the service has stored credentials.
 // Changing the service name can be done, but would require migrating existing credentials.
 //
+// Both are validated up front so that a malformed value fails New rather
+// than a later operation. They must be non-empty valid UTF-8, without
+// control characters or leading and trailing whitespace. In addition:
+//   - on macOS the service group is a keychain access group, e.g.
+//     "TEAMID.com.example.app", made of ASCII letters, digits, '-' and '.'
+//     only, without empty dot-separated components;
+//   - on Windows neither may contain ':', which separates them in the
+//     credential target name used to look secrets up;
+//   - on Linux no further restrictions apply, both are stored as item
+//     attributes.
+//
 // [Factory] is a function used to instantiate new secrets of type T.
 //
 // ctx bounds the eager backend-availability probe New performs before returning
@@ -139,8 +295,8 @@ func WithUseDataProtectionKeychain() DarwinOptions {
 // is a no-op and ctx is unused. New does not retain ctx: it governs construction
 // only, not later store operations.
 func New[T store.Secret](ctx context.Context, serviceGroup, serviceName string, factory store.Factory[T], opts ...Option) (store.Store, error) {
-	if serviceGroup == "" || serviceName == "" {
-		return nil, errors.New("serviceGroup and serviceName are required")
+	if err := validateService(serviceGroup, serviceName); err != nil {
+		return nil, err
 	}
 
 	k := &keychainStore[T]{
@@ -167,12 +323,67 @@ func New[T store.Secret](ctx context.Context, serviceGroup, serviceName string,
 	return k, nil
 }
 
+// validateService checks the serviceGroup and serviceName given to [New]
+// against the rules shared by all platforms, then against the platform
+// specific ones of validatePlatformService.
+func validateService(serviceGroup, serviceName string) error {
+	for _, field := range []struct{ name, value string }{
+		{"serviceGroup", serviceGroup},
+		{"serviceName", serviceName},
+	} {
+		switch {
+		case field.value == "":
+			return fmt.Errorf("%s is required", field.name)
+		case !utf8.ValidString(field.value):
+			return fmt.Errorf("invalid %s %q: must be valid UTF-8", field.name, field.value)
+		case strings.TrimSpace(field.value) != field.value:
+			return fmt.Errorf("invalid %s %q: must not start or end with whitespace", field.name, field.value)
+		case strings.ContainsFunc(field.value, unicode.IsControl):
+			return fmt.Errorf("invalid %s %q: must not contain control characters", field.name, field.value)
+		}
+	}
+	return validatePlatformService(serviceGroup, serviceName)
+}
+
+// GetAll returns all the secrets of the service including their values, see
+// [keychainStore.Filter].
+func (k *keychainStore[T]) GetAll(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return k.Filter(ctx, store.MustParsePattern("**"))
+}
+
+var _ store.Batcher = &keychainStore[store.Secret]{}
+
+// BeginBatch returns a best-effort [store.Batch], see
+// [store.NewBestEffortBatch]. OS keychains cannot apply multiple writes
+// atomically, so a failed commit restores the previous secrets one by one.
+func (k *keychainStore[T]) BeginBatch() (store.Batch, error) {
+	return store.NewBestEffortBatch(k), nil
+}
+
 // itemLabel prefixes a secret ID with the service group and service name
 // e.g. group:name:id
 func (k *keychainStore[T]) itemLabel(id string) string {
 	return k.serviceGroup + ":" + k.serviceName + ":" + id
 }
 
+func (k *keychainStore[T]) setItemLabelFunc(f func(id store.ID) string) {
+	k.labelFunc = f
+}
+
+// displayLabel returns the label shown to users by the OS keychain UI.
+// It defaults to [keychainStore.itemLabel] unless [WithItemLabelFunc] was
+// provided.
+func (k *keychainStore[T]) displayLabel(id store.ID) (string, error) {
+	if k.labelFunc == nil {
+		return k.itemLabel(id.String()), nil
+	}
+	label := k.labelFunc(id)
+	if strings.TrimSpace(label) == "" {
+		return "", fmt.Errorf("item label for secret %s cannot be empty", id)
+	}
+	return label, nil
+}
+
 const (
 	serviceGroupKey = "service:group"
 	serviceNameKey  = "service:name"
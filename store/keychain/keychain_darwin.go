@@ -19,6 +19,8 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"strconv"
+	"strings"
 	"sync"
 
 	kc "github.com/docker/secrets-engine/store/keychain/internal/go-keychain"
@@ -37,17 +39,67 @@ type keychainStore[T store.Secret] struct {
 	serviceName               string
 	factory                   store.Factory[T]
 	useDataProtectionKeychain bool
+	accessibility             Accessibility
+	labelFunc                 func(id store.ID) string
 }
 
 func (k *keychainStore[T]) setUseDataProtectionKeychain(v bool) {
 	k.useDataProtectionKeychain = v
 }
 
+func (k *keychainStore[T]) setAccessibility(a Accessibility) {
+	k.accessibility = a
+}
+
+// accessible maps an [Accessibility] to the keychain attribute value.
+func (a Accessibility) accessible() kc.Accessible {
+	switch a {
+	case AccessibleWhenUnlocked:
+		return kc.AccessibleWhenUnlocked
+	case AccessibleAfterFirstUnlockThisDeviceOnly:
+		return kc.AccessibleAfterFirstUnlockThisDeviceOnly
+	case AccessibleWhenUnlockedThisDeviceOnly:
+		return kc.AccessibleWhenUnlockedThisDeviceOnly
+	case AccessibleWhenPasscodeSetThisDeviceOnly:
+		return kc.AccessibleWhenPasscodeSetThisDeviceOnly
+	default:
+		return kc.AccessibleAfterFirstUnlock
+	}
+}
+
 // ensureAvailable is the macOS no-op of the per-platform availability hook New
 // calls. The macOS Keychain is always available to a logged-in user, so New
 // never returns ErrKeychainUnavailable here. ctx is unused on macOS.
 func ensureAvailable(_ context.Context) error { return nil }
 
+// validatePlatformService checks that the service group is a well-formed
+// keychain access group, so that a typo fails [New] instead of surfacing as
+// an opaque SecItemAdd error on the first Save. Whether the group matches the
+// entitlements of the application can only be known by the keychain.
+func validatePlatformService(serviceGroup, _ string) error {
+	for part := range strings.SplitSeq(serviceGroup, ".") {
+		if part == "" {
+			return fmt.Errorf("invalid serviceGroup %q: keychain access groups cannot have empty dot-separated components", serviceGroup)
+		}
+		if strings.ContainsFunc(part, isNotAccessGroupRune) {
+			return fmt.Errorf("invalid serviceGroup %q: keychain access groups can only contain ASCII letters, digits, '-' and '.'", serviceGroup)
+		}
+	}
+	return nil
+}
+
+func isNotAccessGroupRune(r rune) bool {
+	isAllowed := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-'
+	return !isAllowed
+}
+
+// Close implements [store.Store].
+//
+// The keychain is accessed per operation, so there is nothing to release.
+func (k *keychainStore[T]) Close() error {
+	return nil
+}
+
 // newKeychainItem creates a new keychain item with valid default parameters.
 //
 // It uses a generic password class, which is suitable for most use cases.
@@ -65,7 +117,7 @@ func newKeychainItem[T store.Secret](id string, k *keychainStore[T]) kc.Item {
 	// MatchLimitOne is used to ensure we only get one item back when querying
 	// set this to MatchLimitAll if you want to retrieve all items
 	item.SetMatchLimit(kc.MatchLimitOne)
-	item.SetAccessible(kc.AccessibleAfterFirstUnlock)
+	item.SetAccessible(k.accessibility.accessible())
 	item.SetReturnAttributes(true)
 
 	item.SetService(k.serviceName)
@@ -101,12 +153,27 @@ func getItemWithData[T store.Secret](id string, k *keychainStore[T]) (*kc.QueryR
 	return &results[0], nil
 }
 
+// convertAttributes converts the generic attributes of a keychain item into
+// the string metadata of a [store.Secret].
+//
+// The store only ever writes string attributes, like the other platforms
+// where attributes are strings by design. Items written by other tools may
+// however hold other scalar types, which are converted to their string
+// representation instead of failing the whole query.
 func convertAttributes(attributes map[string]any) (map[string]string, error) {
 	attr := make(map[string]string, len(attributes))
 	for k, v := range attributes {
 		switch t := v.(type) {
 		case string:
 			attr[k] = t
+		case bool:
+			attr[k] = strconv.FormatBool(t)
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			attr[k] = fmt.Sprint(t)
+		case float32:
+			attr[k] = strconv.FormatFloat(float64(t), 'g', -1, 32)
+		case float64:
+			attr[k] = strconv.FormatFloat(t, 'g', -1, 64)
 		default:
 			return nil, fmt.Errorf("attributes of key %s has unsupported type %T", k, t)
 		}
@@ -181,6 +248,14 @@ func (k *keychainStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]sto
 }
 
 func (k *keychainStore[T]) Save(_ context.Context, id store.ID, secret store.Secret) error {
+	if err := store.Validate(secret); err != nil {
+		return err
+	}
+	label, err := k.displayLabel(id)
+	if err != nil {
+		return err
+	}
+
 	data, err := secret.Marshal()
 	if err != nil {
 		return err
@@ -191,7 +266,7 @@ func (k *keychainStore[T]) Save(_ context.Context, id store.ID, secret store.Sec
 	// only creation of a secret needs the label attribute.
 	// it is a user-friendly name for the item, which is displayed in the keychain UI.
 	// https://developer.apple.com/documentation/security/ksecattrlabel
-	item.SetLabel(k.itemLabel(id.String()))
+	item.SetLabel(label)
 
 	metadata := make(map[string]string)
 	maps.Copy(metadata, secret.Metadata())
@@ -207,12 +282,33 @@ func (k *keychainStore[T]) Save(_ context.Context, id store.ID, secret store.Sec
 	return mapKeychainError(kc.AddItem(item))
 }
 
+// SaveIfAbsent implements [store.AbsentSaver]. AddItem never overwrites an
+// existing item, its duplicate item error is returned as
+// [store.ErrCredentialExists]. It holds the same mutex as Upsert, so that it
+// cannot slip between the Delete and the Save of a concurrent Upsert.
+func (k *keychainStore[T]) SaveIfAbsent(ctx context.Context, id store.ID, secret store.Secret) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	err := k.Save(ctx, id, secret)
+	if errors.Is(err, kc.ErrorDuplicateItem) {
+		return store.ErrCredentialExists
+	}
+	return err
+}
+
 // Upsert atomically replaces a credential in the macOS Keychain.
 //
 // The macOS Keychain does not allow overwriting an existing item via AddItem,
 // so Upsert holds a mutex and performs a Delete followed by a Save to ensure
 // no concurrent Upsert can interleave between the two operations.
 func (k *keychainStore[T]) Upsert(ctx context.Context, id store.ID, secret store.Secret) error {
+	// validate before deleting so an invalid secret never removes the
+	// existing one.
+	if err := store.Validate(secret); err != nil {
+		return err
+	}
+
 	k.mu.Lock()
 	defer k.mu.Unlock()
 
@@ -285,6 +381,49 @@ func (k *keychainStore[T]) Filter(ctx context.Context, pattern store.Pattern) (m
 	return creds, nil
 }
 
+func (k *keychainStore[T]) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	item := newKeychainItem("", k)
+
+	// Same as Filter, but the data of matching items is never fetched so
+	// that the user does not get prompted for access.
+	item.SetMatchLimit(kc.MatchLimitAll)
+
+	results, err := kc.QueryItem(item)
+	if err != nil {
+		return nil, mapKeychainError(err)
+	}
+
+	creds := make(map[store.ID]store.Secret)
+	for _, result := range results {
+		// skip items that were not stored through the store.
+		id, err := store.ParseID(result.Account)
+		if err != nil {
+			continue
+		}
+		if !pattern.Match(id) {
+			continue
+		}
+
+		attributes, err := convertAttributes(result.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		safelyCleanMetadata(attributes)
+
+		secret := k.factory(ctx, id)
+		if err := secret.SetMetadata(attributes); err != nil {
+			return nil, err
+		}
+		creds[id] = secret
+	}
+
+	if len(creds) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+
+	return creds, nil
+}
+
 // loadSecret fetches the raw keychain data for id, zeroes it after use,
 // and returns a fully populated Secret.
 func (k *keychainStore[T]) loadSecret(ctx context.Context, id store.ID, attr map[string]string) (store.Secret, error) {
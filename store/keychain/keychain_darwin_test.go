@@ -242,10 +242,32 @@ func TestConvertAttributes(t *testing.T) {
 			"color": "blue",
 		}, converted)
 	})
-	t.Run("should error when a value has a non-string type", func(t *testing.T) {
+	t.Run("scalar values are converted to strings", func(t *testing.T) {
 		attributes := map[string]any{
-			"score": 20,
-			"color": "blue",
+			"score":   20,
+			"port":    int32(8080),
+			"size":    uint64(1 << 40),
+			"ratio":   0.5,
+			"weight":  float32(1.25),
+			"enabled": true,
+			"color":   "blue",
+		}
+		converted, err := convertAttributes(attributes)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"score":   "20",
+			"port":    "8080",
+			"size":    "1099511627776",
+			"ratio":   "0.5",
+			"weight":  "1.25",
+			"enabled": "true",
+			"color":   "blue",
+		}, converted)
+	})
+	t.Run("should error when a value has a non-scalar type", func(t *testing.T) {
+		attributes := map[string]any{
+			"scores": []int{20},
+			"color":  "blue",
 		}
 		converted, err := convertAttributes(attributes)
 		assert.ErrorContains(t, err, "unsupported type")
@@ -258,3 +280,19 @@ func TestConvertAttributes(t *testing.T) {
 		assert.Empty(t, converted)
 	})
 }
+
+func TestNewValidatesAccessGroup(t *testing.T) {
+	factory := func(_ context.Context, _ store.ID) *mocks.MockCredential {
+		return &mocks.MockCredential{}
+	}
+	for _, group := range []string{"com..test", ".com.test", "com.test.", "com test", "com_test", "com.tést"} {
+		t.Run(group, func(t *testing.T) {
+			_, err := New(t.Context(), group, "test", factory)
+			assert.ErrorContains(t, err, "invalid serviceGroup")
+		})
+	}
+	t.Run("team prefixed groups are valid", func(t *testing.T) {
+		_, err := New(t.Context(), "ABCDE12345.com.test-app.shared", "test", factory)
+		assert.NoError(t, err)
+	})
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"sync"
 	"time"
 
 	dbus "github.com/godbus/dbus/v5"
@@ -39,6 +40,10 @@ const (
 	// NOTE: do not use this directly, always call [getDefaultCollection]
 	loginKeychainObjectPath = dbus.ObjectPath("/org/freedesktop/secrets/collection/login")
 
+	// defaultCollectionLabel is the label of the collection created by
+	// [WithCreateDefaultCollection], as shown by keyring managers.
+	defaultCollectionLabel = "Default keyring"
+
 	// the null/root object path returned by the secret service when an alias is
 	// not assigned to any collection. It is syntactically valid (so
 	// [dbus.ObjectPath.IsValid] returns true) but does not point at a real
@@ -57,6 +62,7 @@ const (
 type secretService interface {
 	Collections() ([]dbus.ObjectPath, error)
 	ReadAlias(alias string) (dbus.ObjectPath, error)
+	CreateCollection(label, alias string) (dbus.ObjectPath, error)
 	IsLocked(collection dbus.ObjectPath) (bool, error)
 	OpenSession(mode kc.AuthenticationMode) (*kc.Session, error)
 	CloseSession(session *kc.Session)
@@ -125,6 +131,11 @@ var (
 // caller-supplied deadline always takes precedence.
 const defaultProbeTimeout = 2 * time.Second
 
+// validatePlatformService is the Linux no-op of the per-platform validation
+// hook New calls. The service group and name are stored as item attributes,
+// which accept any string.
+func validatePlatformService(_, _ string) error { return nil }
+
 // ensureAvailable eagerly checks that the secret service backend is reachable,
 // so New fails at construction time on a host without a usable keyring (for
 // example WSL with no D-Bus session bus, or no gnome-keyring/kwallet running)
@@ -173,13 +184,10 @@ func ensureAvailable(ctx context.Context) error {
 
 // getDefaultCollection gets the secret service collection dbus object path.
 //
-// It prefers the loginKeychainObjectPath, since most users on X11 would have
-// this available via PAM, see https://wiki.gnome.org/Projects/GnomeKeyring/Pam.
-//
-// As a fallback it queries the secret service for the default collection.
-// It is possible that the host does not have a collection set up, in that case
-// the only option is to error.
-func getDefaultCollection(service secretService) (dbus.ObjectPath, error) {
+// When there is no usable default collection, it creates one assigned to the
+// 'default' alias if create is set (see [WithCreateDefaultCollection]), or
+// returns [ErrNoDefaultCollection] otherwise.
+func getDefaultCollection(service secretService, create bool) (dbus.ObjectPath, error) {
 	collections, err := service.Collections()
 	if err != nil {
 		return "", err
@@ -194,7 +202,18 @@ func getDefaultCollection(service secretService) (dbus.ObjectPath, error) {
 		return "", err
 	}
 
-	return resolveDefaultCollection(collections, defaultKeychainObjectPath)
+	objectPath, err := resolveDefaultCollection(collections, defaultKeychainObjectPath)
+	if !errors.Is(err, ErrNoDefaultCollection) {
+		return objectPath, err
+	}
+	if !create {
+		return "", fmt.Errorf("%w: create one with a keyring manager, e.g. Seahorse, or use WithCreateDefaultCollection", err)
+	}
+	objectPath, err = service.CreateCollection(defaultCollectionLabel, "default")
+	if err != nil {
+		return "", fmt.Errorf("creating the default keychain collection: %w", err)
+	}
+	return objectPath, nil
 }
 
 // resolveDefaultCollection selects the collection to use given the available
@@ -272,8 +291,8 @@ const (
 	relockRetryMaxDelay  = 500 * time.Millisecond
 )
 
-// sleepFn is the sleep seam used by the relock backoff so tests can exercise the
-// retry loop without real delays. It is a package-level var with no
+// sleepFn is the sleep seam used by the relock and service retry backoffs so
+// tests can exercise the retry loops without real delays. It is a package-level var with no
 // synchronisation, so tests that swap it must not run in parallel.
 var sleepFn = time.Sleep
 
@@ -316,14 +335,93 @@ func withRelockRetry(service secretService, collectionPath dbus.ObjectPath, op f
 	return err
 }
 
+// Service retry defaults, see [WithServiceRetry].
+const (
+	defaultServiceRetryAttempts = 3
+	defaultServiceRetryBackoff  = 50 * time.Millisecond
+)
+
 type keychainStore[T store.Secret] struct {
 	serviceGroup string
 	serviceName  string
 	factory      store.Factory[T]
+	labelFunc    func(id store.ID) string
+
+	// createDefaultCollection is set by [WithCreateDefaultCollection].
+	createDefaultCollection bool
+
+	// serviceRetryAttempts and serviceRetryBackoff are set by
+	// [WithServiceRetry], zero values use the defaults.
+	serviceRetryAttempts int
+	serviceRetryBackoff  time.Duration
+
+	// reuseSession is set by [WithSessionReuse]. mu serializes operations on
+	// the shared connection and session, which are created by the first
+	// operation and released by Close.
+	reuseSession  bool
+	mu            sync.Mutex
+	sharedService secretService
+	sharedSession *kc.Session
 }
 
-func (k *keychainStore[T]) Delete(ctx context.Context, id store.ID) error {
-	service, err := operationService(ctx)
+func (k *keychainStore[T]) setSessionReuse(v bool) {
+	k.reuseSession = v
+}
+
+func (k *keychainStore[T]) setCreateDefaultCollection(v bool) {
+	k.createDefaultCollection = v
+}
+
+// Close implements [store.Store].
+//
+// It releases the session and connection shared across operations when
+// [WithSessionReuse] is used; otherwise there is nothing to release as every
+// operation opens and closes its own.
+func (k *keychainStore[T]) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.closeShared()
+}
+
+// closeShared releases the shared session and connection, if any. k.mu must
+// be held.
+func (k *keychainStore[T]) closeShared() error {
+	if k.sharedService == nil {
+		return nil
+	}
+	service := k.sharedService
+	service.CloseSession(k.sharedSession)
+	k.sharedService, k.sharedSession = nil, nil
+	return service.Close()
+}
+
+// withSession runs op against the secret service with an open session.
+//
+// By default a fresh connection and session are opened for op and closed
+// once it returns. With [WithSessionReuse], op runs on the store's shared
+// connection and session, created on first use. When the shared connection
+// turns out to be stale, e.g. because the D-Bus daemon or the secret service
+// restarted, it is dropped and op runs again on a fresh connection; the next
+// operation then creates a new shared one.
+func (k *keychainStore[T]) withSession(ctx context.Context, op func(secretService, *kc.Session) error) error {
+	if k.reuseSession {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if k.sharedService == nil {
+			service, session, err := k.openService(ctx)
+			if err != nil {
+				return err
+			}
+			k.sharedService, k.sharedSession = service, session
+		}
+		err := op(k.sharedService, k.sharedSession)
+		if !isStaleConnectionError(err) {
+			return err
+		}
+		_ = k.closeShared()
+	}
+
+	service, session, err := k.openService(ctx)
 	if err != nil {
 		return err
 	}
@@ -331,14 +429,92 @@ func (k *keychainStore[T]) Delete(ctx context.Context, id store.ID) error {
 	// its socket fd) when we return. Deferred before CloseSession so that, by
 	// LIFO order, the session is closed first and the connection last.
 	defer func() { _ = service.Close() }()
+	defer service.CloseSession(session)
+
+	return op(service, session)
+}
 
-	session, err := service.OpenSession(kc.AuthenticationDHAES)
+// openService dials a fresh secret service and opens a session on it. The
+// caller owns both and must close them.
+func (k *keychainStore[T]) openService(ctx context.Context) (secretService, *kc.Session, error) {
+	service, err := k.dialService(ctx)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	defer service.CloseSession(session)
+	session, err := k.openSession(service)
+	if err != nil {
+		_ = service.Close()
+		return nil, nil, err
+	}
+	return service, session, nil
+}
+
+// isStaleConnectionError reports whether err means a connection or session
+// can no longer be used and a new one must be opened.
+func isStaleConnectionError(err error) bool {
+	if errors.Is(err, dbus.ErrClosed) {
+		return true
+	}
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		return false
+	}
+	switch dbusErr.Name {
+	case "org.freedesktop.DBus.Error.ServiceUnknown", "org.freedesktop.Secret.Error.NoSession":
+		return true
+	}
+	return false
+}
+
+func (k *keychainStore[T]) setServiceRetry(attempts int, backoff time.Duration) {
+	k.serviceRetryAttempts = attempts
+	k.serviceRetryBackoff = backoff
+}
+
+func (k *keychainStore[T]) serviceRetry() (int, time.Duration) {
+	if k.serviceRetryAttempts == 0 {
+		return defaultServiceRetryAttempts, defaultServiceRetryBackoff
+	}
+	return k.serviceRetryAttempts, k.serviceRetryBackoff
+}
+
+// withServiceRetry calls f until it succeeds or the configured attempts are
+// exhausted, in which case the last error is returned.
+func withServiceRetry[V any](attempts int, backoff time.Duration, f func() (V, error)) (V, error) {
+	v, err := f()
+	for attempt := 1; attempt < attempts && err != nil; attempt++ {
+		sleepFn(backoff)
+		backoff *= 2
+		v, err = f()
+	}
+	return v, err
+}
+
+// dialService dials a fresh secret service for a store operation (see
+// [operationService]), retrying transient failures.
+func (k *keychainStore[T]) dialService(ctx context.Context) (secretService, error) {
+	attempts, backoff := k.serviceRetry()
+	return withServiceRetry(attempts, backoff, func() (secretService, error) {
+		return operationService(ctx)
+	})
+}
 
-	objectPath, err := getDefaultCollection(service)
+// openSession opens a session on service, retrying transient failures.
+func (k *keychainStore[T]) openSession(service secretService) (*kc.Session, error) {
+	attempts, backoff := k.serviceRetry()
+	return withServiceRetry(attempts, backoff, func() (*kc.Session, error) {
+		return service.OpenSession(kc.AuthenticationDHAES)
+	})
+}
+
+func (k *keychainStore[T]) Delete(ctx context.Context, id store.ID) error {
+	return k.withSession(ctx, func(service secretService, _ *kc.Session) error {
+		return k.delete(id, service)
+	})
+}
+
+func (k *keychainStore[T]) delete(id store.ID, service secretService) error {
+	objectPath, err := getDefaultCollection(service, k.createDefaultCollection)
 	if err != nil {
 		return err
 	}
@@ -372,22 +548,16 @@ func (k *keychainStore[T]) Delete(ctx context.Context, id store.ID) error {
 }
 
 func (k *keychainStore[T]) Get(ctx context.Context, id store.ID) (store.Secret, error) {
-	service, err := operationService(ctx)
-	if err != nil {
-		return nil, err
-	}
-	// NewService dials a fresh private session-bus connection; close it (and
-	// its socket fd) when we return. Deferred before CloseSession so that, by
-	// LIFO order, the session is closed first and the connection last.
-	defer func() { _ = service.Close() }()
-
-	session, err := service.OpenSession(kc.AuthenticationDHAES)
-	if err != nil {
-		return nil, err
-	}
-	defer service.CloseSession(session)
+	var secret store.Secret
+	err := k.withSession(ctx, func(service secretService, session *kc.Session) (err error) {
+		secret, err = k.get(ctx, id, service, session)
+		return err
+	})
+	return secret, err
+}
 
-	objectPath, err := getDefaultCollection(service)
+func (k *keychainStore[T]) get(ctx context.Context, id store.ID, service secretService, session *kc.Session) (store.Secret, error) {
+	objectPath, err := getDefaultCollection(service, k.createDefaultCollection)
 	if err != nil {
 		return nil, err
 	}
@@ -444,22 +614,16 @@ func (k *keychainStore[T]) Get(ctx context.Context, id store.ID) (store.Secret,
 }
 
 func (k *keychainStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.Secret, error) {
-	service, err := operationService(ctx)
-	if err != nil {
-		return nil, err
-	}
-	// NewService dials a fresh private session-bus connection; close it (and
-	// its socket fd) when we return. Deferred before CloseSession so that, by
-	// LIFO order, the session is closed first and the connection last.
-	defer func() { _ = service.Close() }()
-
-	session, err := service.OpenSession(kc.AuthenticationDHAES)
-	if err != nil {
-		return nil, err
-	}
-	defer service.CloseSession(session)
+	var credentials map[store.ID]store.Secret
+	err := k.withSession(ctx, func(service secretService, _ *kc.Session) (err error) {
+		credentials, err = k.getAllMetadata(ctx, service)
+		return err
+	})
+	return credentials, err
+}
 
-	objectPath, err := getDefaultCollection(service)
+func (k *keychainStore[T]) getAllMetadata(ctx context.Context, service secretService) (map[store.ID]store.Secret, error) {
+	objectPath, err := getDefaultCollection(service, k.createDefaultCollection)
 	if err != nil {
 		return nil, err
 	}
@@ -514,23 +678,89 @@ func (k *keychainStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]sto
 	return credentials, nil
 }
 
+// listAllServices implements [allServicesLister]. Searching the default
+// collection without attributes matches all of its items.
+func (k *keychainStore[T]) listAllServices(ctx context.Context) (map[ServiceKey][]store.ID, error) {
+	services := map[ServiceKey][]store.ID{}
+	err := k.withSession(ctx, func(service secretService, _ *kc.Session) error {
+		objectPath, err := getDefaultCollection(service, k.createDefaultCollection)
+		if err != nil {
+			return err
+		}
+
+		err = isCollectionUnlocked(objectPath, service)
+		if err != nil && !errors.Is(err, errCollectionLocked) {
+			return err
+		}
+		if errors.Is(err, errCollectionLocked) {
+			if err := service.Unlock([]dbus.ObjectPath{objectPath}); err != nil {
+				return err
+			}
+		}
+
+		itemPaths, err := service.SearchCollection(objectPath, map[string]string{})
+		if err != nil {
+			return fmt.Errorf("failed to search collection: %w", err)
+		}
+		clear(services)
+		for _, itemPath := range itemPaths {
+			attributes, err := service.GetAttributes(itemPath)
+			if err != nil {
+				return err
+			}
+			key := ServiceKey{Group: attributes[serviceGroupKey], Name: attributes[serviceNameKey]}
+			if key.Group == "" || key.Name == "" {
+				continue
+			}
+			id, err := store.ParseID(attributes[secretIDKey])
+			if err != nil {
+				continue
+			}
+			services[key] = append(services[key], id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
 func (k *keychainStore[T]) Save(ctx context.Context, id store.ID, secret store.Secret) error {
-	service, err := operationService(ctx)
+	if err := store.Validate(secret); err != nil {
+		return err
+	}
+	label, err := k.displayLabel(id)
 	if err != nil {
 		return err
 	}
-	// NewService dials a fresh private session-bus connection; close it (and
-	// its socket fd) when we return. Deferred before CloseSession so that, by
-	// LIFO order, the session is closed first and the connection last.
-	defer func() { _ = service.Close() }()
 
-	session, err := service.OpenSession(kc.AuthenticationDHAES)
+	return k.withSession(ctx, func(service secretService, session *kc.Session) error {
+		return k.save(id, secret, label, service, session, false)
+	})
+}
+
+// SaveIfAbsent implements [store.AbsentSaver]. The existing items are looked
+// up right before creating the new one on the same session, but the secret
+// service offers no way to make both a single operation.
+func (k *keychainStore[T]) SaveIfAbsent(ctx context.Context, id store.ID, secret store.Secret) error {
+	if err := store.Validate(secret); err != nil {
+		return err
+	}
+	label, err := k.displayLabel(id)
 	if err != nil {
 		return err
 	}
-	defer service.CloseSession(session)
 
-	objectPath, err := getDefaultCollection(service)
+	return k.withSession(ctx, func(service secretService, session *kc.Session) error {
+		return k.save(id, secret, label, service, session, true)
+	})
+}
+
+// save creates or updates the item of id. With createOnly, an existing item
+// is left untouched and [store.ErrCredentialExists] is returned.
+func (k *keychainStore[T]) save(id store.ID, secret store.Secret, label string, service secretService, session *kc.Session, createOnly bool) error {
+	objectPath, err := getDefaultCollection(service, k.createDefaultCollection)
 	if err != nil {
 		return err
 	}
@@ -561,8 +791,6 @@ func (k *keychainStore[T]) Save(ctx context.Context, id store.ID, secret store.S
 	safelySetMetadata(k.serviceGroup, k.serviceName, attributes)
 	safelySetID(id, attributes)
 
-	label := k.itemLabel(id.String())
-
 	// Find existing items for this identity by the stable triple only
 	// {service:group, service:name, id}, never the volatile metadata, so a
 	// changed metadata value can never hide a previously-stored item. This is
@@ -576,6 +804,9 @@ func (k *keychainStore[T]) Save(ctx context.Context, id store.ID, secret store.S
 	if err != nil {
 		return err
 	}
+	if createOnly && len(items) > 0 {
+		return store.ErrCredentialExists
+	}
 
 	// Nothing stored yet: create a fresh item.
 	if len(items) == 0 {
@@ -646,24 +877,30 @@ func (k *keychainStore[T]) loadSecret(
 	return secret, secret.Unmarshal(value)
 }
 
-//gocyclo:ignore
 func (k *keychainStore[T]) Filter(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
-	service, err := operationService(ctx)
-	if err != nil {
-		return nil, err
-	}
-	// NewService dials a fresh private session-bus connection; close it (and
-	// its socket fd) when we return. Deferred before CloseSession so that, by
-	// LIFO order, the session is closed first and the connection last.
-	defer func() { _ = service.Close() }()
+	var credentials map[store.ID]store.Secret
+	err := k.withSession(ctx, func(service secretService, session *kc.Session) (err error) {
+		credentials, err = k.filter(ctx, pattern, nil, service, session)
+		return err
+	})
+	return credentials, err
+}
 
-	session, err := service.OpenSession(kc.AuthenticationDHAES)
-	if err != nil {
-		return nil, err
-	}
-	defer service.CloseSession(session)
+// FilterByLabels returns the secrets whose metadata contains all of labels,
+// see [store.FilterByLabels]. The labels are part of the attributes searched
+// in the collection, so only matching secrets are retrieved.
+func (k *keychainStore[T]) FilterByLabels(ctx context.Context, labels map[string]string) (map[store.ID]store.Secret, error) {
+	var credentials map[store.ID]store.Secret
+	err := k.withSession(ctx, func(service secretService, session *kc.Session) (err error) {
+		credentials, err = k.filter(ctx, store.MustParsePattern("**"), labels, service, session)
+		return err
+	})
+	return credentials, err
+}
 
-	objectPath, err := getDefaultCollection(service)
+//gocyclo:ignore
+func (k *keychainStore[T]) filter(ctx context.Context, pattern store.Pattern, labels map[string]string, service secretService, session *kc.Session) (map[store.ID]store.Secret, error) {
+	objectPath, err := getDefaultCollection(service, k.createDefaultCollection)
 	if err != nil {
 		return nil, err
 	}
@@ -678,7 +915,12 @@ func (k *keychainStore[T]) Filter(ctx context.Context, pattern store.Pattern) (m
 		}
 	}
 
-	attributes := make(map[string]string)
+	// labels are stored as prefixed attributes, so they can be searched for
+	// alongside the service attributes.
+	attributes := maps.Clone(labels)
+	if attributes == nil {
+		attributes = make(map[string]string)
+	}
 	// add our pattern to the attributes so we can match against items that
 	// also contain these items
 	// only concrete types are used
@@ -717,7 +959,7 @@ func (k *keychainStore[T]) Filter(ctx context.Context, pattern store.Pattern) (m
 		}
 
 		// filter any secrets we couldn't filter through the keychain API
-		if !pattern.Match(secretID) {
+		if !pattern.Match(secretID) || !hasPrefixedLabels(attributes, labels) {
 			continue
 		}
 
@@ -734,3 +976,83 @@ func (k *keychainStore[T]) Filter(ctx context.Context, pattern store.Pattern) (m
 
 	return credentials, nil
 }
+
+func (k *keychainStore[T]) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	var credentials map[store.ID]store.Secret
+	err := k.withSession(ctx, func(service secretService, _ *kc.Session) (err error) {
+		credentials, err = k.filterMetadata(ctx, pattern, service)
+		return err
+	})
+	return credentials, err
+}
+
+func (k *keychainStore[T]) filterMetadata(ctx context.Context, pattern store.Pattern, service secretService) (map[store.ID]store.Secret, error) {
+	objectPath, err := getDefaultCollection(service, k.createDefaultCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	err = isCollectionUnlocked(objectPath, service)
+	if err != nil && !errors.Is(err, errCollectionLocked) {
+		return nil, err
+	}
+	if errors.Is(err, errCollectionLocked) {
+		if err := service.Unlock([]dbus.ObjectPath{objectPath}); err != nil {
+			return nil, err
+		}
+	}
+
+	searchMetadata := make(map[string]string)
+	safelySetMetadata(k.serviceGroup, k.serviceName, searchMetadata)
+
+	itemPaths, err := service.SearchCollection(objectPath, searchMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search collection: %w", err)
+	}
+
+	credentials := make(map[store.ID]store.Secret)
+	for _, itemPath := range itemPaths {
+		attributes, err := service.GetAttributes(itemPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// skip items that were not stored through the store, see Filter.
+		attrID, ok := attributes["id"]
+		if !ok {
+			continue
+		}
+		secretID, err := store.ParseID(attrID)
+		if err != nil {
+			continue
+		}
+		if !pattern.Match(secretID) {
+			continue
+		}
+		safelyCleanMetadata(attributes)
+
+		// unlike Filter, the secret itself is never fetched.
+		secret := k.factory(ctx, secretID)
+		if err := secret.SetMetadata(attributes); err != nil {
+			return nil, err
+		}
+		credentials[secretID] = secret
+	}
+
+	if len(credentials) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+
+	return credentials, nil
+}
+
+// hasPrefixedLabels reports whether the raw item attributes contain labels,
+// which are stored with the prefix added by [safelySetMetadata].
+func hasPrefixedLabels(attributes, labels map[string]string) bool {
+	for k, v := range labels {
+		if got, ok := attributes["x_"+k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
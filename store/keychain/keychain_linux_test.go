@@ -20,6 +20,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -49,6 +52,11 @@ type fakeService struct {
 	// loadSecret (and therefore the wrapped GetSecret) for items whose attributes
 	// carry a parseable "id"; leave nil for paths that do not inspect attributes.
 	attributes kc.Attributes
+	// itemAttributes, when set, is returned by GetAttributes per item instead
+	// of attributes.
+	itemAttributes map[dbus.ObjectPath]kc.Attributes
+	// searchAttributes records the attributes of the last SearchCollection.
+	searchAttributes kc.Attributes
 
 	// recorded write operations, for assertions in the Save tests. Not
 	// concurrency-safe: the tests that read them drive a single sequential
@@ -59,6 +67,7 @@ type fakeService struct {
 	getSecretCalls int
 	setSecretItems []dbus.ObjectPath
 	deletedItems   []dbus.ObjectPath
+	labels         []string
 
 	// {createItem,setSecret,deleteItem}LockedErrs is how many leading calls of
 	// each kind fail with the secret service "collection is locked" D-Bus error
@@ -82,6 +91,17 @@ type fakeService struct {
 	availableErr   error
 	availableCalls int
 
+	// openSessionErrs is how many leading OpenSession calls fail, simulating
+	// a secret service that is not ready yet (see WithServiceRetry).
+	openSessionErrs  int
+	openSessionCalls int
+
+	// searchStaleErrs is how many leading SearchCollection calls fail with a
+	// closed connection error, simulating a D-Bus restart.
+	searchStaleErrs   int
+	searchCalls       int
+	closeSessionCalls int
+
 	// availableCtxDeadline / availableCtxHasDeadline record the deadline of the
 	// context the eager probe passed to Available, so a test can assert New's
 	// default-timeout behavior: a bounded probe when the caller sets no deadline,
@@ -89,6 +109,13 @@ type fakeService struct {
 	availableCtxDeadline    time.Time
 	availableCtxHasDeadline bool
 
+	// emptyKeyring, when set, simulates a secret service without any
+	// collection nor 'default' alias until CreateCollection is called, which
+	// records the collection it creates in createdCollection.
+	emptyKeyring          bool
+	createdCollection     dbus.ObjectPath
+	createCollectionCalls int
+
 	opened atomic.Int64
 	closed atomic.Int64
 }
@@ -100,30 +127,65 @@ func lockedErr(op string) error {
 }
 
 func (f *fakeService) Collections() ([]dbus.ObjectPath, error) {
+	if f.emptyKeyring {
+		if f.createdCollection == "" {
+			return nil, nil
+		}
+		return []dbus.ObjectPath{f.createdCollection}, nil
+	}
 	return []dbus.ObjectPath{loginKeychainObjectPath}, nil
 }
-func (f *fakeService) ReadAlias(string) (dbus.ObjectPath, error) { return loginKeychainObjectPath, nil }
-func (f *fakeService) IsLocked(dbus.ObjectPath) (bool, error)    { return false, nil }
+func (f *fakeService) ReadAlias(string) (dbus.ObjectPath, error) {
+	if f.emptyKeyring {
+		if f.createdCollection == "" {
+			return nullObjectPath, nil
+		}
+		return f.createdCollection, nil
+	}
+	return loginKeychainObjectPath, nil
+}
+func (f *fakeService) CreateCollection(label, alias string) (dbus.ObjectPath, error) {
+	f.createCollectionCalls++
+	if alias != "default" {
+		return "", fmt.Errorf("unexpected alias %q", alias)
+	}
+	f.createdCollection = dbus.ObjectPath("/org/freedesktop/secrets/collection/" + strings.ReplaceAll(strings.ToLower(label), " ", "_"))
+	return f.createdCollection, nil
+}
+
+func (f *fakeService) IsLocked(dbus.ObjectPath) (bool, error) { return false, nil }
 func (f *fakeService) OpenSession(kc.AuthenticationMode) (*kc.Session, error) {
+	f.openSessionCalls++
+	if f.openSessionCalls <= f.openSessionErrs {
+		return nil, errors.New("secret service not ready")
+	}
 	// plain mode so Session.NewSecret works without a negotiated AES key, which
 	// lets the Save path run end-to-end against the fake.
 	return &kc.Session{Mode: kc.AuthenticationInsecurePlain}, nil
 }
-func (f *fakeService) CloseSession(*kc.Session) {}
+func (f *fakeService) CloseSession(*kc.Session) { f.closeSessionCalls++ }
 func (f *fakeService) Unlock([]dbus.ObjectPath) error {
 	f.unlockCalls++
 	return f.unlockErr
 }
 
-func (f *fakeService) SearchCollection(dbus.ObjectPath, kc.Attributes) ([]dbus.ObjectPath, error) {
+func (f *fakeService) SearchCollection(_ dbus.ObjectPath, attributes kc.Attributes) ([]dbus.ObjectPath, error) {
+	f.searchCalls++
+	f.searchAttributes = attributes
+	if f.searchCalls <= f.searchStaleErrs {
+		return nil, dbus.ErrClosed
+	}
 	return f.items, nil
 }
 
-func (f *fakeService) CreateItem(dbus.ObjectPath, map[string]dbus.Variant, kc.Secret, kc.ReplaceBehavior) (dbus.ObjectPath, error) {
+func (f *fakeService) CreateItem(_ dbus.ObjectPath, properties map[string]dbus.Variant, _ kc.Secret, _ kc.ReplaceBehavior) (dbus.ObjectPath, error) {
 	f.createCalls++
 	if f.createCalls <= f.createItemLockedErrs {
 		return "", lockedErr("create item")
 	}
+	if label, ok := properties["org.freedesktop.Secret.Item.Label"].Value().(string); ok {
+		f.labels = append(f.labels, label)
+	}
 	return "/created", nil
 }
 
@@ -136,7 +198,10 @@ func (f *fakeService) DeleteItem(item dbus.ObjectPath) error {
 	return nil
 }
 
-func (f *fakeService) GetAttributes(dbus.ObjectPath) (kc.Attributes, error) {
+func (f *fakeService) GetAttributes(item dbus.ObjectPath) (kc.Attributes, error) {
+	if f.itemAttributes != nil {
+		return f.itemAttributes[item], nil
+	}
 	return f.attributes, nil
 }
 
@@ -159,7 +224,10 @@ func (f *fakeService) SetItemSecret(item dbus.ObjectPath, _ kc.Secret) error {
 	return nil
 }
 func (f *fakeService) SetItemAttributes(dbus.ObjectPath, kc.Attributes) error { return nil }
-func (f *fakeService) SetItemLabel(dbus.ObjectPath, string) error             { return nil }
+func (f *fakeService) SetItemLabel(_ dbus.ObjectPath, label string) error {
+	f.labels = append(f.labels, label)
+	return nil
+}
 func (f *fakeService) Available(ctx context.Context) error {
 	f.availableCalls++
 	f.availableCtxDeadline, f.availableCtxHasDeadline = ctx.Deadline()
@@ -211,6 +279,42 @@ func TestKeychainGetNotFound(t *testing.T) {
 	assert.ErrorIs(t, err, store.ErrCredentialNotFound)
 }
 
+// TestKeychainCreateDefaultCollection drives a store against a secret service
+// without any collection: by default operations fail with an actionable
+// ErrNoDefaultCollection, while WithCreateDefaultCollection creates a usable
+// default collection on the first operation.
+func TestKeychainCreateDefaultCollection(t *testing.T) {
+	id := store.MustParseID("com.test.test/test/bob")
+	t.Run("disabled by default", func(t *testing.T) {
+		fake := &fakeService{emptyKeyring: true}
+		withFakeService(t, fake)
+
+		ks := setupKeychain(t, nil)
+		_, err := ks.Get(t.Context(), id)
+		require.ErrorIs(t, err, ErrNoDefaultCollection)
+		assert.ErrorContains(t, err, "WithCreateDefaultCollection")
+		assert.Zero(t, fake.createCollectionCalls)
+	})
+	t.Run("creates the default collection", func(t *testing.T) {
+		fake := &fakeService{emptyKeyring: true}
+		withFakeService(t, fake)
+
+		ks, err := New(t.Context(), "com.test.test", "test", func(_ context.Context, _ store.ID) store.Secret {
+			return &mocks.MockCredential{}
+		}, WithCreateDefaultCollection(true))
+		require.NoError(t, err)
+		require.NoError(t, ks.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "bob-password"}))
+		assert.Equal(t, 1, fake.createCollectionCalls)
+		assert.Equal(t, 1, fake.createCalls)
+		assert.Equal(t, dbus.ObjectPath("/org/freedesktop/secrets/collection/default_keyring"), fake.createdCollection)
+
+		// the created collection is found through the 'default' alias from now on
+		_, err = ks.Get(t.Context(), id)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+		assert.Equal(t, 1, fake.createCollectionCalls)
+	})
+}
+
 // TestKeychainGetAllMetadataEmpty is a regression test for `docker pass ls`
 // against an empty keychain: listing all is a valid empty result, not a miss,
 // so GetAllMetadata must return an empty (non-nil) map with a nil error rather
@@ -260,6 +364,122 @@ func TestKeychainClosesEveryConnection(t *testing.T) {
 	assert.Equal(t, opened, closed, "every opened connection must be closed")
 }
 
+// TestKeychainRetriesServiceConnection asserts transient failures to dial the
+// secret service or open a session are retried, and that the underlying error
+// is returned once the attempts are exhausted.
+func TestKeychainRetriesServiceConnection(t *testing.T) {
+	stubRelockSleep(t)
+	missing := store.MustParseID("com.test.test/test/missing")
+
+	t.Run("dial", func(t *testing.T) {
+		fake := &fakeService{}
+		withFakeService(t, fake)
+		ks := setupKeychain(t, nil)
+
+		dials := 0
+		newService = func(context.Context) (secretService, error) {
+			dials++
+			if dials == 1 {
+				return nil, errors.New("no session bus yet")
+			}
+			return fake, nil
+		}
+		_, err := ks.Get(t.Context(), missing)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+		assert.Equal(t, 2, dials)
+	})
+	t.Run("session", func(t *testing.T) {
+		fake := &fakeService{openSessionErrs: 1}
+		withFakeService(t, fake)
+		ks := setupKeychain(t, nil)
+
+		_, err := ks.Get(t.Context(), missing)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+		assert.Equal(t, 2, fake.openSessionCalls)
+	})
+	t.Run("gives up after the configured attempts", func(t *testing.T) {
+		fake := &fakeService{openSessionErrs: 10}
+		withFakeService(t, fake)
+		ks, err := New(t.Context(), "com.test.test", "test",
+			func(context.Context, store.ID) store.Secret { return &mocks.MockCredential{} },
+			WithServiceRetry(2, time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		_, err = ks.Get(t.Context(), missing)
+		assert.ErrorContains(t, err, "secret service not ready")
+		assert.Equal(t, 2, fake.openSessionCalls)
+		assert.Equal(t, fake.opened.Load(), fake.closed.Load(), "every opened connection must be closed")
+	})
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		withFakeService(t, &fakeService{})
+		newStore := func(opt Option) error {
+			_, err := New(t.Context(), "com.test.test", "test",
+				func(context.Context, store.ID) store.Secret { return &mocks.MockCredential{} }, opt)
+			return err
+		}
+		assert.Error(t, newStore(WithServiceRetry(0, time.Millisecond)))
+		assert.Error(t, newStore(WithServiceRetry(1, -time.Millisecond)))
+	})
+}
+
+// TestKeychainReusesSession asserts that WithSessionReuse opens a single
+// connection and session for all operations and releases them on Close.
+func TestKeychainReusesSession(t *testing.T) {
+	fake := &fakeService{}
+	withFakeService(t, fake)
+	ks, err := New(t.Context(), "com.test.test", "test",
+		func(context.Context, store.ID) store.Secret { return &mocks.MockCredential{} },
+		WithSessionReuse(),
+	)
+	require.NoError(t, err)
+	// discard the connection of the eager probe in New.
+	fake.opened.Store(0)
+	fake.closed.Store(0)
+
+	id := store.MustParseID("com.test.test/test/reused")
+	for range 5 {
+		require.NoError(t, ks.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pass"}))
+		_, err := ks.Get(t.Context(), id)
+		require.ErrorIs(t, err, store.ErrCredentialNotFound)
+	}
+	assert.Equal(t, int64(1), fake.opened.Load())
+	assert.Equal(t, 1, fake.openSessionCalls)
+	assert.Zero(t, fake.closed.Load(), "the shared connection must stay open until Close")
+
+	require.NoError(t, ks.Close())
+	assert.Equal(t, int64(1), fake.closed.Load())
+	assert.Equal(t, 1, fake.closeSessionCalls)
+	require.NoError(t, ks.Close(), "closing twice is a no-op")
+}
+
+// TestKeychainReusedSessionFallsBack asserts that an operation failing on a
+// stale shared connection runs again on a fresh one, and that the next
+// operation creates a new shared connection.
+func TestKeychainReusedSessionFallsBack(t *testing.T) {
+	fake := &fakeService{searchStaleErrs: 1}
+	withFakeService(t, fake)
+	ks, err := New(t.Context(), "com.test.test", "test",
+		func(context.Context, store.ID) store.Secret { return &mocks.MockCredential{} },
+		WithSessionReuse(),
+	)
+	require.NoError(t, err)
+	fake.opened.Store(0)
+	fake.closed.Store(0)
+	id := store.MustParseID("com.test.test/test/stale")
+
+	_, err = ks.Get(t.Context(), id)
+	require.ErrorIs(t, err, store.ErrCredentialNotFound)
+	assert.Equal(t, int64(2), fake.opened.Load(), "the stale shared connection and a fresh one")
+	assert.Equal(t, int64(2), fake.closed.Load(), "both are closed once the operation is done")
+
+	_, err = ks.Get(t.Context(), id)
+	require.ErrorIs(t, err, store.ErrCredentialNotFound)
+	assert.Equal(t, int64(3), fake.opened.Load(), "a new shared connection is created")
+	require.NoError(t, ks.Close())
+	assert.Equal(t, fake.opened.Load(), fake.closed.Load())
+}
+
 // TestKeychainSaveCreatesWhenAbsent asserts Save mints a new item only when the
 // identity has no existing item, and performs no in-place update or cleanup.
 func TestKeychainSaveCreatesWhenAbsent(t *testing.T) {
@@ -277,6 +497,102 @@ func TestKeychainSaveCreatesWhenAbsent(t *testing.T) {
 	assert.Empty(t, fake.deletedItems, "nothing to collapse")
 }
 
+// TestKeychainSaveIfAbsent asserts a create-only save creates the item when
+// the identity has none, and leaves an existing item untouched otherwise.
+func TestKeychainSaveIfAbsent(t *testing.T) {
+	fake := &fakeService{}
+	withFakeService(t, fake)
+
+	ks := setupKeychain(t, nil)
+	id := store.MustParseID("com.test.test/test/new-user")
+	creds := &mocks.MockCredential{Username: "alice", Password: "alice-password"}
+
+	require.NoError(t, store.SaveIfAbsent(t.Context(), ks, id, creds))
+	assert.Equal(t, 1, fake.createCalls)
+
+	fake.items = []dbus.ObjectPath{"/created"}
+	err := store.SaveIfAbsent(t.Context(), ks, id, creds)
+	require.ErrorIs(t, err, store.ErrCredentialExists)
+	assert.Equal(t, 1, fake.createCalls, "must not create another item")
+	assert.Empty(t, fake.setSecretItems, "must not update the existing item")
+	assert.Empty(t, fake.deletedItems)
+}
+
+// TestListAllServices asserts that every item of the collection is listed
+// under the service attributes found on it, whatever the service of the store,
+// and that foreign items are skipped.
+func TestListAllServices(t *testing.T) {
+	fake := &fakeService{
+		items: []dbus.ObjectPath{"/a", "/b", "/c", "/foreign", "/no-id"},
+		itemAttributes: map[dbus.ObjectPath]kc.Attributes{
+			"/a":       {serviceGroupKey: "com.test.test", serviceNameKey: "test", secretIDKey: "b/2"},
+			"/b":       {serviceGroupKey: "com.test.test", serviceNameKey: "test", secretIDKey: "a/1"},
+			"/c":       {serviceGroupKey: "com.test.other", serviceNameKey: "other", secretIDKey: "c"},
+			"/foreign": {"application": "chromium"},
+			"/no-id":   {serviceGroupKey: "com.test.test", serviceNameKey: "test"},
+		},
+	}
+	withFakeService(t, fake)
+	ks := setupKeychain(t, nil)
+
+	services, err := ListAllServices(t.Context(), ks)
+	require.NoError(t, err)
+	assert.Equal(t, map[ServiceKey][]store.ID{
+		{Group: "com.test.test", Name: "test"}:   {store.MustParseID("a/1"), store.MustParseID("b/2")},
+		{Group: "com.test.other", Name: "other"}: {store.MustParseID("c")},
+	}, services)
+	assert.Empty(t, fake.searchAttributes, "the search must not be scoped to the service of the store")
+	assert.Zero(t, fake.getSecretCalls, "listing must not read secrets")
+}
+
+// TestKeychainFilterByLabels asserts that the labels are searched as prefixed
+// attributes, and that only the secrets carrying all of them are read.
+func TestKeychainFilterByLabels(t *testing.T) {
+	fake := &fakeService{
+		items: []dbus.ObjectPath{"/a", "/b", "/c"},
+		itemAttributes: map[dbus.ObjectPath]kc.Attributes{
+			"/a": {serviceGroupKey: "com.test.test", serviceNameKey: "test", secretIDKey: "payments/db", "x_team": "payments", "x_env": "prod"},
+			"/b": {serviceGroupKey: "com.test.test", serviceNameKey: "test", secretIDKey: "payments/api", "x_team": "payments", "x_env": "dev"},
+			"/c": {serviceGroupKey: "com.test.test", serviceNameKey: "test", secretIDKey: "search/db", "x_team": "search", "x_env": "prod"},
+		},
+	}
+	withFakeService(t, fake)
+	ks := setupKeychain(t, nil)
+
+	secrets, err := store.FilterByLabels(t.Context(), ks, map[string]string{"team": "payments", "env": "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, []store.ID{store.MustParseID("payments/db")}, slices.Collect(maps.Keys(secrets)))
+	assert.Equal(t, map[string]string{"team": "payments", "env": "prod"}, secrets[store.MustParseID("payments/db")].Metadata())
+	assert.Equal(t, "payments", fake.searchAttributes["x_team"])
+	assert.Equal(t, "prod", fake.searchAttributes["x_env"])
+	assert.Equal(t, "com.test.test", fake.searchAttributes[serviceGroupKey])
+	assert.Equal(t, 1, fake.getSecretCalls)
+
+	_, err = store.FilterByLabels(t.Context(), ks, map[string]string{"team": "unknown"})
+	assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+}
+
+// TestKeychainSaveUsesItemLabelFunc asserts a custom label is used both when
+// creating an item and when updating one in place.
+func TestKeychainSaveUsesItemLabelFunc(t *testing.T) {
+	fake := &fakeService{}
+	withFakeService(t, fake)
+
+	ks, err := New(t.Context(), "com.test.test", "test",
+		func(context.Context, store.ID) store.Secret { return &mocks.MockCredential{} },
+		WithItemLabelFunc(func(id store.ID) string { return "Docker — " + id.String() }),
+	)
+	require.NoError(t, err)
+	id := store.MustParseID("db/password")
+	creds := &mocks.MockCredential{Username: "alice", Password: "alice-password"}
+
+	require.NoError(t, ks.Save(t.Context(), id, creds))
+	fake.items = []dbus.ObjectPath{"/created"}
+	require.NoError(t, ks.Save(t.Context(), id, creds))
+
+	assert.Equal(t, []string{"Docker — db/password", "Docker — db/password"}, fake.labels)
+}
+
 // TestKeychainSaveCollapsesDuplicatesInPlace is the issue #446 regression test:
 // when several items already share one stable identity (the accumulated
 // duplicates), Save must update the first match in place — never minting a new
@@ -415,6 +731,26 @@ func TestKeychainFilterRetriesWhenCollectionRelocks(t *testing.T) {
 	assert.Equal(t, 2, fake.unlockCalls, "exactly one Unlock per relock retry")
 }
 
+// TestKeychainFilterMetadataNeverReadsSecrets asserts FilterMetadata matches
+// items by pattern using their attributes alone, so the secret service is
+// never asked for the secret itself.
+func TestKeychainFilterMetadataNeverReadsSecrets(t *testing.T) {
+	fake := &fakeService{
+		items:      []dbus.ObjectPath{"/org/freedesktop/secrets/collection/login/1"},
+		attributes: kc.Attributes{"id": "com.test.test/test/bob"},
+	}
+	withFakeService(t, fake)
+
+	ks := setupKeychain(t, nil)
+	creds, err := ks.FilterMetadata(t.Context(), store.MustParsePattern("com.test.test/**"))
+	require.NoError(t, err)
+	require.Len(t, creds, 1)
+	assert.Zero(t, fake.getSecretCalls)
+
+	_, err = ks.FilterMetadata(t.Context(), store.MustParsePattern("unknown/**"))
+	assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+}
+
 // The real-keychain dedup tests use their own service group/name so their items
 // are namespace-isolated from TestKeychain (which shares com.test.test/test).
 // GetAllMetadata/Filter search by {service:group, service:name}, so a leaked
@@ -458,7 +794,7 @@ func searchRealItems(serviceGroup, serviceName string, id store.ID) ([]dbus.Obje
 	}
 	defer func() { _ = svc.Close() }()
 
-	collection, err := getDefaultCollection(svc)
+	collection, err := getDefaultCollection(svc, false)
 	if err != nil {
 		return nil, err
 	}
@@ -510,7 +846,7 @@ func seedRealDuplicates(t *testing.T, serviceGroup, serviceName string, id store
 	require.NoError(t, err)
 	defer svc.CloseSession(session)
 
-	collection, err := getDefaultCollection(svc)
+	collection, err := getDefaultCollection(svc, false)
 	require.NoError(t, err)
 
 	// Talking to the daemon directly skips the unlock the store does internally,
@@ -556,7 +892,7 @@ func purgeRealItems(t *testing.T, serviceGroup, serviceName string, id store.ID)
 	require.NoError(t, err)
 	defer func() { _ = svc.Close() }()
 
-	collection, err := getDefaultCollection(svc)
+	collection, err := getDefaultCollection(svc, false)
 	require.NoError(t, err)
 	ensureUnlocked(t, svc, collection)
 
@@ -654,6 +990,18 @@ func TestNewProbeSucceeds(t *testing.T) {
 	assert.Equal(t, fake.opened.Load(), fake.closed.Load(), "the probe must close its connection")
 }
 
+// TestNewAcceptsAnyAttributeValue asserts that Linux does not restrict the
+// service group and name beyond the rules shared by all platforms, since
+// they are only stored as item attributes.
+func TestNewAcceptsAnyAttributeValue(t *testing.T) {
+	withFakeService(t, &fakeService{})
+
+	_, err := New(t.Context(), "team:com.tést_group", "my service", func(_ context.Context, _ store.ID) store.Secret {
+		return &mocks.MockCredential{}
+	})
+	require.NoError(t, err)
+}
+
 // TestNewProbeNoOwner asserts that when the session bus is reachable but no
 // process owns org.freedesktop.secrets, New fails eagerly with
 // ErrKeychainUnavailable wrapping the no-owner cause — and NOT
@@ -865,3 +1213,35 @@ func TestResolveDefaultCollection(t *testing.T) {
 		})
 	}
 }
+
+// BenchmarkKeychainSave saves 100 secrets against the live keyring, with and
+// without WithSessionReuse.
+func BenchmarkKeychainSave(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "per-operation session"},
+		{name: "reused session", opts: []Option{WithSessionReuse()}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			ks, err := New(b.Context(), dedupServiceGroup, dedupServiceName, func(_ context.Context, _ store.ID) store.Secret {
+				return &mocks.MockCredential{}
+			}, bench.opts...)
+			require.NoError(b, err)
+			b.Cleanup(func() {
+				for i := range 100 {
+					_ = ks.Delete(context.Background(), store.MustParseID(fmt.Sprintf("%s/%s/bench-%d", dedupServiceGroup, dedupServiceName, i)))
+				}
+				_ = ks.Close()
+			})
+
+			for b.Loop() {
+				for i := range 100 {
+					id := store.MustParseID(fmt.Sprintf("%s/%s/bench-%d", dedupServiceGroup, dedupServiceName, i))
+					require.NoError(b, ks.Save(b.Context(), id, &mocks.MockCredential{Username: "bench", Password: "bench-password"}))
+				}
+			}
+		})
+	}
+}
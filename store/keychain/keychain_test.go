@@ -17,6 +17,7 @@ package keychain
 import (
 	"context"
 	"errors"
+	"maps"
 	"runtime"
 	"strings"
 	"testing"
@@ -66,6 +67,16 @@ func (m *mustUnmarshalError) Unmarshal([]byte) error {
 	return errors.New("i am failing on purpose")
 }
 
+type mustValidateError struct {
+	mocks.MockCredential
+}
+
+var _ store.Validator = &mustValidateError{}
+
+func (m *mustValidateError) Validate() error {
+	return errors.New("i am failing on purpose")
+}
+
 func setupKeychain(t *testing.T, secretFactory func(context.Context, store.ID) store.Secret) store.Store {
 	t.Helper()
 	if secretFactory == nil {
@@ -118,6 +129,35 @@ func TestKeychain(t *testing.T) {
 		assert.EqualValues(t, expected, actual)
 	})
 
+	t.Run("numeric-looking metadata is kept as is", func(t *testing.T) {
+		ks := setupKeychain(t, nil)
+		id := store.MustParseID("com.test.test/test/bob")
+		metadata := map[string]string{
+			"port":    "8080",
+			"ratio":   "0.50",
+			"enabled": "true",
+			"padded":  "007",
+		}
+		creds := &mocks.MockCredential{
+			Username:   "bob",
+			Password:   "bob-password",
+			Attributes: maps.Clone(metadata),
+		}
+		t.Cleanup(func() {
+			require.NoError(t, ks.Delete(context.Background(), id))
+		})
+		require.NoError(t, ks.Save(t.Context(), id, creds))
+
+		secret, err := ks.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, metadata, secret.Metadata())
+
+		all, err := ks.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		require.Contains(t, all, id)
+		assert.Equal(t, metadata, all[id].Metadata())
+	})
+
 	t.Run("list all credentials", func(t *testing.T) {
 		ks := setupKeychain(t, nil)
 
@@ -152,6 +192,13 @@ func TestKeychain(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, secrets, 3)
 
+		withValues, err := ks.GetAll(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, withValues, 3)
+		for id, cred := range moreCreds {
+			assert.Equal(t, cred.Password, withValues[id].(*mocks.MockCredential).Password)
+		}
+
 		actual := make(map[store.ID]*mocks.MockCredential)
 		for k, v := range secrets {
 			actual[k] = v.(*mocks.MockCredential)
@@ -246,6 +293,19 @@ func TestKeychain(t *testing.T) {
 			assert.EqualValues(t, expected, actual)
 		})
 
+		t.Run("can filter metadata only", func(t *testing.T) {
+			result, err := ks.FilterMetadata(t.Context(), store.MustParsePattern("**/bob"))
+			require.NoError(t, err)
+			assert.Len(t, result, 2)
+			bob := store.MustParseID("com.test.test/test/bob")
+			assert.Equal(t, &mocks.MockCredential{Attributes: moreCreds[bob].Attributes}, result[bob])
+		})
+
+		t.Run("filter metadata without matches", func(t *testing.T) {
+			_, err := ks.FilterMetadata(t.Context(), store.MustParsePattern("**/unknown"))
+			assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+		})
+
 		t.Run("exact id match should still return exactly one secret", func(t *testing.T) {
 			actual, err := ks.Filter(t.Context(), store.MustParsePattern("com.test.test/test/pete"))
 			require.NoError(t, err)
@@ -352,6 +412,15 @@ func TestKeychain(t *testing.T) {
 		require.ErrorContains(t, kc.Save(t.Context(), id, &mustMarshalError{}), "i am failing on purpose")
 	})
 
+	t.Run("invalid secret is not saved", func(t *testing.T) {
+		kc := setupKeychain(t, nil)
+		id := store.MustParseID("com.test.test/test/invalid")
+		require.ErrorContains(t, kc.Save(t.Context(), id, &mustValidateError{}), "i am failing on purpose")
+		require.ErrorContains(t, kc.Upsert(t.Context(), id, &mustValidateError{}), "i am failing on purpose")
+		_, err := kc.Get(t.Context(), id)
+		require.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+
 	t.Run("unmarshal error on get", func(t *testing.T) {
 		kc := setupKeychain(t, func(_ context.Context, _ store.ID) store.Secret {
 			return &mustUnmarshalError{}
@@ -427,6 +496,35 @@ func TestKeychain(t *testing.T) {
 	})
 }
 
+func TestNewValidatesService(t *testing.T) {
+	factory := func(_ context.Context, _ store.ID) *mocks.MockCredential {
+		return &mocks.MockCredential{}
+	}
+	tests := []struct {
+		name         string
+		serviceGroup string
+		serviceName  string
+		wantErr      string
+	}{
+		{"empty group", "", "test", "serviceGroup is required"},
+		{"empty name", "com.test.test", "", "serviceName is required"},
+		{"invalid UTF-8", "com.test.test", "te\xffst", "must be valid UTF-8"},
+		{"surrounding whitespace", " com.test.test", "test", "must not start or end with whitespace"},
+		{"control character", "com.test.test", "te\nst", "must not contain control characters"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(t.Context(), tc.serviceGroup, tc.serviceName, factory)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestListAllServicesNotSupported(t *testing.T) {
+	_, err := ListAllServices(t.Context(), &mocks.MockStore{})
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
 func TestSafelySetID(t *testing.T) {
 	t.Run("can set id in attributes", func(t *testing.T) {
 		attributes := map[string]string{
@@ -569,3 +667,61 @@ func TestSafelyCleanMetadata(t *testing.T) {
 		}, attributes)
 	})
 }
+
+func TestItemLabelFunc(t *testing.T) {
+	id := store.MustParseID("db/password")
+
+	t.Run("defaults to the item label", func(t *testing.T) {
+		k := &keychainStore[store.Secret]{serviceGroup: "group", serviceName: "name"}
+		label, err := k.displayLabel(id)
+		require.NoError(t, err)
+		assert.Equal(t, "group:name:db/password", label)
+	})
+	t.Run("uses the custom label", func(t *testing.T) {
+		k := &keychainStore[store.Secret]{serviceGroup: "group", serviceName: "name"}
+		require.NoError(t, WithItemLabelFunc(func(id store.ID) string {
+			return "Docker — " + id.String()
+		}).apply(k))
+		label, err := k.displayLabel(id)
+		require.NoError(t, err)
+		assert.Equal(t, "Docker — db/password", label)
+		assert.Equal(t, "group:name:db/password", k.itemLabel(id.String()), "lookup label must not change")
+	})
+	t.Run("rejects empty labels", func(t *testing.T) {
+		k := &keychainStore[store.Secret]{serviceGroup: "group", serviceName: "name"}
+		require.NoError(t, WithItemLabelFunc(func(store.ID) string { return " " }).apply(k))
+		_, err := k.displayLabel(id)
+		assert.ErrorContains(t, err, "cannot be empty")
+	})
+	t.Run("rejects a nil func", func(t *testing.T) {
+		k := &keychainStore[store.Secret]{}
+		assert.Error(t, WithItemLabelFunc(nil).apply(k))
+	})
+}
+
+type fakeDarwinOptions struct {
+	accessibility Accessibility
+}
+
+func (f *fakeDarwinOptions) setUseDataProtectionKeychain(bool) {}
+
+func (f *fakeDarwinOptions) setAccessibility(a Accessibility) {
+	f.accessibility = a
+}
+
+func TestWithAccessibility(t *testing.T) {
+	opts := &fakeDarwinOptions{}
+	require.NoError(t, WithAccessibility(AccessibleWhenUnlocked)(opts))
+	assert.Equal(t, AccessibleWhenUnlocked, opts.accessibility)
+
+	assert.ErrorContains(t, WithAccessibility(Accessibility(42))(opts), "invalid keychain accessibility")
+	assert.ErrorContains(t, WithAccessibility(Accessibility(-1))(opts), "invalid keychain accessibility")
+
+	t.Run("ignored on other platforms", func(t *testing.T) {
+		if runtime.GOOS == "darwin" {
+			t.Skip("only applies to non-darwin platforms")
+		}
+		opt := WithDarwinOptions(WithAccessibility(Accessibility(42)))
+		assert.ErrorIs(t, opt.apply(&struct{}{}), errSkipOptions)
+	})
+}
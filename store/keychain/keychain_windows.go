@@ -22,6 +22,7 @@ import (
 	"maps"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/danieljoos/wincred"
 	"golang.org/x/sys/windows"
@@ -109,6 +110,10 @@ type keychainStore[T store.Secret] struct {
 	serviceGroup string
 	serviceName  string
 	factory      store.Factory[T]
+	labelFunc    func(id store.ID) string
+
+	// mu serializes [keychainStore.SaveIfAbsent] calls.
+	mu sync.Mutex
 }
 
 // ensureAvailable is the Windows no-op of the per-platform availability hook New
@@ -118,6 +123,27 @@ type keychainStore[T store.Secret] struct {
 // unused on Windows.
 func ensureAvailable(_ context.Context) error { return nil }
 
+// validatePlatformService rejects a serviceGroup or serviceName containing
+// ':', which separates them from the secret ID in the credential target name.
+// The target name is parsed back when listing secrets, so it must not be
+// ambiguous.
+func validatePlatformService(serviceGroup, serviceName string) error {
+	if strings.Contains(serviceGroup, ":") {
+		return fmt.Errorf("invalid serviceGroup %q: must not contain ':'", serviceGroup)
+	}
+	if strings.Contains(serviceName, ":") {
+		return fmt.Errorf("invalid serviceName %q: must not contain ':'", serviceName)
+	}
+	return nil
+}
+
+// Close implements [store.Store].
+//
+// The keychain is accessed per operation, so there is nothing to release.
+func (k *keychainStore[T]) Close() error {
+	return nil
+}
+
 // itemChunkLabel returns the target name for the i-th chunk of a secret.
 func (k *keychainStore[T]) itemChunkLabel(id store.ID, index int) string {
 	return fmt.Sprintf("%s:chunk:%d", k.itemLabel(id.String()), index)
@@ -297,7 +323,43 @@ func (k *keychainStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]sto
 	return secrets, nil
 }
 
+// listAllServices implements [allServicesLister]. Windows lists the
+// credentials of all applications, only those carrying the service
+// attributes are kept.
+func (k *keychainStore[T]) listAllServices(context.Context) (map[ServiceKey][]store.ID, error) {
+	credentials, err := wincred.List()
+	if err != nil {
+		return nil, mapWindowsCredentialError(err)
+	}
+
+	services := map[ServiceKey][]store.ID{}
+	for _, cred := range credentials {
+		if isChunkCredential(cred.Attributes) {
+			continue
+		}
+		attributes := mapFromWindowsAttributes(cred.Attributes)
+		key := ServiceKey{Group: attributes[serviceGroupKey], Name: attributes[serviceNameKey]}
+		if key.Group == "" || key.Name == "" {
+			continue
+		}
+		id, err := store.ParseID(cred.UserName)
+		if err != nil {
+			continue
+		}
+		services[key] = append(services[key], id)
+	}
+	return services, nil
+}
+
 func (k *keychainStore[T]) Save(_ context.Context, id store.ID, secret store.Secret) error {
+	if err := store.Validate(secret); err != nil {
+		return err
+	}
+	label, err := k.displayLabel(id)
+	if err != nil {
+		return err
+	}
+
 	blob, err := encodeSecret(secret)
 	if err != nil {
 		return err
@@ -319,6 +381,11 @@ func (k *keychainStore[T]) Save(_ context.Context, id store.ID, secret store.Sec
 	g := wincred.NewGenericCredential(k.itemLabel(id.String()))
 	g.UserName = id.String()
 	g.Persist = wincred.PersistLocalMachine
+	// the target name is how secrets are looked up and listed, so a custom
+	// label can only be shown as the comment.
+	if label != g.TargetName {
+		g.Comment = label
+	}
 
 	// the blob is too large, we will chunk it across multiple entries
 	if len(blob) > maxBlobSize {
@@ -351,6 +418,24 @@ func (k *keychainStore[T]) Upsert(ctx context.Context, id store.ID, secret store
 	return k.Save(ctx, id, secret)
 }
 
+// SaveIfAbsent implements [store.AbsentSaver]. The Credential Manager has no
+// create-only write, so the credential is looked up right before writing it.
+// mu keeps concurrent calls on this store from interleaving, but another
+// process can still write the credential in between.
+func (k *keychainStore[T]) SaveIfAbsent(ctx context.Context, id store.ID, secret store.Secret) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	_, err := wincred.GetGenericCredential(k.itemLabel(id.String()))
+	if err == nil {
+		return store.ErrCredentialExists
+	}
+	if err := mapWindowsCredentialError(err); !errors.Is(err, store.ErrCredentialNotFound) {
+		return err
+	}
+	return k.Save(ctx, id, secret)
+}
+
 func (k *keychainStore[T]) Filter(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
 	// Note: there is no notion of a filter on Windows inside the wincred API.
 	// It has no way to even filter on known attributes.
@@ -395,6 +480,39 @@ func (k *keychainStore[T]) Filter(ctx context.Context, pattern store.Pattern) (m
 	return secrets, nil
 }
 
+func (k *keychainStore[T]) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	credentials, err := wincred.List()
+	if err != nil {
+		return nil, mapWindowsCredentialError(err)
+	}
+
+	onlyLabelPrefix := k.itemLabel("")
+
+	secrets := make(map[store.ID]store.Secret)
+	for cred := range findServiceCredentials(k, pattern, credentials) {
+		// skip credentials that were not stored through the store.
+		id, err := store.ParseID(strings.ReplaceAll(cred.TargetName, onlyLabelPrefix, ""))
+		if err != nil {
+			continue
+		}
+
+		attributes := mapFromWindowsAttributes(cred.Attributes)
+		safelyCleanMetadata(attributes)
+
+		secret := k.factory(ctx, id)
+		if err := secret.SetMetadata(attributes); err != nil {
+			return nil, err
+		}
+		secrets[id] = secret
+	}
+
+	if len(secrets) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+
+	return secrets, nil
+}
+
 // loadSecret fetches, decodes, and zeroes the raw blob for id, then
 // returns a fully populated Secret. rawBlob is zeroed only when it was
 // allocated by readChunks (chunked path); gc.CredentialBlob is not ours.
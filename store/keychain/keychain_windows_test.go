@@ -17,6 +17,7 @@
 package keychain
 
 import (
+	"context"
 	"slices"
 	"strings"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/docker/secrets-engine/store"
 	"github.com/docker/secrets-engine/store/mocks"
 )
 
@@ -217,3 +219,15 @@ func TestMapWindowsAttributes(t *testing.T) {
 		assert.Empty(t, mapFromWindowsAttributes(wa))
 	})
 }
+
+func TestNewRejectsTargetNameSeparator(t *testing.T) {
+	factory := func(_ context.Context, _ store.ID) *mocks.MockCredential {
+		return &mocks.MockCredential{}
+	}
+	_, err := New(t.Context(), "com.test:test", "test", factory)
+	assert.ErrorContains(t, err, `invalid serviceGroup "com.test:test"`)
+	_, err = New(t.Context(), "com.test.test", "te:st", factory)
+	assert.ErrorContains(t, err, `invalid serviceName "te:st"`)
+	_, err = New(t.Context(), "com.test.test", "test", factory)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keychain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/docker/secrets-engine/store"
+)
+
+// ErrNotSupported is returned by [ListAllServices] on platforms whose
+// keychain cannot enumerate the items of other services.
+var ErrNotSupported = errors.New("not supported by this keychain backend")
+
+// ServiceKey identifies the service that saved a keychain item, see the
+// serviceGroup and serviceName of [New].
+type ServiceKey struct {
+	Group string
+	Name  string
+}
+
+// allServicesLister is implemented by the keychain stores of the platforms
+// supporting [ListAllServices].
+type allServicesLister interface {
+	listAllServices(ctx context.Context) (map[ServiceKey][]store.ID, error)
+}
+
+// ListAllServices returns the IDs of every secret the keychain backing s can
+// reach, grouped by the service group and name found on each item, ignoring
+// the service s was created for. The IDs of each service are sorted.
+//
+// It is a diagnostic capability, e.g. to find secrets saved under a
+// mistyped service name, and not a way to retrieve secrets: no secret value
+// is read. Items not saved by a keychain store are skipped.
+//
+// s must be a store created with [New]. [ErrNotSupported] is returned on
+// macOS, where keychain queries are restricted to the access groups the
+// application is entitled to.
+func ListAllServices(ctx context.Context, s store.Store) (map[ServiceKey][]store.ID, error) {
+	l, ok := s.(allServicesLister)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	services, err := l.listAllServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ids := range services {
+		slices.SortFunc(ids, func(a, b store.ID) int {
+			return strings.Compare(a.String(), b.String())
+		})
+	}
+	return services, nil
+}
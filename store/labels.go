@@ -0,0 +1,74 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// LabelFilterer can optionally be implemented by a [Store] that can look up
+// secrets by their metadata natively, see [FilterByLabels].
+type LabelFilterer interface {
+	FilterByLabels(ctx context.Context, labels map[string]string) (map[ID]Secret, error)
+}
+
+// FilterByLabels returns the secrets whose metadata contains all of labels,
+// with both [Secret.SetMetadata] and [Secret.Unmarshal] called like
+// [Store.Filter]. [ErrCredentialNotFound] is returned when no secret matches.
+//
+// Stores implementing [LabelFilterer] match the labels natively. For other
+// stores, the labels are matched against [Store.GetAllMetadata] and only the
+// matching secrets are retrieved with [Store.Get].
+func FilterByLabels(ctx context.Context, s Store, labels map[string]string) (map[ID]Secret, error) {
+	if l, ok := s.(LabelFilterer); ok {
+		return l.FilterByLabels(ctx, labels)
+	}
+
+	all, err := s.GetAllMetadata(ctx)
+	if err != nil && !errors.Is(err, ErrCredentialNotFound) {
+		return nil, err
+	}
+	secrets := make(map[ID]Secret)
+	for id, secret := range all {
+		if !HasLabels(secret.Metadata(), labels) {
+			continue
+		}
+		secret, err := s.Get(ctx, id)
+		if errors.Is(err, ErrCredentialNotFound) {
+			// deleted since it was listed
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		secrets[id] = secret
+	}
+	if len(secrets) == 0 {
+		return nil, ErrCredentialNotFound
+	}
+	return secrets, nil
+}
+
+// HasLabels reports whether metadata contains all of labels with the same
+// values. Any metadata matches empty labels.
+func HasLabels(metadata, labels map[string]string) bool {
+	for k, v := range labels {
+		if got, ok := metadata[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type labeledSecret struct {
+	testSecret
+	metadata map[string]string
+}
+
+func (l *labeledSecret) Metadata() map[string]string { return l.metadata }
+
+// memoryStore is a minimal in-memory [Store] supporting [Store.Get] and
+// [Store.GetAllMetadata].
+type memoryStore struct {
+	Store
+	secrets map[string]*labeledSecret
+	gets    []string
+}
+
+func (m *memoryStore) Get(_ context.Context, id ID) (Secret, error) {
+	m.gets = append(m.gets, id.String())
+	s, ok := m.secrets[id.String()]
+	if !ok {
+		return nil, ErrCredentialNotFound
+	}
+	return s, nil
+}
+
+func (m *memoryStore) GetAllMetadata(context.Context) (map[ID]Secret, error) {
+	secrets := map[ID]Secret{}
+	for k, s := range m.secrets {
+		secrets[MustParseID(k)] = &labeledSecret{metadata: s.metadata}
+	}
+	return secrets, nil
+}
+
+func TestFilterByLabels(t *testing.T) {
+	s := &memoryStore{secrets: map[string]*labeledSecret{
+		"payments/db":  {testSecret{value: "a"}, map[string]string{"team": "payments", "env": "prod"}},
+		"payments/api": {testSecret{value: "b"}, map[string]string{"team": "payments", "env": "dev"}},
+		"search/db":    {testSecret{value: "c"}, map[string]string{"team": "search", "env": "prod"}},
+		"unlabeled":    {testSecret{value: "d"}, nil},
+	}}
+	ids := func(secrets map[ID]Secret) []string {
+		var ids []string
+		for id := range secrets {
+			ids = append(ids, id.String())
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	t.Run("matches a single label", func(t *testing.T) {
+		s.gets = nil
+		secrets, err := FilterByLabels(t.Context(), s, map[string]string{"team": "payments"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"payments/api", "payments/db"}, ids(secrets))
+		assert.Equal(t, "a", secrets[MustParseID("payments/db")].(*labeledSecret).value)
+		slices.Sort(s.gets)
+		assert.Equal(t, []string{"payments/api", "payments/db"}, s.gets, "only matching secrets are retrieved")
+	})
+	t.Run("overlapping labels must all match", func(t *testing.T) {
+		secrets, err := FilterByLabels(t.Context(), s, map[string]string{"team": "payments", "env": "prod"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"payments/db"}, ids(secrets))
+
+		secrets, err = FilterByLabels(t.Context(), s, map[string]string{"env": "prod"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"payments/db", "search/db"}, ids(secrets))
+	})
+	t.Run("empty labels match everything", func(t *testing.T) {
+		secrets, err := FilterByLabels(t.Context(), s, nil)
+		require.NoError(t, err)
+		assert.Equal(t, slices.Sorted(maps.Keys(s.secrets)), ids(secrets))
+	})
+	t.Run("no match", func(t *testing.T) {
+		_, err := FilterByLabels(t.Context(), s, map[string]string{"team": "unknown"})
+		assert.ErrorIs(t, err, ErrCredentialNotFound)
+	})
+	t.Run("native implementations are used", func(t *testing.T) {
+		_, err := FilterByLabels(t.Context(), labelFilterer{}, map[string]string{"team": "payments"})
+		assert.ErrorIs(t, err, errNative)
+	})
+}
+
+var errNative = errors.New("native")
+
+type labelFilterer struct {
+	Store
+}
+
+func (labelFilterer) FilterByLabels(context.Context, map[string]string) (map[ID]Secret, error) {
+	return nil, errNative
+}
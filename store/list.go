@@ -0,0 +1,99 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ListOptions controls the page returned by [List].
+type ListOptions struct {
+	// Limit is the maximum number of secrets returned. A value less than or
+	// equal to zero returns all the remaining secrets.
+	Limit int
+	// Cursor continues the listing after the previous page, see
+	// [ListResult.Cursor]. An empty cursor starts from the beginning.
+	Cursor string
+}
+
+// ListItem is a secret returned by [List], with only its metadata set.
+type ListItem struct {
+	ID     ID
+	Secret Secret
+}
+
+// ListResult is a page of secrets returned by [List].
+type ListResult struct {
+	// Items are in a stable order specific to the store.
+	Items []ListItem
+	// Cursor is passed in [ListOptions.Cursor] to get the next page. It is
+	// empty when there are no more secrets. Cursors are opaque and only valid
+	// for the store that returned them.
+	Cursor string
+}
+
+// Lister can optionally be implemented by a [Store] to page through its
+// secrets without enumerating all of them, see [List].
+type Lister interface {
+	List(ctx context.Context, pattern Pattern, opts ListOptions) (ListResult, error)
+}
+
+// List returns a page of the secrets matching pattern, with only their
+// metadata set like [Store.FilterMetadata]. Unlike FilterMetadata, no
+// matching secret is an empty result rather than [ErrCredentialNotFound].
+//
+// Stores implementing [Lister] page natively. Other stores are paged over
+// the full result of [Store.FilterMetadata], ordered by ID, using the last
+// returned ID as cursor.
+func List(ctx context.Context, s Store, pattern Pattern, opts ListOptions) (ListResult, error) {
+	if l, ok := s.(Lister); ok {
+		return l.List(ctx, pattern, opts)
+	}
+
+	secrets, err := s.FilterMetadata(ctx, pattern)
+	if errors.Is(err, ErrCredentialNotFound) {
+		return ListResult{}, nil
+	}
+	if err != nil {
+		return ListResult{}, err
+	}
+	items := make([]ListItem, 0, len(secrets))
+	for id, secret := range secrets {
+		if opts.Cursor != "" && id.String() <= opts.Cursor {
+			continue
+		}
+		items = append(items, ListItem{ID: id, Secret: secret})
+	}
+	slices.SortFunc(items, func(a, b ListItem) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return Page(items, opts.Limit, func(item ListItem) string {
+		return item.ID.String()
+	}), nil
+}
+
+// Page returns at most limit items, and the cursor of the last one if there
+// are more. It helps [Lister] implementations build a [ListResult] from the
+// sorted items after the cursor.
+func Page(items []ListItem, limit int, cursor func(ListItem) string) ListResult {
+	if limit <= 0 || len(items) <= limit {
+		return ListResult{Items: items}
+	}
+	items = items[:limit]
+	return ListResult{Items: items, Cursor: cursor(items[len(items)-1])}
+}
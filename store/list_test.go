@@ -0,0 +1,111 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// filterStore is a minimal in-memory [Store] that only supports
+// [Store.FilterMetadata].
+type filterStore struct {
+	Store
+	secrets map[string]Secret
+}
+
+func (f *filterStore) FilterMetadata(_ context.Context, pattern Pattern) (map[ID]Secret, error) {
+	secrets := map[ID]Secret{}
+	for k, s := range f.secrets {
+		if id := MustParseID(k); pattern.Match(id) {
+			secrets[id] = s
+		}
+	}
+	if len(secrets) == 0 {
+		return nil, ErrCredentialNotFound
+	}
+	return secrets, nil
+}
+
+func listIDs(result ListResult) []string {
+	var ids []string
+	for _, item := range result.Items {
+		ids = append(ids, item.ID.String())
+	}
+	return ids
+}
+
+func TestList(t *testing.T) {
+	s := &filterStore{secrets: map[string]Secret{
+		"app/c":   &testSecret{},
+		"app/a":   &testSecret{},
+		"app/b":   &testSecret{},
+		"other/a": &testSecret{},
+	}}
+	pattern := MustParsePattern("app/*")
+
+	t.Run("without limit returns everything in order", func(t *testing.T) {
+		result, err := List(t.Context(), s, pattern, ListOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app/a", "app/b", "app/c"}, listIDs(result))
+		assert.Empty(t, result.Cursor)
+	})
+	t.Run("cursor continues after the previous page", func(t *testing.T) {
+		first, err := List(t.Context(), s, pattern, ListOptions{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app/a", "app/b"}, listIDs(first))
+		require.NotEmpty(t, first.Cursor)
+
+		next, err := List(t.Context(), s, pattern, ListOptions{Limit: 2, Cursor: first.Cursor})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app/c"}, listIDs(next))
+		assert.Empty(t, next.Cursor)
+	})
+	t.Run("cursor is stable across inserts before it", func(t *testing.T) {
+		s := &filterStore{secrets: map[string]Secret{
+			"app/b": &testSecret{},
+			"app/d": &testSecret{},
+		}}
+		first, err := List(t.Context(), s, pattern, ListOptions{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app/b"}, listIDs(first))
+
+		s.secrets["app/a"] = &testSecret{}
+		next, err := List(t.Context(), s, pattern, ListOptions{Limit: 1, Cursor: first.Cursor})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app/d"}, listIDs(next))
+	})
+	t.Run("no match is an empty result", func(t *testing.T) {
+		result, err := List(t.Context(), s, MustParsePattern("missing/*"), ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, result.Items)
+		assert.Empty(t, result.Cursor)
+	})
+	t.Run("errors are returned", func(t *testing.T) {
+		_, err := List(t.Context(), &errFilterStore{}, pattern, ListOptions{})
+		assert.ErrorIs(t, err, errWrite)
+	})
+}
+
+type errFilterStore struct {
+	Store
+}
+
+func (errFilterStore) FilterMetadata(context.Context, Pattern) (map[ID]Secret, error) {
+	return nil, errWrite
+}
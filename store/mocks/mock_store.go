@@ -23,6 +23,11 @@ import (
 )
 
 type MockStore struct {
+	// Factory creates the secrets returned by FilterMetadata, which only
+	// carry metadata like with the real backends. It defaults to
+	// [MockCredential].
+	Factory store.Factory[store.Secret]
+
 	lock  sync.RWMutex
 	store map[store.ID]store.Secret
 }
@@ -54,6 +59,10 @@ func (m *MockStore) Get(_ context.Context, id store.ID) (store.Secret, error) {
 	return secret, nil
 }
 
+func (m *MockStore) GetAll(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return m.Filter(ctx, store.MustParsePattern("**"))
+}
+
 func (m *MockStore) GetAllMetadata(_ context.Context) (map[store.ID]store.Secret, error) {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
@@ -88,4 +97,35 @@ func (m *MockStore) Filter(_ context.Context, pattern store.Pattern) (map[store.
 	return filtered, nil
 }
 
+func (m *MockStore) Close() error {
+	return nil
+}
+
+func (m *MockStore) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	factory := m.Factory
+	if factory == nil {
+		factory = func(context.Context, store.ID) store.Secret {
+			return &MockCredential{}
+		}
+	}
+	filtered := make(map[store.ID]store.Secret)
+	for id, secret := range m.store {
+		if !pattern.Match(id) {
+			continue
+		}
+		metadata := factory(ctx, id)
+		if err := metadata.SetMetadata(maps.Clone(secret.Metadata())); err != nil {
+			return nil, err
+		}
+		filtered[id] = metadata
+	}
+	if len(filtered) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+	return filtered, nil
+}
+
 var _ store.Store = &MockStore{}
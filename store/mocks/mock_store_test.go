@@ -0,0 +1,58 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/store"
+)
+
+func TestMockStoreFilterMetadata(t *testing.T) {
+	m := &MockStore{}
+	require.NoError(t, m.Save(t.Context(), store.MustParseID("db/password"), &MockCredential{
+		Username:   "user",
+		Password:   "pw",
+		Attributes: map[string]string{"env": "prod"},
+	}))
+	require.NoError(t, m.Save(t.Context(), store.MustParseID("api/token"), &MockCredential{Password: "token"}))
+
+	t.Run("only metadata is returned", func(t *testing.T) {
+		secrets, err := m.FilterMetadata(t.Context(), store.MustParsePattern("db/**"))
+		require.NoError(t, err)
+		assert.Equal(t, map[store.ID]store.Secret{
+			store.MustParseID("db/password"): &MockCredential{Attributes: map[string]string{"env": "prod"}},
+		}, secrets)
+	})
+	t.Run("secrets are built by the factory", func(t *testing.T) {
+		var ids []string
+		m := &MockStore{Factory: func(_ context.Context, id store.ID) store.Secret {
+			ids = append(ids, id.String())
+			return &MockCredential{}
+		}}
+		require.NoError(t, m.Save(t.Context(), store.MustParseID("api/token"), &MockCredential{Password: "token"}))
+		_, err := m.FilterMetadata(t.Context(), store.MustParsePattern("**"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api/token"}, ids)
+	})
+	t.Run("no match", func(t *testing.T) {
+		_, err := m.FilterMetadata(t.Context(), store.MustParsePattern("unknown/**"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package posixage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"sync"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/posixage/internal/secretfile"
+)
+
+// batchDirPrefix prefixes the directories used to stage a batch under the
+// store root. They are ignored when listing secrets, which keeps a batch
+// interrupted by a crash from showing up.
+const batchDirPrefix = ".batch-"
+
+var _ store.Batcher = &fileStore[store.Secret]{}
+
+// BeginBatch starts a [store.Batch] that applies all its writes at once.
+//
+// Secrets are encrypted when they get staged, so encryption callbacks are
+// invoked by [store.Batch.Save] and not by [store.Batch.Commit].
+// On commit, the secrets are written to a staging directory under the store
+// root and then renamed into place while the store is locked. Secrets that
+// get overwritten or deleted are moved aside first, so that they can be
+// restored if any of the renames fails.
+func (f *fileStore[T]) BeginBatch() (store.Batch, error) {
+	return &fileBatch[T]{store: f}, nil
+}
+
+type fileBatchOp struct {
+	id       store.ID
+	metadata map[string]string
+	// secrets is nil for deletions
+	secrets []secretfile.EncryptedSecret
+}
+
+type fileBatch[T store.Secret] struct {
+	store  *fileStore[T]
+	mu     sync.Mutex
+	ops    []fileBatchOp
+	closed bool
+}
+
+func (b *fileBatch[T]) stage(op fileBatchOp) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return store.ErrBatchClosed
+	}
+	if i := slices.IndexFunc(b.ops, func(o fileBatchOp) bool { return o.id.String() == op.id.String() }); i != -1 {
+		b.ops[i] = op
+		return nil
+	}
+	b.ops = append(b.ops, op)
+	return nil
+}
+
+func (b *fileBatch[T]) Save(ctx context.Context, id store.ID, s store.Secret) error {
+	metadata, secrets, err := b.store.encryptSecret(ctx, s)
+	if err != nil {
+		return err
+	}
+	return b.stage(fileBatchOp{id: id, metadata: metadata, secrets: secrets})
+}
+
+func (b *fileBatch[T]) Delete(_ context.Context, id store.ID) error {
+	return b.stage(fileBatchOp{id: id})
+}
+
+func (b *fileBatch[T]) Discard() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return store.ErrBatchClosed
+	}
+	b.closed = true
+	b.ops = nil
+	return nil
+}
+
+func (b *fileBatch[T]) Commit(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return store.ErrBatchClosed
+	}
+	b.closed = true
+
+	unlock, err := b.store.tryLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	root := b.store.filesystem
+	stagingDir := batchDirPrefix + rand.Text()
+	newDir := path.Join(stagingDir, "new")
+	oldDir := path.Join(stagingDir, "old")
+	if err := root.MkdirAll(newDir, 0o700); err != nil {
+		return err
+	}
+	// also removes the previous secrets once the batch has been applied
+	defer func() {
+		if err := root.RemoveAll(stagingDir); err != nil {
+			b.store.logger.Errorf("could not remove batch staging directory %s: %s", stagingDir, err)
+		}
+	}()
+	if err := root.Mkdir(oldDir, 0o700); err != nil {
+		return err
+	}
+
+	if err := b.persistStaged(newDir); err != nil {
+		return err
+	}
+
+	var applied []appliedOp
+	for _, op := range b.ops {
+		name := secretfile.IDToDirName(op.id)
+		a := appliedOp{name: name}
+		if _, err := root.Stat(name); err == nil {
+			if err := root.Rename(name, path.Join(oldDir, name)); err != nil {
+				return errors.Join(err, rollback(root, oldDir, applied))
+			}
+			a.movedOld = true
+		}
+		applied = append(applied, a)
+
+		if op.secrets == nil {
+			continue
+		}
+		if err := root.Rename(path.Join(newDir, name), name); err != nil {
+			return errors.Join(err, rollback(root, oldDir, applied))
+		}
+		applied[len(applied)-1].placedNew = true
+	}
+
+	// the batch is applied, failing to keep the previous versions must not
+	// undo it
+	if b.store.versionHistory > 0 {
+		for _, a := range applied {
+			if !a.movedOld || !a.placedNew {
+				continue
+			}
+			if err := b.store.archive(path.Join(oldDir, a.name), a.name); err != nil {
+				b.store.logger.Errorf("could not keep the previous version of secret directory %s: %s", a.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// persistStaged writes the staged secrets to dir.
+func (b *fileBatch[T]) persistStaged(dir string) error {
+	staging, err := b.store.filesystem.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = staging.Close()
+	}()
+
+	for _, op := range b.ops {
+		if op.secrets == nil {
+			continue
+		}
+		if err := secretfile.Persist(op.id, staging, op.metadata, op.secrets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appliedOp records the renames performed for a single op of a batch.
+type appliedOp struct {
+	name      string
+	movedOld  bool
+	placedNew bool
+}
+
+// rollback undoes the renames of the applied ops in reverse order, moving the
+// previous secrets back from oldDir.
+func rollback(root *os.Root, oldDir string, applied []appliedOp) error {
+	var errs []error
+	for _, a := range slices.Backward(applied) {
+		if a.placedNew {
+			if err := root.RemoveAll(a.name); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if a.movedOld {
+			if err := root.Rename(path.Join(oldDir, a.name), a.name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package posixage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/posixage/internal/secretfile"
+)
+
+var _ store.Versioner = &fileStore[store.Secret]{}
+
+// persist writes the secret of id. With [WithVersionHistory], the secret it
+// replaces is kept as a previous version.
+//
+// The secret must be locked for writing, see [fileStore.tryLockID].
+func (f *fileStore[T]) persist(id store.ID, metadata map[string]string, secrets []secretfile.EncryptedSecret) error {
+	root := f.filesystem
+	name := secretfile.IDToDirName(id)
+	if f.versionHistory == 0 {
+		return secretfile.Persist(id, root, metadata, secrets)
+	}
+	if _, err := root.Lstat(name); errors.Is(err, fs.ErrNotExist) {
+		return secretfile.Persist(id, root, metadata, secrets)
+	} else if err != nil {
+		return err
+	}
+
+	// move the current secret aside, Persist would remove it otherwise
+	stagingDir := batchDirPrefix + rand.Text()
+	if err := root.Mkdir(stagingDir, 0o700); err != nil {
+		return err
+	}
+	defer func() {
+		if err := root.RemoveAll(stagingDir); err != nil {
+			f.logger.Errorf("could not remove staging directory %s: %s", stagingDir, err)
+		}
+	}()
+	previous := path.Join(stagingDir, name)
+	if err := root.Rename(name, previous); err != nil {
+		return err
+	}
+	if err := secretfile.Persist(id, root, metadata, secrets); err != nil {
+		return errors.Join(err, root.Rename(previous, name))
+	}
+	// the new secret is saved, failing to keep the previous one must not
+	// undo it
+	if err := f.archive(previous, name); err != nil {
+		f.logger.Errorf("could not keep the previous version of secret %s: %s", id, err)
+	}
+	return nil
+}
+
+// archive moves the secret directory previous, along with its own previous
+// versions, into the versions of the secret directory current, then prunes
+// them to the configured [WithVersionHistory].
+func (f *fileStore[T]) archive(previous, current string) error {
+	root := f.filesystem
+	versionsDir := path.Join(current, secretfile.VersionsDirName)
+	_, err := root.Lstat(path.Join(previous, secretfile.VersionsDirName))
+	switch {
+	case err == nil:
+		err = root.Rename(path.Join(previous, secretfile.VersionsDirName), versionsDir)
+	case errors.Is(err, fs.ErrNotExist):
+		err = root.Mkdir(versionsDir, 0o700)
+	}
+	if err != nil {
+		return err
+	}
+
+	versions, err := secretfile.Versions(root, current)
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1] + 1
+	}
+	if err := root.Rename(previous, secretfile.VersionDirName(current, next)); err != nil {
+		return err
+	}
+
+	versions = append(versions, next)
+	var errs []error
+	for _, version := range versions[:max(0, len(versions)-f.versionHistory)] {
+		errs = append(errs, root.RemoveAll(secretfile.VersionDirName(current, version)))
+	}
+	return errors.Join(errs...)
+}
+
+// ListVersions implements [store.Versioner]. A version was saved when its
+// metadata file was written.
+func (f *fileStore[T]) ListVersions(ctx context.Context, id store.ID) ([]store.VersionInfo, error) {
+	unlock, err := f.tryLockID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	name := secretfile.IDToDirName(id)
+	if _, err := f.filesystem.Lstat(name); errors.Is(err, fs.ErrNotExist) {
+		return nil, store.ErrCredentialNotFound
+	}
+	versions, err := secretfile.Versions(f.filesystem, name)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]store.VersionInfo, 0, len(versions))
+	for _, version := range slices.Backward(versions) {
+		info, err := f.filesystem.Stat(path.Join(secretfile.VersionDirName(name, version), secretfile.MetadataFileName))
+		if err != nil {
+			f.logger.Warnf("skipping version %d of secret %s: %s", version, id, err)
+			continue
+		}
+		infos = append(infos, store.VersionInfo{Version: version, SavedAt: info.ModTime()})
+	}
+	return infos, nil
+}
+
+// GetVersion implements [store.Versioner].
+func (f *fileStore[T]) GetVersion(ctx context.Context, id store.ID, version int) (store.Secret, error) {
+	unlock, err := f.tryLockID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	versionDir, err := f.filesystem.OpenRoot(secretfile.VersionDirName(secretfile.IDToDirName(id), version))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("version %d of secret %s: %w", version, id, store.ErrCredentialNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = versionDir.Close()
+	}()
+
+	encryptedSecrets, metadata, err := secretfile.RestoreSecretDir(versionDir)
+	if err != nil {
+		return nil, err
+	}
+	return f.newSecret(ctx, id, encryptedSecrets, metadata)
+}
@@ -91,10 +91,10 @@ var heartbeatInterval = 10 * time.Second
 type UnlockFunc func() error
 
 // openFile is a helper function for internal use by [tryLock]
-func openFile(root *os.Root) (*os.File, error) {
+func openFile(root *os.Root, name string) (*os.File, error) {
 	// we need to open in readwrite mode so that the file modtime gets updated
 	// with os.Truncate when we actually acquire a lock.
-	fl, err := root.OpenFile(lockFileName, os.O_RDWR|os.O_CREATE, 0o600)
+	fl, err := root.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o600)
 	if err != nil {
 		return nil, err
 	}
@@ -115,8 +115,8 @@ func openFile(root *os.Root) (*os.File, error) {
 // our [lockFile] call, [lockFile] will succeed on the unlinked inode but
 // the path will resolve to a brand-new inode. Treating that as a failure
 // forces the caller to drop the bad lock and try again with a fresh fd.
-func acquireOnce(root *os.Root, exclusive bool) (*os.File, error) {
-	fl, err := openFile(root)
+func acquireOnce(root *os.Root, name string, exclusive bool) (*os.File, error) {
+	fl, err := openFile(root, name)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +133,7 @@ func acquireOnce(root *os.Root, exclusive bool) (*os.File, error) {
 	// already-orphaned inode.
 	_ = fl.Truncate(0)
 
-	same, err := isCurrentLockFile(fl, root)
+	same, err := isCurrentLockFile(fl, root, name)
 	if err != nil {
 		_ = releaseLock(fl)
 		_ = fl.Close()
@@ -150,12 +150,12 @@ func acquireOnce(root *os.Root, exclusive bool) (*os.File, error) {
 // isCurrentLockFile reports whether the locked descriptor [fl] still refers
 // to the file at the lock-file path. It returns false when the path no
 // longer exists or has been replaced by a different inode.
-func isCurrentLockFile(fl *os.File, root *os.Root) (bool, error) {
+func isCurrentLockFile(fl *os.File, root *os.Root, name string) (bool, error) {
 	fdInfo, err := fl.Stat()
 	if err != nil {
 		return false, err
 	}
-	pathInfo, err := root.Stat(lockFileName)
+	pathInfo, err := root.Stat(name)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return false, nil
@@ -165,11 +165,11 @@ func isCurrentLockFile(fl *os.File, root *os.Root) (bool, error) {
 	return os.SameFile(fdInfo, pathInfo), nil
 }
 
-func tryLock(ctx context.Context, root *os.Root, exclusive bool) (UnlockFunc, error) {
+func tryLock(ctx context.Context, root *os.Root, name string, exclusive bool) (UnlockFunc, error) {
 	logger := loggerFromCtx(ctx)
-	fl, err := acquireOnce(root, exclusive)
+	fl, err := acquireOnce(root, name, exclusive)
 	if err == nil {
-		return startHeartbeat(fl, root, logger), nil
+		return startHeartbeat(fl, root, name, logger), nil
 	}
 	firstErr := errors.Join(ErrLockUnsuccessful, err)
 
@@ -177,15 +177,15 @@ func tryLock(ctx context.Context, root *os.Root, exclusive bool) (UnlockFunc, er
 		return nil, errors.Join(firstErr, ctxErr)
 	}
 
-	if recoverErr := recoverStaleLock(root); recoverErr != nil && !errors.Is(recoverErr, errRecoverLock) {
+	if recoverErr := recoverStaleLock(root, name); recoverErr != nil && !errors.Is(recoverErr, errRecoverLock) {
 		return nil, errors.Join(firstErr, recoverErr)
 	}
 
-	fl, err = retryLock(ctx, root, exclusive)
+	fl, err = retryLock(ctx, root, name, exclusive)
 	if err != nil {
 		return nil, err
 	}
-	return startHeartbeat(fl, root, logger), nil
+	return startHeartbeat(fl, root, name, logger), nil
 }
 
 // startHeartbeat launches the modtime-refresh goroutine for a locked file
@@ -196,12 +196,12 @@ func tryLock(ctx context.Context, root *os.Root, exclusive bool) (UnlockFunc, er
 // The supplied logger is used by the goroutine to surface truncate
 // failures and inode-mismatch hijacks. A [noopLogger] is acceptable when
 // the caller has no logging plumbed.
-func startHeartbeat(fl *os.File, root *os.Root, logger logging.Logger) UnlockFunc {
+func startHeartbeat(fl *os.File, root *os.Root, name string, logger logging.Logger) UnlockFunc {
 	hbCtx, stop := context.WithCancel(context.Background())
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		heartbeat(hbCtx, fl, root, logger)
+		heartbeat(hbCtx, fl, root, name, logger)
 	}()
 	return sync.OnceValue(func() error {
 		stop()
@@ -227,7 +227,7 @@ func startHeartbeat(fl *os.File, root *os.Root, logger logging.Logger) UnlockFun
 //
 // The goroutine returns when ctx is canceled by [startHeartbeat]'s
 // returned [UnlockFunc].
-func heartbeat(ctx context.Context, fl *os.File, root *os.Root, logger logging.Logger) {
+func heartbeat(ctx context.Context, fl *os.File, root *os.Root, name string, logger logging.Logger) {
 	ticker := time.NewTicker(heartbeatInterval)
 	defer ticker.Stop()
 	for {
@@ -239,7 +239,7 @@ func heartbeat(ctx context.Context, fl *os.File, root *os.Root, logger logging.L
 				logger.Warnf("flock heartbeat: truncate failed: %v", err)
 				continue
 			}
-			same, err := isCurrentLockFile(fl, root)
+			same, err := isCurrentLockFile(fl, root, name)
 			if err != nil {
 				logger.Warnf("flock heartbeat: inode verify failed: %v", err)
 				continue
@@ -255,13 +255,13 @@ func heartbeat(ctx context.Context, fl *os.File, root *os.Root, logger logging.L
 // canceled or a verified lock is obtained. Each iteration opens a fresh
 // fd, so a [errStaleInode] result simply causes the next attempt to start
 // over against whatever file is currently at the path.
-func retryLock(ctx context.Context, root *os.Root, exclusive bool) (*os.File, error) {
+func retryLock(ctx context.Context, root *os.Root, name string, exclusive bool) (*os.File, error) {
 	ep := backoff.NewExponentialBackOff()
 	ep.InitialInterval = time.Millisecond * 10
 	ep.MaxInterval = time.Millisecond * 100
 
 	fl, err := backoff.Retry(ctx, func() (*os.File, error) {
-		return acquireOnce(root, exclusive)
+		return acquireOnce(root, name, exclusive)
 	}, backoff.WithBackOff(ep), backoff.WithMaxElapsedTime(0))
 	if err != nil {
 		return nil, errors.Join(ErrLockUnsuccessful, err)
@@ -302,7 +302,7 @@ func retryLock(ctx context.Context, root *os.Root, exclusive bool) (*os.File, er
 // and the heartbeat goroutine for the remaining lifetime of the process.
 // See [UnlockFunc] for details.
 func TryLock(ctx context.Context, root *os.Root) (UnlockFunc, error) {
-	return tryLock(ctx, root, true)
+	return tryLock(ctx, root, lockFileName, true)
 }
 
 // TryRLock acquires a non-exclusive advisory lock on a lock file.
@@ -338,5 +338,18 @@ func TryLock(ctx context.Context, root *os.Root) (UnlockFunc, error) {
 // and the heartbeat goroutine for the remaining lifetime of the process.
 // See [UnlockFunc] for details.
 func TryRLock(ctx context.Context, root *os.Root) (UnlockFunc, error) {
-	return tryLock(ctx, root, false)
+	return tryLock(ctx, root, lockFileName, false)
+}
+
+// TryLockFile acquires an exclusive advisory lock like [TryLock], but on
+// the lock file name under root instead of the lock file of the store. It
+// allows locking parts of the store independently.
+func TryLockFile(ctx context.Context, root *os.Root, name string) (UnlockFunc, error) {
+	return tryLock(ctx, root, name, true)
+}
+
+// TryRLockFile acquires a non-exclusive advisory lock like [TryRLock], but
+// on the lock file name under root instead of the lock file of the store.
+func TryRLockFile(ctx context.Context, root *os.Root, name string) (UnlockFunc, error) {
+	return tryLock(ctx, root, name, false)
 }
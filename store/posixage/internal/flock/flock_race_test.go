@@ -39,17 +39,17 @@ func TestFlockRaces(t *testing.T) {
 		require.NoError(t, err)
 		t.Cleanup(func() { assert.NoError(t, root.Close()) })
 
-		fl, err := openFile(root)
+		fl, err := openFile(root, lockFileName)
 		require.NoError(t, err)
 		t.Cleanup(func() { _ = fl.Close() })
 
-		same, err := isCurrentLockFile(fl, root)
+		same, err := isCurrentLockFile(fl, root, lockFileName)
 		require.NoError(t, err)
 		assert.True(t, same, "fd and path should resolve to the same inode immediately after open")
 
 		require.NoError(t, root.Remove(lockFileName))
 
-		same, err = isCurrentLockFile(fl, root)
+		same, err = isCurrentLockFile(fl, root, lockFileName)
 		require.NoError(t, err)
 		assert.False(t, same, "fd should no longer match the path after the file is unlinked")
 	})
@@ -63,20 +63,20 @@ func TestFlockRaces(t *testing.T) {
 		require.NoError(t, err)
 		t.Cleanup(func() { assert.NoError(t, root.Close()) })
 
-		flOld, err := openFile(root)
+		flOld, err := openFile(root, lockFileName)
 		require.NoError(t, err)
 		t.Cleanup(func() { _ = flOld.Close() })
 
 		require.NoError(t, root.Remove(lockFileName))
-		flNew, err := openFile(root)
+		flNew, err := openFile(root, lockFileName)
 		require.NoError(t, err)
 		t.Cleanup(func() { _ = flNew.Close() })
 
-		same, err := isCurrentLockFile(flOld, root)
+		same, err := isCurrentLockFile(flOld, root, lockFileName)
 		require.NoError(t, err)
 		assert.False(t, same, "old fd is on the unlinked inode; path now points to a new inode")
 
-		same, err = isCurrentLockFile(flNew, root)
+		same, err = isCurrentLockFile(flNew, root, lockFileName)
 		require.NoError(t, err)
 		assert.True(t, same, "newly opened fd should match the current path")
 	})
@@ -92,7 +92,7 @@ func TestFlockRaces(t *testing.T) {
 		require.NoError(t, err)
 		t.Cleanup(func() { assert.NoError(t, root.Close()) })
 
-		fl, err := openFile(root)
+		fl, err := openFile(root, lockFileName)
 		require.NoError(t, err)
 		require.NoError(t, fl.Close())
 		stale := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -106,7 +106,7 @@ func TestFlockRaces(t *testing.T) {
 		for i := range 2 {
 			go func() {
 				defer wg.Done()
-				errs[i] = recoverStaleLock(root)
+				errs[i] = recoverStaleLock(root, lockFileName)
 			}()
 		}
 		wg.Wait()
@@ -136,7 +136,7 @@ func TestFlockRaces(t *testing.T) {
 		require.NoError(t, err)
 		t.Cleanup(func() { assert.NoError(t, root.Close()) })
 
-		unlock, err := tryLock(t.Context(), root, true)
+		unlock, err := tryLock(t.Context(), root, lockFileName, true)
 		require.NoError(t, err)
 		t.Cleanup(func() { _ = unlock() })
 
@@ -144,7 +144,7 @@ func TestFlockRaces(t *testing.T) {
 		// every 20ms so the file's modtime should keep refreshing.
 		time.Sleep(5 * staleThreshold)
 
-		assert.ErrorIs(t, recoverStaleLock(root), errRecoverLock,
+		assert.ErrorIs(t, recoverStaleLock(root, lockFileName), errRecoverLock,
 			"recoverStaleLock should refuse to recover a heartbeating lock")
 	})
 }
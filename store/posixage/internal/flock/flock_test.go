@@ -34,11 +34,11 @@ func TestFlock(t *testing.T) {
 		})
 
 		exclusive := true
-		unlock, err := tryLock(t.Context(), root, exclusive)
+		unlock, err := tryLock(t.Context(), root, lockFileName, exclusive)
 		require.NoError(t, err)
 		require.NoError(t, unlock())
 
-		unlock, err = tryLock(t.Context(), root, exclusive)
+		unlock, err = tryLock(t.Context(), root, lockFileName, exclusive)
 		require.NoError(t, err)
 		require.NoError(t, unlock())
 	})
@@ -50,7 +50,7 @@ func TestFlock(t *testing.T) {
 		})
 
 		exclusive := true
-		unlock, err := tryLock(t.Context(), root, exclusive)
+		unlock, err := tryLock(t.Context(), root, lockFileName, exclusive)
 		require.NoError(t, err)
 		t.Cleanup(func() {
 			_ = unlock()
@@ -59,14 +59,14 @@ func TestFlock(t *testing.T) {
 		ctx, cancel := context.WithTimeout(t.Context(), 75*time.Millisecond)
 		defer cancel()
 
-		_, err = tryLock(ctx, root, exclusive)
+		_, err = tryLock(ctx, root, lockFileName, exclusive)
 		require.ErrorIs(t, err, ErrLockUnsuccessful)
 		require.ErrorIs(t, err, context.DeadlineExceeded)
 
 		ctx, cancel = context.WithTimeout(t.Context(), 75*time.Millisecond)
 		defer cancel()
 
-		_, err = tryLock(ctx, root, !exclusive)
+		_, err = tryLock(ctx, root, lockFileName, !exclusive)
 		require.ErrorIs(t, err, ErrLockUnsuccessful)
 		require.ErrorIs(t, err, context.DeadlineExceeded)
 	})
@@ -79,13 +79,13 @@ func TestFlock(t *testing.T) {
 		})
 
 		exclusive := true
-		unlock, err := tryLock(t.Context(), root, !exclusive)
+		unlock, err := tryLock(t.Context(), root, lockFileName, !exclusive)
 		require.NoError(t, err)
 		t.Cleanup(func() {
 			_ = unlock()
 		})
 
-		unlockTwo, err := tryLock(t.Context(), root, !exclusive)
+		unlockTwo, err := tryLock(t.Context(), root, lockFileName, !exclusive)
 		require.NoError(t, err)
 		t.Cleanup(func() {
 			_ = unlockTwo()
@@ -94,7 +94,7 @@ func TestFlock(t *testing.T) {
 		ctx, cancel := context.WithTimeout(t.Context(), 75*time.Millisecond)
 		defer cancel()
 
-		_, err = tryLock(ctx, root, exclusive)
+		_, err = tryLock(ctx, root, lockFileName, exclusive)
 		require.ErrorIs(t, err, ErrLockUnsuccessful)
 		require.ErrorIs(t, err, context.DeadlineExceeded)
 	})
@@ -110,14 +110,14 @@ func TestFlock(t *testing.T) {
 		})
 
 		exclusive := true
-		_, err = tryLock(t.Context(), root, exclusive)
+		_, err = tryLock(t.Context(), root, lockFileName, exclusive)
 		require.NoError(t, err)
 
 		// change the lock file modification time
 		fakeModTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 		require.NoError(t, root.Chtimes(lockFileName, fakeModTime, fakeModTime))
 
-		unlock, err := tryLock(t.Context(), root, exclusive)
+		unlock, err := tryLock(t.Context(), root, lockFileName, exclusive)
 		require.NoError(t, err)
 		require.NoError(t, unlock())
 	})
@@ -130,7 +130,7 @@ func TestFlock(t *testing.T) {
 		})
 
 		exclusive := true
-		unlock, err := tryLock(t.Context(), root, exclusive)
+		unlock, err := tryLock(t.Context(), root, lockFileName, exclusive)
 		require.NoError(t, err)
 
 		release := make(chan struct{})
@@ -146,7 +146,7 @@ func TestFlock(t *testing.T) {
 		ctx, cancel := context.WithTimeout(t.Context(), 750*time.Millisecond)
 		defer cancel()
 
-		unlockTwo, err := tryLock(ctx, root, exclusive)
+		unlockTwo, err := tryLock(ctx, root, lockFileName, exclusive)
 		require.NoError(t, err)
 		require.NoError(t, unlockTwo())
 	})
@@ -159,7 +159,7 @@ func TestFlock(t *testing.T) {
 		})
 
 		exclusive := true
-		unlock, err := tryLock(t.Context(), root, exclusive)
+		unlock, err := tryLock(t.Context(), root, lockFileName, exclusive)
 		require.NoError(t, err)
 		t.Cleanup(func() {
 			_ = unlock()
@@ -169,7 +169,7 @@ func TestFlock(t *testing.T) {
 		defer cancel()
 
 		start := time.Now()
-		_, err = tryLock(ctx, root, exclusive)
+		_, err = tryLock(ctx, root, lockFileName, exclusive)
 		require.ErrorIs(t, err, ErrLockUnsuccessful)
 		require.ErrorIs(t, err, context.DeadlineExceeded)
 		assert.Less(t, time.Since(start), time.Second)
@@ -183,7 +183,7 @@ func TestFlock(t *testing.T) {
 		})
 
 		exclusive := true
-		unlock, err := tryLock(t.Context(), root, exclusive)
+		unlock, err := tryLock(t.Context(), root, lockFileName, exclusive)
 		require.NoError(t, err)
 		t.Cleanup(func() {
 			_ = unlock()
@@ -197,7 +197,7 @@ func TestFlock(t *testing.T) {
 		ctx, cancel := context.WithTimeout(t.Context(), 75*time.Millisecond)
 		cancel()
 
-		_, err = tryLock(ctx, root, exclusive)
+		_, err = tryLock(ctx, root, lockFileName, exclusive)
 		require.ErrorIs(t, err, ErrLockUnsuccessful)
 		require.ErrorIs(t, err, context.Canceled)
 
@@ -218,7 +218,7 @@ func TestRecoverLock(t *testing.T) {
 		require.NoError(t, err)
 		t.Cleanup(func() { _ = f.Close() })
 
-		require.ErrorIs(t, recoverStaleLock(root), errRecoverLock)
+		require.ErrorIs(t, recoverStaleLock(root, lockFileName), errRecoverLock)
 	})
 
 	t.Run("recoverLock removes the file if it is older than 30 seconds", func(t *testing.T) {
@@ -235,7 +235,7 @@ func TestRecoverLock(t *testing.T) {
 		fakeModTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 		require.NoError(t, root.Chtimes(lockFileName, fakeModTime, fakeModTime))
 
-		require.NoError(t, recoverStaleLock(root))
+		require.NoError(t, recoverStaleLock(root, lockFileName))
 		_, err = root.Stat(lockFileName)
 		require.ErrorIs(t, err, os.ErrNotExist)
 	})
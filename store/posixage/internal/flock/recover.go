@@ -32,8 +32,8 @@ var errRecoverLock = errors.New("recovery failed. lock file is not older than 30
 // Exposed as a var rather than a const so tests can shorten it.
 var staleThreshold = 30 * time.Second
 
-// recoverStaleLock attempts to clear a stale lock file at the configured
-// lock-file path.
+// recoverStaleLock attempts to clear a stale lock file at the lock-file path
+// name.
 //
 // A lock is considered stale if its modification time is at least
 // [staleThreshold] old. On Unix the stale lock file is unlinked; on
@@ -47,8 +47,8 @@ var staleThreshold = 30 * time.Second
 //
 // It returns nil when the lock file was removed (or already gone) and
 // [errRecoverLock] when the lock was not considered stale.
-func recoverStaleLock(root *os.Root) error {
-	info, err := root.Stat(lockFileName)
+func recoverStaleLock(root *os.Root, name string) error {
+	info, err := root.Stat(name)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			// nothing to recover; subsequent open will create a fresh file
@@ -61,7 +61,7 @@ func recoverStaleLock(root *os.Root) error {
 		return errRecoverLock
 	}
 
-	if err := root.Remove(lockFileName); err != nil {
+	if err := root.Remove(name); err != nil {
 		// another caller raced us to the unlink — recovery still succeeded
 		if errors.Is(err, fs.ErrNotExist) {
 			return nil
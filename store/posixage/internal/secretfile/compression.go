@@ -0,0 +1,107 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Compression identifies how a secret was compressed before it was
+// encrypted.
+type Compression string
+
+const (
+	NoCompression   Compression = ""
+	GzipCompression Compression = "gzip"
+)
+
+// compressionHeader is the first line of a secret file holding a compressed
+// secret, followed by the [Compression] and a newline. Secret files without
+// it are uncompressed, which is always the case for files written before
+// compression was supported as age files start with "age-encryption.org/".
+const compressionHeader = "posixage-compression: "
+
+// ValidateCompressionLevel reports whether level is a valid gzip compression
+// level.
+func ValidateCompressionLevel(level int) error {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return fmt.Errorf("invalid compression level (%d..%d): %d", gzip.HuffmanOnly, gzip.BestCompression, level)
+	}
+	return nil
+}
+
+// Compress compresses data with gzip at the given level.
+func Compress(data []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress reverses [Compress] for data compressed with c.
+func Decompress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case NoCompression:
+		return data, nil
+	case GzipCompression:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %q", c)
+	}
+}
+
+// encodeSecretFile returns the content of the secret file for s.
+func encodeSecretFile(s EncryptedSecret) []byte {
+	if s.Compression == NoCompression {
+		return s.EncryptedData
+	}
+	header := compressionHeader + string(s.Compression) + "\n"
+	return append([]byte(header), s.EncryptedData...)
+}
+
+// decodeSecretFile splits the content of a secret file into its compression
+// and encrypted data.
+func decodeSecretFile(data []byte) (Compression, []byte, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(compressionHeader))
+	if !ok {
+		return NoCompression, data, nil
+	}
+	value, encryptedData, ok := bytes.Cut(rest, []byte("\n"))
+	if !ok {
+		return "", nil, fmt.Errorf("malformed compression header")
+	}
+	switch c := Compression(value); c {
+	case GzipCompression:
+		return c, encryptedData, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported compression: %q", c)
+	}
+}
@@ -16,10 +16,13 @@ package secretfile
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"filippo.io/age"
 	"filippo.io/age/agessh"
+	"filippo.io/age/plugin"
 )
 
 type (
@@ -29,7 +32,7 @@ type (
 	PromptFunc func(context.Context) ([]byte, error)
 
 	// KeyType identifies the type of encryption or decryption key associated
-	// with a secret (e.g., password, age, or SSH).
+	// with a secret (e.g., password, age, SSH, or an age plugin).
 	KeyType string
 )
 
@@ -37,6 +40,9 @@ const (
 	PasswordKeyType KeyType = "pass"
 	AgeKeyType      KeyType = "age"
 	SSHKeyType      KeyType = "ssh"
+	// AgePluginKeyType keys are handled by an age-plugin-<name> binary
+	// found in $PATH, e.g. age-plugin-yubikey for hardware-backed keys.
+	AgePluginKeyType KeyType = "plugin"
 )
 
 // MaxScryptWorkFactor is the largest scrypt work factor (2^logN) that may be
@@ -49,6 +55,8 @@ type recipientOptions struct {
 	// scryptWorkFactor is the scrypt work factor (2^logN). A zero value means
 	// the age default is used.
 	scryptWorkFactor int
+	// pluginUI handles the interactions requested by age plugins.
+	pluginUI *plugin.ClientUI
 }
 
 // RecipientOption configures how recipients are built by [GetRecipients].
@@ -68,7 +76,15 @@ func WithScryptWorkFactor(logN int) RecipientOption {
 	}
 }
 
-func getRecipient(k KeyType, encryptionKey string, o *recipientOptions) (age.Recipient, error) {
+// WithPluginUI sets the UI used by [AgePluginKeyType] recipients to interact
+// with the user. It is a no-op for the other key types.
+func WithPluginUI(ui *plugin.ClientUI) RecipientOption {
+	return func(o *recipientOptions) {
+		o.pluginUI = ui
+	}
+}
+
+func getRecipients(k KeyType, encryptionKey string, o *recipientOptions) ([]age.Recipient, error) {
 	var recipient age.Recipient
 	var err error
 
@@ -84,9 +100,12 @@ func getRecipient(k KeyType, encryptionKey string, o *recipientOptions) (age.Rec
 		}
 		recipient = scryptRecipient
 	case AgeKeyType:
-		recipient, err = age.ParseX25519Recipient(encryptionKey)
+		// a single key or a recipients file listing many of them
+		return age.ParseRecipients(strings.NewReader(encryptionKey))
 	case SSHKeyType:
 		recipient, err = agessh.ParseRecipient(encryptionKey)
+	case AgePluginKeyType:
+		return parsePluginRecipients(encryptionKey, o.pluginUI)
 	default:
 		return nil, fmt.Errorf("unsupported encryption type %T", k)
 	}
@@ -95,7 +114,29 @@ func getRecipient(k KeyType, encryptionKey string, o *recipientOptions) (age.Rec
 		return nil, err
 	}
 
-	return recipient, nil
+	return []age.Recipient{recipient}, nil
+}
+
+// parsePluginRecipients parses age plugin recipients in the recipients file
+// format, e.g. "age1yubikey1...". The plugin binary is only invoked when
+// encrypting.
+func parsePluginRecipients(encryptionKey string, ui *plugin.ClientUI) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+	for line := range strings.Lines(encryptionKey) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := plugin.NewRecipient(line, ui)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("no age plugin recipients found")
+	}
+	return recipients, nil
 }
 
 // GetRecipients returns a slice of [age.Recipient] for the given key type and
@@ -103,8 +144,13 @@ func getRecipient(k KeyType, encryptionKey string, o *recipientOptions) (age.Rec
 //
 // The recipient implementation depends on the provided [KeyType]:
 //   - passwordKeyType → [age.NewScryptRecipient]
-//   - ageKeyType      → [age.ParseX25519Recipient]
+//   - ageKeyType      → [age.ParseRecipients]
 //   - sshKeyType      → [agessh.ParseRecipient]
+//   - AgePluginKeyType → [plugin.NewRecipient]
+//
+// An age or age plugin encryption key may hold several recipients in the recipients file
+// format, i.e. one per line, with empty lines and lines starting with "#"
+// ignored.
 //
 // Optional [RecipientOption] values tune recipient creation; see
 // [WithScryptWorkFactor].
@@ -119,11 +165,11 @@ func GetRecipients(k KeyType, encryptionKeys []string, opts ...RecipientOption)
 
 	var recipients []age.Recipient
 	for _, encryptionKey := range encryptionKeys {
-		recipient, err := getRecipient(k, encryptionKey, o)
+		r, err := getRecipients(k, encryptionKey, o)
 		if err != nil {
 			return nil, err
 		}
-		recipients = append(recipients, recipient)
+		recipients = append(recipients, r...)
 	}
 	return recipients, nil
 }
@@ -135,10 +181,13 @@ func GetRecipients(k KeyType, encryptionKeys []string, opts ...RecipientOption)
 //   - PasswordKeyType → [age.NewScryptIdentity]
 //   - AgeKeyType      → [age.ParseX25519Identity]
 //   - SSHKeyType      → [agessh.ParseIdentity]
+//   - AgePluginKeyType → [plugin.NewIdentity], interacting with the user
+//     through pluginUI
 //
 // An error is returned if the key cannot be parsed or the key type is
-// unsupported.
-func GetIdentity(k KeyType, decryptionKey string) (age.Identity, error) {
+// unsupported. A missing plugin binary is only reported when the identity is
+// used, as a [*plugin.NotFoundError].
+func GetIdentity(k KeyType, decryptionKey string, pluginUI *plugin.ClientUI) (age.Identity, error) {
 	var identity age.Identity
 	var err error
 
@@ -149,6 +198,8 @@ func GetIdentity(k KeyType, decryptionKey string) (age.Identity, error) {
 		identity, err = age.ParseX25519Identity(decryptionKey)
 	case SSHKeyType:
 		identity, err = agessh.ParseIdentity([]byte(decryptionKey))
+	case AgePluginKeyType:
+		identity, err = plugin.NewIdentity(strings.TrimSpace(decryptionKey), pluginUI)
 	default:
 		return nil, fmt.Errorf("unsupported decryption type %T", k)
 	}
@@ -17,17 +17,31 @@ package secretfile
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/docker/secrets-engine/store"
 )
 
+// ErrIncompleteSecret is returned when restoring a secret directory that was
+// not fully written, e.g. because the process crashed during [Persist].
+var ErrIncompleteSecret = errors.New("incomplete secret")
+
 type EncryptedSecret struct {
 	KeyType       KeyType
 	EncryptedData []byte
+	// Compression is how the plaintext was compressed before encryption.
+	Compression Compression
+	// Preferred marks the key type the secret was saved with, which is tried
+	// first when decrypting. At most one secret of a set is preferred.
+	Preferred bool
 }
 
 // IDToDirName encodes a secret ID as a base64 string suitable for use
@@ -52,6 +66,12 @@ func DirNameToID(dirName string) (store.ID, error) {
 const (
 	SecretFileName   = "secret"
 	MetadataFileName = "metadata.json"
+	// HintFileName holds the [KeyType] of the preferred secret file.
+	HintFileName = "hint"
+	// VersionsDirName is the directory holding the previous versions of a
+	// secret, each in a sub-directory named after its version number and laid
+	// out like the secret directory itself.
+	VersionsDirName = "versions"
 )
 
 // atomicWrite writes data to a file atomically by first writing to a temporary
@@ -94,8 +114,14 @@ func atomicWrite(fs *os.Root, fileName string, data []byte) error {
 // keys cannot become inconsistent.
 //
 // Inside the directory, the function creates:
+//   - secret<KeyType> — one encrypted secret file per key type, prefixed
+//     by a compression header when the secret was compressed
+//   - hint — the key type of the preferred secret file, if any
 //   - metadata.json — a JSON-encoded metadata file (always public)
-//   - secret<KeyType> — one encrypted secret file per key type
+//
+// Each file is written atomically, and the metadata file is written last: a
+// directory without it was not fully written and is reported as
+// [ErrIncompleteSecret] when restored.
 //
 // If any step fails, the directory is removed to prevent partial or
 // inconsistent state. An error is returned in such cases.
@@ -131,27 +157,36 @@ func Persist(id store.ID, root *os.Root, metadata map[string]string, secrets []E
 		_ = secretDir.Close()
 	}()
 
+	for _, s := range secrets {
+		err = atomicWrite(secretDir, SecretFileName+string(s.KeyType), encodeSecretFile(s))
+		if err != nil {
+			return err
+		}
+		if s.Preferred {
+			err = atomicWrite(secretDir, HintFileName, []byte(s.KeyType))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	meta, err := json.Marshal(metadata)
 	if err != nil {
 		return err
 	}
-
+	// the metadata file marks the secret as complete, it must come last
 	err = atomicWrite(secretDir, MetadataFileName, meta)
 	if err != nil {
 		return err
 	}
 
-	for _, s := range secrets {
-		err = atomicWrite(secretDir, SecretFileName+string(s.KeyType), s.EncryptedData)
-		if err != nil {
-			return err
-		}
-	}
-
 	return nil
 }
 
-// RestoreSecret reads the secret and metadata files from its scoped directory
+// RestoreSecret reads the secret and metadata files from its scoped directory.
+//
+// [ErrIncompleteSecret] is returned if the metadata file is missing, or if
+// there is no secret file or an empty one.
 func RestoreSecret(id store.ID, root *os.Root) ([]EncryptedSecret, map[string]string, error) {
 	secretDir, err := root.OpenRoot(IDToDirName(id))
 	if err != nil {
@@ -161,6 +196,12 @@ func RestoreSecret(id store.ID, root *os.Root) ([]EncryptedSecret, map[string]st
 		_ = secretDir.Close()
 	}()
 
+	return RestoreSecretDir(secretDir)
+}
+
+// RestoreSecretDir reads the secret and metadata files of secretDir, which
+// can be a secret directory or one of its versions, see [RestoreSecret].
+func RestoreSecretDir(secretDir *os.Root) ([]EncryptedSecret, map[string]string, error) {
 	metadata, err := RestoreMetadata(secretDir)
 	if err != nil {
 		return nil, nil, err
@@ -171,30 +212,52 @@ func RestoreSecret(id store.ID, root *os.Root) ([]EncryptedSecret, map[string]st
 		return nil, nil, err
 	}
 
+	// the hint is optional, secrets written before it existed have none
+	hint, _ := secretDir.ReadFile(HintFileName)
+
 	var secrets []EncryptedSecret
 	for _, file := range files {
 		if file.IsDir() {
 			continue
 		}
-		if !strings.HasPrefix(file.Name(), SecretFileName) {
+		// temporary files are left behind by an interrupted atomicWrite
+		if !strings.HasPrefix(file.Name(), SecretFileName) || strings.HasSuffix(file.Name(), ".tmp") {
 			continue
 		}
-		encryptedData, err := secretDir.ReadFile(file.Name())
+		data, err := secretDir.ReadFile(file.Name())
 		if err != nil {
 			continue
 		}
+		compression, encryptedData, err := decodeSecretFile(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("secret file %s: %w", file.Name(), err)
+		}
+		if len(encryptedData) == 0 {
+			return nil, nil, fmt.Errorf("secret file %s is empty: %w", file.Name(), ErrIncompleteSecret)
+		}
+		keyType := KeyType(strings.ReplaceAll(file.Name(), SecretFileName, ""))
 		secrets = append(secrets, EncryptedSecret{
-			KeyType:       KeyType(strings.ReplaceAll(file.Name(), SecretFileName, "")),
+			KeyType:       keyType,
 			EncryptedData: encryptedData,
+			Compression:   compression,
+			Preferred:     len(hint) > 0 && string(hint) == string(keyType),
 		})
 	}
+	if len(secrets) == 0 {
+		return nil, nil, fmt.Errorf("no secret file: %w", ErrIncompleteSecret)
+	}
 
 	return secrets, metadata, nil
 }
 
-// RestoreMetadata reads and unmarshals the [metadataFileName] file
+// RestoreMetadata reads and unmarshals the [MetadataFileName] file.
+//
+// [ErrIncompleteSecret] is returned if the file is missing, see [Persist].
 func RestoreMetadata(secretDir *os.Root) (map[string]string, error) {
 	metadataStore, err := secretDir.Open(MetadataFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no metadata file: %w", ErrIncompleteSecret)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -212,3 +275,35 @@ func RestoreMetadata(secretDir *os.Root) (map[string]string, error) {
 
 	return metadata, nil
 }
+
+// Versions returns the version numbers found in the [VersionsDirName]
+// directory of the secret directory secretDirName, in ascending order.
+// Entries that are not version directories are ignored.
+func Versions(root *os.Root, secretDirName string) ([]int, error) {
+	entries, err := fs.ReadDir(root.FS(), path.Join(secretDirName, VersionsDirName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version, err := strconv.Atoi(entry.Name())
+		if err != nil || version < 1 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return versions, nil
+}
+
+// VersionDirName returns the path, relative to the store root, of the
+// version directory of the secret directory secretDirName.
+func VersionDirName(secretDirName string, version int) string {
+	return path.Join(secretDirName, VersionsDirName, strconv.Itoa(version))
+}
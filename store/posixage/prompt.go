@@ -18,6 +18,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"time"
 
 	"github.com/docker/secrets-engine/store/posixage/internal/secretfile"
 )
@@ -27,14 +30,39 @@ type (
 	EncryptionAgeX25519 secretfile.PromptFunc
 	// EncryptionSSH supports ssh-rsa and ssh-ed25519
 	EncryptionSSH secretfile.PromptFunc
+	// EncryptionAgePlugin are age plugin recipients, e.g. "age1yubikey1...",
+	// in the recipients file format
+	EncryptionAgePlugin secretfile.PromptFunc
 
 	// DecryptionAgeX25519 is the age private key
 	DecryptionAgeX25519 secretfile.PromptFunc
 	// DecryptionSSH is the ssh private key
 	DecryptionSSH      secretfile.PromptFunc
 	DecryptionPassword secretfile.PromptFunc
+	// DecryptionAgePlugin is an age plugin identity, e.g.
+	// "AGE-PLUGIN-YUBIKEY-1...". The age-plugin-<name> binary must be in
+	// $PATH when decrypting.
+	DecryptionAgePlugin secretfile.PromptFunc
 )
 
+// AgeRecipientsFile returns an [EncryptionAgeX25519] callback that encrypts to
+// every age public key listed in the recipients file at path, e.g. to share a
+// secret with a team. The file lists one recipient per line; empty lines and
+// lines starting with "#" are ignored.
+//
+// The file is read each time a secret is encrypted, so changes to it apply to
+// the secrets written afterwards. Any one of the matching identities can
+// decrypt the secret.
+func AgeRecipientsFile(path string) EncryptionAgeX25519 {
+	return func(_ context.Context) ([]byte, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading age recipients file: %w", err)
+		}
+		return data, nil
+	}
+}
+
 type promptCaller interface {
 	call(context.Context) ([]byte, error)
 }
@@ -63,6 +91,49 @@ func (dp DecryptionPassword) call(ctx context.Context) ([]byte, error) {
 	return dp(ctx)
 }
 
+func (ep EncryptionAgePlugin) call(ctx context.Context) ([]byte, error) {
+	return ep(ctx)
+}
+
+func (dp DecryptionAgePlugin) call(ctx context.Context) ([]byte, error) {
+	return dp(ctx)
+}
+
+// callPrompt invokes the callback and waits for it to return or for ctx to be
+// done, whichever happens first. A timeout greater than zero bounds the call
+// further.
+//
+// Callbacks are not required to honor the context, in which case they are
+// left to finish in the background and any key they return is discarded.
+func callPrompt(ctx context.Context, f promptCaller, timeout time.Duration) ([]byte, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		key []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		key, err := f.call(ctx)
+		done <- result{key: key, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.key, r.err
+	case <-ctx.Done():
+		go func() {
+			r := <-done
+			clear(r.key)
+		}()
+		return nil, fmt.Errorf("waiting for prompt callback: %w", ctx.Err())
+	}
+}
+
 func getPromptCallerKeyType(f promptCaller) (secretfile.KeyType, error) {
 	switch f.(type) {
 	case EncryptionPassword:
@@ -77,6 +148,8 @@ func getPromptCallerKeyType(f promptCaller) (secretfile.KeyType, error) {
 		return secretfile.AgeKeyType, nil
 	case DecryptionSSH:
 		return secretfile.SSHKeyType, nil
+	case EncryptionAgePlugin, DecryptionAgePlugin:
+		return secretfile.AgePluginKeyType, nil
 	default:
 		return "", errors.New("invalid callback function type")
 	}
@@ -90,9 +163,11 @@ func getPromptCallerKeyType(f promptCaller) (secretfile.KeyType, error) {
 // where the map key is the callback's key type and the value is a slice of
 // all collected keys for that type.
 //
+// Each callback is bounded by timeout, see [callPrompt].
+//
 // It returns an error if any callback fails, if the key type cannot be
 // determined, or if a callback returns an empty key.
-func promptForEncryptionKeys(ctx context.Context, funcs []promptCaller) (map[secretfile.KeyType][]string, error) {
+func promptForEncryptionKeys(ctx context.Context, funcs []promptCaller, timeout time.Duration) (map[secretfile.KeyType][]string, error) {
 	m := map[secretfile.KeyType][]string{}
 	for _, f := range funcs {
 		groupType, err := getPromptCallerKeyType(f)
@@ -100,7 +175,7 @@ func promptForEncryptionKeys(ctx context.Context, funcs []promptCaller) (map[sec
 			return nil, err
 		}
 
-		raw, err := f.call(ctx)
+		raw, err := callPrompt(ctx, f, timeout)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,157 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prompt provides interactive callbacks for the posixage store that
+// read a passphrase from the controlling terminal without echoing it.
+//
+// The callbacks never fall back to reading stdin. When no terminal is
+// available they return [ErrNoTTY], so that scripts have to provide the
+// passphrase explicitly, e.g. from a file or an environment variable.
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/secrets-engine/store/posixage"
+)
+
+// ErrNoTTY is returned when there is no terminal to prompt the user on.
+var ErrNoTTY = errors.New("no terminal available to prompt for a passphrase")
+
+// ErrPasswordMismatch is returned by [Password] when the confirmation does
+// not match the passphrase.
+var ErrPasswordMismatch = errors.New("passphrases do not match")
+
+// terminal is the controlling terminal of the process.
+type terminal interface {
+	io.ReadWriteCloser
+	// disableEcho stops echoing the input and returns a function restoring
+	// the previous state.
+	disableEcho() (restore func() error, err error)
+}
+
+// openTerminal is replaced in tests.
+var openTerminal = openTTY
+
+// Password returns an [posixage.EncryptionPassword] callback prompting the
+// user for a passphrase with message and asking to confirm it.
+func Password(message string) posixage.EncryptionPassword {
+	return func(ctx context.Context) ([]byte, error) {
+		return readPassword(ctx, message, true)
+	}
+}
+
+// DecryptionPassword returns a [posixage.DecryptionPassword] callback
+// prompting the user for a passphrase with message.
+func DecryptionPassword(message string) posixage.DecryptionPassword {
+	return func(ctx context.Context) ([]byte, error) {
+		return readPassword(ctx, message, false)
+	}
+}
+
+func readPassword(ctx context.Context, message string, confirm bool) ([]byte, error) {
+	tty, err := openTerminal()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoTTY, err)
+	}
+	defer tty.Close()
+
+	restore, err := tty.disableEcho()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoTTY, err)
+	}
+	defer func() { _ = restore() }()
+
+	type result struct {
+		password []byte
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		password, err := promptLine(tty, message)
+		if err != nil || !confirm {
+			done <- result{password: password, err: err}
+			return
+		}
+		confirmation, err := promptLine(tty, "Confirm "+lowerFirst(message))
+		defer clear(confirmation)
+		if err == nil && !bytes.Equal(password, confirmation) {
+			err = ErrPasswordMismatch
+		}
+		if err != nil {
+			clear(password)
+			password = nil
+		}
+		done <- result{password: password, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.password, r.err
+	case <-ctx.Done():
+		// closing the terminal unblocks the pending read
+		_ = restore()
+		_ = tty.Close()
+		r := <-done
+		clear(r.password)
+		return nil, context.Cause(ctx)
+	}
+}
+
+// promptLine writes message to the terminal and reads a line of input.
+// The line terminator is not part of the returned input.
+func promptLine(tty terminal, message string) ([]byte, error) {
+	if _, err := io.WriteString(tty, message+" "); err != nil {
+		return nil, err
+	}
+	line, err := readLine(tty)
+	// the newline typed by the user was not echoed
+	_, _ = io.WriteString(tty, "\n")
+	return line, err
+}
+
+// readLine reads from r up to a newline one byte at a time, so that nothing
+// past the line is consumed.
+func readLine(r io.Reader) ([]byte, error) {
+	var line []byte
+	var b [1]byte
+	for {
+		n, err := r.Read(b[:])
+		if n > 0 {
+			if b[0] == '\n' {
+				return bytes.TrimSuffix(line, []byte{'\r'}), nil
+			}
+			line = append(line, b[0])
+		}
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			clear(line)
+			return nil, err
+		}
+	}
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
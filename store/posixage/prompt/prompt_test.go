@@ -0,0 +1,124 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTerminal struct {
+	io.Reader
+	out      bytes.Buffer
+	echo     bool
+	closed   bool
+	closeErr func() error
+}
+
+func (f *fakeTerminal) Write(p []byte) (int, error) {
+	return f.out.Write(p)
+}
+
+func (f *fakeTerminal) Close() error {
+	f.closed = true
+	if f.closeErr != nil {
+		return f.closeErr()
+	}
+	return nil
+}
+
+func (f *fakeTerminal) disableEcho() (func() error, error) {
+	f.echo = false
+	return func() error {
+		f.echo = true
+		return nil
+	}, nil
+}
+
+func useTerminal(t *testing.T, tty terminal, err error) {
+	t.Helper()
+	openTerminal = func() (terminal, error) {
+		return tty, err
+	}
+	t.Cleanup(func() { openTerminal = openTTY })
+}
+
+func TestDecryptionPassword(t *testing.T) {
+	tty := &fakeTerminal{Reader: strings.NewReader("s3cret\r\nleftover"), echo: true}
+	useTerminal(t, tty, nil)
+
+	password, err := DecryptionPassword("Passphrase:")(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(password))
+	assert.Equal(t, "Passphrase: \n", tty.out.String())
+	assert.True(t, tty.echo, "echo must be restored")
+	assert.True(t, tty.closed)
+}
+
+func TestPassword(t *testing.T) {
+	t.Run("confirmed", func(t *testing.T) {
+		tty := &fakeTerminal{Reader: strings.NewReader("s3cret\ns3cret\n")}
+		useTerminal(t, tty, nil)
+
+		password, err := Password("Passphrase:")(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", string(password))
+		assert.Equal(t, "Passphrase: \nConfirm passphrase: \n", tty.out.String())
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		useTerminal(t, &fakeTerminal{Reader: strings.NewReader("s3cret\nother\n")}, nil)
+
+		password, err := Password("Passphrase:")(t.Context())
+		assert.ErrorIs(t, err, ErrPasswordMismatch)
+		assert.Nil(t, password)
+	})
+}
+
+func TestNoTTY(t *testing.T) {
+	useTerminal(t, nil, errors.New("open /dev/tty: no such device or address"))
+
+	_, err := DecryptionPassword("Passphrase:")(t.Context())
+	assert.ErrorIs(t, err, ErrNoTTY)
+}
+
+func TestPromptCanceled(t *testing.T) {
+	r, w := io.Pipe()
+	t.Cleanup(func() { _ = w.Close() })
+	tty := &fakeTerminal{Reader: r, closeErr: r.Close}
+	useTerminal(t, tty, nil)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	_, err := DecryptionPassword("Passphrase:")(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, tty.closed)
+}
+
+func TestReadLine(t *testing.T) {
+	line, err := readLine(strings.NewReader("no newline"))
+	require.NoError(t, err)
+	assert.Equal(t, "no newline", string(line))
+
+	_, err = readLine(strings.NewReader(""))
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompt
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal, returning the terminal side of it. The
+// files stay in non-blocking mode, like the one opened by [openTTY].
+func openPTY(t *testing.T) *os.File {
+	t.Helper()
+	ptm, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminal available: %s", err)
+	}
+	t.Cleanup(func() { _ = ptm.Close() })
+
+	conn, err := ptm.SyscallConn()
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, control(conn, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return err
+		}
+		n, err = unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		return err
+	}))
+
+	pts, err := os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminal available: %s", err)
+	}
+	t.Cleanup(func() { _ = pts.Close() })
+	return pts
+}
+
+func echoEnabled(t *testing.T, f *os.File) bool {
+	t.Helper()
+	conn, err := f.SyscallConn()
+	require.NoError(t, err)
+	var state *unix.Termios
+	require.NoError(t, control(conn, func(fd int) error {
+		state, err = unix.IoctlGetTermios(fd, ioctlReadTermios)
+		return err
+	}))
+	return state.Lflag&unix.ECHO != 0
+}
+
+func TestUnixTerminal(t *testing.T) {
+	t.Run("disables echo and restores it", func(t *testing.T) {
+		pts := openPTY(t)
+		require.True(t, echoEnabled(t, pts))
+
+		restore, err := (&unixTerminal{File: pts}).disableEcho()
+		require.NoError(t, err)
+		assert.False(t, echoEnabled(t, pts))
+		require.NoError(t, restore())
+		assert.True(t, echoEnabled(t, pts))
+	})
+	t.Run("canceling interrupts the pending read", func(t *testing.T) {
+		useTerminal(t, &unixTerminal{File: openPTY(t)}, nil)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		done := make(chan error, 1)
+		go func() {
+			_, err := DecryptionPassword("Passphrase:")(ctx)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the read was not interrupted")
+		}
+	})
+}
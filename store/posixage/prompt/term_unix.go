@@ -0,0 +1,78 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package prompt
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+type unixTerminal struct {
+	*os.File
+}
+
+func openTTY() (terminal, error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &unixTerminal{File: f}, nil
+}
+
+// disableEcho sets the terminal attributes through [os.File.SyscallConn]:
+// [os.File.Fd] would switch the file to blocking mode, and closing it could
+// no longer interrupt a pending read.
+func (t *unixTerminal) disableEcho() (func() error, error) {
+	conn, err := t.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var state *unix.Termios
+	err = control(conn, func(fd int) error {
+		var err error
+		state, err = unix.IoctlGetTermios(fd, ioctlReadTermios)
+		if err != nil {
+			return err
+		}
+		noEcho := *state
+		noEcho.Lflag &^= unix.ECHO
+		noEcho.Lflag |= unix.ICANON | unix.ISIG
+		noEcho.Iflag |= unix.ICRNL
+		return unix.IoctlSetTermios(fd, ioctlWriteTermios, &noEcho)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return control(conn, func(fd int) error {
+			return unix.IoctlSetTermios(fd, ioctlWriteTermios, state)
+		})
+	}, nil
+}
+
+// control runs fn with the file descriptor of conn.
+func control(conn syscall.RawConn, fn func(fd int) error) error {
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) {
+		fnErr = fn(int(fd))
+	}); err != nil {
+		return err
+	}
+	return fnErr
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompt
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+type windowsTerminal struct {
+	in  *os.File
+	out *os.File
+}
+
+func openTTY() (terminal, error) {
+	in, err := os.OpenFile("CONIN$", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	out, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+	if err != nil {
+		_ = in.Close()
+		return nil, err
+	}
+	return &windowsTerminal{in: in, out: out}, nil
+}
+
+func (t *windowsTerminal) Read(p []byte) (int, error) {
+	return t.in.Read(p)
+}
+
+func (t *windowsTerminal) Write(p []byte) (int, error) {
+	return t.out.Write(p)
+}
+
+func (t *windowsTerminal) Close() error {
+	return errors.Join(t.in.Close(), t.out.Close())
+}
+
+func (t *windowsTerminal) disableEcho() (func() error, error) {
+	handle := windows.Handle(t.in.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return nil, err
+	}
+	noEcho := mode&^windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT
+	if err := windows.SetConsoleMode(handle, noEcho); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return windows.SetConsoleMode(handle, mode)
+	}, nil
+}
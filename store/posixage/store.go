@@ -29,12 +29,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/fs"
 	"os"
+	"slices"
 	"sync"
+	"time"
 
 	"filippo.io/age"
+	"filippo.io/age/plugin"
 
 	"github.com/docker/secrets-engine/store"
 	"github.com/docker/secrets-engine/store/posixage/internal/flock"
@@ -46,13 +50,46 @@ type fileStore[T store.Secret] struct {
 	filesystem *os.Root
 	factory    store.Factory[T]
 	l          sync.RWMutex
+	stripes    [lockStripes]sync.RWMutex
 	*config
 }
 
 var _ store.Store = &fileStore[store.Secret]{}
+var _ store.Lister = &fileStore[store.Secret]{}
+var _ store.AbsentSaver = &fileStore[store.Secret]{}
+
+// ErrIncompleteSecret is returned when reading a secret that was not fully
+// written, e.g. because the process saving it crashed. Listing operations
+// skip such secrets.
+var ErrIncompleteSecret = secretfile.ErrIncompleteSecret
+
+// lockStripes is the number of locks the secrets of a store are spread
+// over, see [fileStore.tryLockID].
+const lockStripes = 64
+
+// storeLockFileName is the lock file of the whole store, under the store
+// root. Older versions of the store locked it through ".posixage.lock" and
+// held it exclusively for every write: the name changed along with the
+// protocol, so that a holder of the old lock is never mistaken for a writer
+// of a single secret.
+const storeLockFileName = ".posixage-v2.lock"
+
+// stripeLockFileName returns the name of the lock file of the stripe i, under
+// the store root.
+func stripeLockFileName(i int) string {
+	return fmt.Sprintf(".posixage-%02d.lock", i)
+}
+
+// stripe returns the stripe of the lock of id.
+func stripe(id store.ID) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id.String()))
+	return int(h.Sum32() % lockStripes)
+}
 
 // tryLock is an internal convenience function for acquiring an exclusive
-// store lock.
+// store lock. Operations traversing the store hold it, which excludes any
+// other operation.
 //
 // It first attempts to acquire a lock using [sync.RWMutex], then applies
 // a file-based lock for process-level coordination. This helps applications
@@ -63,7 +100,7 @@ var _ store.Store = &fileStore[store.Secret]{}
 func (f *fileStore[T]) tryLock(ctx context.Context) (func(), error) {
 	f.l.Lock()
 
-	unlock, err := flock.TryLock(logging.WithLogger(ctx, f.logger), f.filesystem)
+	unlock, err := flock.TryLockFile(logging.WithLogger(ctx, f.logger), f.filesystem, storeLockFileName)
 	if err != nil {
 		f.l.Unlock()
 		return nil, err
@@ -78,7 +115,8 @@ func (f *fileStore[T]) tryLock(ctx context.Context) (func(), error) {
 }
 
 // tryRLock is an internal convenience function for acquiring a non-exclusive
-// store lock.
+// store lock. Operations on a single secret hold it, reading or writing, see
+// [fileStore.tryLockID].
 //
 // It first attempts to acquire a lock using [sync.RWMutex], then applies
 // a file-based lock for process-level coordination. This helps applications
@@ -89,7 +127,7 @@ func (f *fileStore[T]) tryLock(ctx context.Context) (func(), error) {
 func (f *fileStore[T]) tryRLock(ctx context.Context) (func(), error) {
 	f.l.RLock()
 
-	unlock, err := flock.TryRLock(logging.WithLogger(ctx, f.logger), f.filesystem)
+	unlock, err := flock.TryRLockFile(logging.WithLogger(ctx, f.logger), f.filesystem, storeLockFileName)
 	if err != nil {
 		f.l.RUnlock()
 		return nil, err
@@ -103,6 +141,58 @@ func (f *fileStore[T]) tryRLock(ctx context.Context) (func(), error) {
 	}), nil
 }
 
+// tryLockID acquires the non-exclusive store lock and the lock of the single
+// secret id, exclusive when writing it. Writes to secrets of different
+// stripes run concurrently, while operations traversing the store exclude
+// them all. Locks are always acquired store first.
+//
+// It returns an unlock function that must be called to release the lock.
+func (f *fileStore[T]) tryLockID(ctx context.Context, id store.ID, exclusive bool) (func(), error) {
+	unlockStore, err := f.tryRLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unlockStripe, err := f.lockStripe(ctx, id, exclusive)
+	if err != nil {
+		unlockStore()
+		return nil, err
+	}
+	return func() {
+		defer unlockStore()
+		unlockStripe()
+	}, nil
+}
+
+// lockStripe acquires the lock of the stripe of id, exclusive when writing
+// the secret. The non-exclusive store lock must already be held.
+//
+// Secrets are spread over [lockStripes] locks by ID, each made of a
+// [sync.RWMutex] and a lock file.
+//
+// It returns an unlock function that must be called to release the lock.
+func (f *fileStore[T]) lockStripe(ctx context.Context, id store.ID, exclusive bool) (func(), error) {
+	i := stripe(id)
+	lock, lockFile := f.stripes[i].RLock, flock.TryRLockFile
+	release := f.stripes[i].RUnlock
+	if exclusive {
+		lock, lockFile = f.stripes[i].Lock, flock.TryLockFile
+		release = f.stripes[i].Unlock
+	}
+	lock()
+	unlock, err := lockFile(logging.WithLogger(ctx, f.logger), f.filesystem, stripeLockFileName(i))
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return sync.OnceFunc(func() {
+		defer release()
+		if err := unlock(); err != nil {
+			f.logger.Errorf("%s", err)
+		}
+	}), nil
+}
+
 // decryptSecret attempts to decrypt a secret using the registered
 // [promptCaller] functions.
 //
@@ -115,8 +205,15 @@ func (f *fileStore[T]) tryRLock(ctx context.Context) (func(), error) {
 // The first successful decryption returns the plaintext secret. If no
 // matching secret file is found for a registered key type, or if all
 // decryption attempts fail, an error is returned.
+//
+// Decryption functions of the preferred key type, the one the secret was
+// saved with, are tried first, see [secretfile.EncryptedSecret.Preferred].
 func (f *fileStore[T]) decryptSecret(ctx context.Context, encryptedSecrets []secretfile.EncryptedSecret) ([]byte, error) {
-	for _, prompt := range f.registeredDecryptionFunc {
+	prompts, err := preferredFirst(f.registeredDecryptionFunc, encryptedSecrets)
+	if err != nil {
+		return nil, err
+	}
+	for _, prompt := range prompts {
 		keyType, err := getPromptCallerKeyType(prompt)
 		if err != nil {
 			return nil, err
@@ -134,28 +231,92 @@ func (f *fileStore[T]) decryptSecret(ctx context.Context, encryptedSecrets []sec
 			return nil, fmt.Errorf("decryption function of type %s was specified, but the file was never encrypted with this type", keyType)
 		}
 
-		decryptionKey, err := prompt.call(ctx)
+		decryptionKey, err := callPrompt(ctx, prompt, f.promptTimeout)
 		if err != nil {
 			return nil, err
 		}
 
 		plaintext, err := f.tryDecrypt(keyType, decryptionKey, encryptedSecrets[index].EncryptedData)
+		// a hardware key may not be plugged in on every machine
+		if notFound := (*plugin.NotFoundError)(nil); errors.As(err, &notFound) {
+			f.logger.Warnf("age plugin %s is not available, trying the next decryption key", notFound.Name)
+			continue
+		}
 		if err != nil {
 			f.logger.Errorf("failed to decrypt secret of type :%s", keyType)
 			continue
 		}
-		return plaintext, nil
+
+		compression := encryptedSecrets[index].Compression
+		if compression == secretfile.NoCompression {
+			return plaintext, nil
+		}
+		decompressed, err := secretfile.Decompress(compression, plaintext)
+		clear(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing secret of type %s: %w", keyType, err)
+		}
+		return decompressed, nil
 	}
 
 	return nil, errors.New("could not decrypt secret with provided decryption keys")
 }
 
+// preferredFirst returns the prompts with the ones matching the key type of the
+// preferred encrypted secret moved to the front, keeping their relative order.
+func preferredFirst(prompts []promptCaller, encryptedSecrets []secretfile.EncryptedSecret) ([]promptCaller, error) {
+	i := slices.IndexFunc(encryptedSecrets, func(s secretfile.EncryptedSecret) bool {
+		return s.Preferred
+	})
+	if i == -1 {
+		return prompts, nil
+	}
+	preferred := encryptedSecrets[i].KeyType
+
+	ordered := make([]promptCaller, 0, len(prompts))
+	var others []promptCaller
+	for _, prompt := range prompts {
+		keyType, err := getPromptCallerKeyType(prompt)
+		if err != nil {
+			return nil, err
+		}
+		if keyType == preferred {
+			ordered = append(ordered, prompt)
+		} else {
+			others = append(others, prompt)
+		}
+	}
+	return append(ordered, others...), nil
+}
+
+// preferredKeyType returns the key type the saving user most likely decrypts
+// with: the type of the first registered decryption function the secret is
+// encrypted for, or else the type of the first registered encryption function.
+func (f *fileStore[T]) preferredKeyType(keyGroups map[secretfile.KeyType][]string) secretfile.KeyType {
+	for _, prompt := range f.registeredDecryptionFunc {
+		keyType, err := getPromptCallerKeyType(prompt)
+		if err != nil {
+			continue
+		}
+		if _, ok := keyGroups[keyType]; ok {
+			return keyType
+		}
+	}
+	for _, prompt := range f.registeredEncryptionFuncs {
+		keyType, err := getPromptCallerKeyType(prompt)
+		if err == nil {
+			return keyType
+		}
+	}
+	return ""
+}
+
 // tryDecrypt uses decryptionKey to decrypt encryptedData, zeroing the key after
 // use regardless of outcome.
 func (f *fileStore[T]) tryDecrypt(keyType secretfile.KeyType, decryptionKey, encryptedData []byte) ([]byte, error) {
 	defer clear(decryptionKey)
 
-	identity, err := secretfile.GetIdentity(keyType, string(decryptionKey))
+	identity, err := secretfile.GetIdentity(keyType, string(decryptionKey), f.pluginUI())
 	if err != nil {
 		return nil, err
 	}
@@ -168,8 +329,32 @@ func (f *fileStore[T]) tryDecrypt(keyType secretfile.KeyType, decryptionKey, enc
 	return io.ReadAll(r)
 }
 
+// pluginUI logs the messages of age plugins. Plugins requesting a value or a
+// confirmation fail, since the store cannot interact with the user other
+// than through the registered callbacks.
+func (f *fileStore[T]) pluginUI() *plugin.ClientUI {
+	return &plugin.ClientUI{
+		DisplayMessage: func(name, message string) error {
+			f.logger.Printf("age plugin %s: %s", name, message)
+			return nil
+		},
+		WaitTimer: func(name string) {
+			f.logger.Printf("waiting on age plugin %s, e.g. for a hardware key touch", name)
+		},
+	}
+}
+
+// Close closes the root directory if the store owns it, see
+// [WithRootOwnership].
+func (f *fileStore[T]) Close() error {
+	if !f.ownsRoot {
+		return nil
+	}
+	return f.filesystem.Close()
+}
+
 func (f *fileStore[T]) Delete(ctx context.Context, id store.ID) error {
-	unlock, err := f.tryLock(ctx)
+	unlock, err := f.tryLockID(ctx, id, true)
 	if err != nil {
 		return err
 	}
@@ -179,7 +364,7 @@ func (f *fileStore[T]) Delete(ctx context.Context, id store.ID) error {
 }
 
 func (f *fileStore[T]) Filter(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
-	unlock, err := f.tryRLock(ctx)
+	unlock, err := f.tryLock(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +376,11 @@ func (f *fileStore[T]) Filter(ctx context.Context, pattern store.Pattern) (map[s
 		if !d.IsDir() || d.Name() == "." {
 			return nil
 		}
+		// directories staging a batch or quarantined secrets do not hold
+		// secrets
+		if internalDir(d.Name()) {
+			return fs.SkipDir
+		}
 
 		id, err := secretfile.DirNameToID(d.Name())
 		// we want to continue to the next directory
@@ -247,7 +437,7 @@ func (f *fileStore[T]) Filter(ctx context.Context, pattern store.Pattern) (map[s
 }
 
 func (f *fileStore[T]) Get(ctx context.Context, id store.ID) (store.Secret, error) {
-	unlock, err := f.tryRLock(ctx)
+	unlock, err := f.tryLockID(ctx, id, false)
 	if err != nil {
 		return nil, err
 	}
@@ -257,7 +447,12 @@ func (f *fileStore[T]) Get(ctx context.Context, id store.ID) (store.Secret, erro
 	if err != nil {
 		return nil, err
 	}
+	return f.newSecret(ctx, id, encryptedSecrets, metadata)
+}
 
+// newSecret decrypts the encrypted secrets and returns the secret of id they
+// hold, with metadata.
+func (f *fileStore[T]) newSecret(ctx context.Context, id store.ID, encryptedSecrets []secretfile.EncryptedSecret, metadata map[string]string) (store.Secret, error) {
 	decryptedSecret, err := f.decryptSecret(ctx, encryptedSecrets)
 	if err != nil {
 		return nil, err
@@ -274,8 +469,16 @@ func (f *fileStore[T]) Get(ctx context.Context, id store.ID) (store.Secret, erro
 	return secret, nil
 }
 
+func (f *fileStore[T]) GetAll(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return f.Filter(ctx, store.MustParsePattern("**"))
+}
+
 func (f *fileStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.Secret, error) {
-	unlock, err := f.tryRLock(ctx)
+	return f.FilterMetadata(ctx, store.MustParsePattern("**"))
+}
+
+func (f *fileStore[T]) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	unlock, err := f.tryLock(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -287,6 +490,11 @@ func (f *fileStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.S
 		if !d.IsDir() || d.Name() == "." {
 			return nil
 		}
+		// directories staging a batch or quarantined secrets do not hold
+		// secrets
+		if internalDir(d.Name()) {
+			return fs.SkipDir
+		}
 
 		id, err := secretfile.DirNameToID(d.Name())
 		// just continue to the next item, we don't want to stop because
@@ -296,6 +504,10 @@ func (f *fileStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.S
 			return fs.SkipDir
 		}
 
+		if !pattern.Match(id) {
+			return fs.SkipDir
+		}
+
 		secretDir, err := f.filesystem.OpenRoot(d.Name())
 		if err != nil {
 			return err
@@ -305,6 +517,10 @@ func (f *fileStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.S
 		}()
 
 		metadata, err := secretfile.RestoreMetadata(secretDir)
+		if errors.Is(err, ErrIncompleteSecret) {
+			f.logger.Warnf("skipping incomplete secret %s: %s", id, err)
+			return fs.SkipDir
+		}
 		if err != nil {
 			return err
 		}
@@ -329,61 +545,222 @@ func (f *fileStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.S
 	return secrets, nil
 }
 
-func (f *fileStore[T]) Save(ctx context.Context, id store.ID, s store.Secret) error {
+// List pages through the secret directories in lexical order of their
+// names, using the last directory name as the cursor. Only the metadata of
+// the secrets on the returned page is read.
+func (f *fileStore[T]) List(ctx context.Context, pattern store.Pattern, opts store.ListOptions) (store.ListResult, error) {
 	unlock, err := f.tryLock(ctx)
+	if err != nil {
+		return store.ListResult{}, err
+	}
+	defer unlock()
+
+	// fs.ReadDir returns the entries sorted by filename
+	entries, err := fs.ReadDir(f.filesystem.FS(), ".")
+	if err != nil {
+		return store.ListResult{}, err
+	}
+
+	dirNames := map[store.ID]string{}
+	var items []store.ListItem
+	for _, d := range entries {
+		if !d.IsDir() || internalDir(d.Name()) {
+			continue
+		}
+		if opts.Cursor != "" && d.Name() <= opts.Cursor {
+			continue
+		}
+		id, err := secretfile.DirNameToID(d.Name())
+		if err != nil {
+			f.logger.Warnf("could not parse directory name (%s) to secret ID: %s", d.Name(), err)
+			continue
+		}
+		if !pattern.Match(id) {
+			continue
+		}
+		dirNames[id] = d.Name()
+		items = append(items, store.ListItem{ID: id})
+	}
+
+	result := store.Page(items, opts.Limit, func(item store.ListItem) string {
+		return dirNames[item.ID]
+	})
+	complete := result.Items[:0]
+	for _, item := range result.Items {
+		secret, err := f.restoreMetadata(ctx, item.ID, dirNames[item.ID])
+		// an incomplete secret leaves the page shorter, the cursor is still
+		// valid for the next one
+		if errors.Is(err, ErrIncompleteSecret) {
+			f.logger.Warnf("skipping incomplete secret %s: %s", item.ID, err)
+			continue
+		}
+		if err != nil {
+			return store.ListResult{}, err
+		}
+		item.Secret = secret
+		complete = append(complete, item)
+	}
+	result.Items = complete
+	return result, nil
+}
+
+// restoreMetadata reads the metadata of the secret id stored in dirName. The
+// exclusive store lock must be held.
+func (f *fileStore[T]) restoreMetadata(ctx context.Context, id store.ID, dirName string) (store.Secret, error) {
+	secretDir, err := f.filesystem.OpenRoot(dirName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = secretDir.Close()
+	}()
+
+	metadata, err := secretfile.RestoreMetadata(secretDir)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := f.factory(ctx, id)
+	if err := secret.SetMetadata(metadata); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (f *fileStore[T]) Save(ctx context.Context, id store.ID, s store.Secret) error {
+	metadata, secrets, err := f.encryptSecret(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := f.tryLockID(ctx, id, true)
 	if err != nil {
 		return err
 	}
 	defer unlock()
 
+	return f.persist(id, metadata, secrets)
+}
+
+// SaveIfAbsent implements [store.AbsentSaver]. The secret directory is checked
+// and written while holding the lock of the secret, so concurrent saves from
+// other processes cannot interleave.
+//
+// The check also happens before prompting for the encryption keys, so that
+// the user is not prompted for a secret that already exists.
+func (f *fileStore[T]) SaveIfAbsent(ctx context.Context, id store.ID, s store.Secret) error {
+	if err := f.checkAbsent(id); err != nil {
+		return err
+	}
+	metadata, secrets, err := f.encryptSecret(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := f.tryLockID(ctx, id, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := f.checkAbsent(id); err != nil {
+		return err
+	}
+	return f.persist(id, metadata, secrets)
+}
+
+// checkAbsent returns [store.ErrCredentialExists] if a secret directory
+// exists for id.
+func (f *fileStore[T]) checkAbsent(id store.ID) error {
+	_, err := f.filesystem.Lstat(secretfile.IDToDirName(id))
+	if err == nil {
+		return store.ErrCredentialExists
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// encryptSecret validates s and encrypts it with the keys returned by the
+// registered encryption functions, grouped by key type.
+func (f *fileStore[T]) encryptSecret(ctx context.Context, s store.Secret) (map[string]string, []secretfile.EncryptedSecret, error) {
+	if err := store.Validate(s); err != nil {
+		return nil, nil, err
+	}
+
 	// we need to get the encryption keys from the caller, this is a blocking
 	// call and we must wait for the caller to cancel the ctx or wait for the
 	// user to complete the interaction.
-	keyGroups, err := promptForEncryptionKeys(ctx, f.registeredEncryptionFuncs)
+	// Gathering the keys and encrypting the secret does not depend on what is
+	// on disk, so it happens before taking the lock to not block other
+	// readers and writers while the user is prompted.
+	keyGroups, err := promptForEncryptionKeys(ctx, f.registeredEncryptionFuncs, f.promptTimeout)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	secret, err := s.Marshal()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer clear(secret)
 	metadata := s.Metadata()
 
+	compression := secretfile.NoCompression
+	if f.compress {
+		compressed, err := secretfile.Compress(secret, f.compressionLevel)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer clear(compressed)
+		secret = compressed
+		compression = secretfile.GzipCompression
+	}
+
+	// a hint only matters when there is more than one key type to choose from
+	var preferred secretfile.KeyType
+	if len(keyGroups) > 1 {
+		preferred = f.preferredKeyType(keyGroups)
+	}
 	var secrets []secretfile.EncryptedSecret
 	// Encryption keys must be grouped by type. The age library does not
 	// support mixing different key types in a single encryption operation
 	// (e.g., age + password). However, multiple keys of the same type are
 	// allowed (e.g., password + password).
 	for k, encryptionKeys := range keyGroups {
-		recipients, err := secretfile.GetRecipients(k, encryptionKeys, secretfile.WithScryptWorkFactor(f.scryptWorkFactor))
+		recipients, err := secretfile.GetRecipients(k, encryptionKeys,
+			secretfile.WithScryptWorkFactor(f.scryptWorkFactor),
+			secretfile.WithPluginUI(f.pluginUI()),
+		)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		var encryptedSecret bytes.Buffer
 		w, err := age.Encrypt(&encryptedSecret, recipients...)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		if _, err := w.Write(secret); err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		// Finalize encryption and flush all data into the buffer.
 		if err := w.Close(); err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		secrets = append(secrets, secretfile.EncryptedSecret{
 			KeyType:       k,
 			EncryptedData: encryptedSecret.Bytes(),
+			Compression:   compression,
+			Preferred:     k == preferred,
 		})
 	}
 
-	return secretfile.Persist(id, f.filesystem, metadata, secrets)
+	return metadata, secrets, nil
 }
 
 func (f *fileStore[T]) Upsert(ctx context.Context, id store.ID, s store.Secret) error {
@@ -397,6 +774,18 @@ type config struct {
 	// scryptWorkFactor is the scrypt work factor (2^logN) applied to
 	// password-protected secrets. A zero value uses the age default.
 	scryptWorkFactor int
+	// promptTimeout bounds each encryption and decryption callback.
+	// A zero value means no timeout.
+	promptTimeout time.Duration
+	// ownsRoot makes Close close the root directory given to New.
+	ownsRoot bool
+	// compress enables gzip compression of secrets at compressionLevel
+	// before they are encrypted.
+	compress         bool
+	compressionLevel int
+	// versionHistory is how many previous versions of a secret are kept
+	// when it gets overwritten. Zero keeps none.
+	versionHistory int
 }
 
 type Options func(c *config) error
@@ -472,8 +861,78 @@ func WithScryptWorkFactor(logN int) Options {
 	}
 }
 
+// WithPromptTimeout bounds how long each registered encryption and decryption
+// callback may take to return a key.
+//
+// Callbacks usually prompt the user, and decryption callbacks are invoked
+// while the store is locked. When a callback does not return in time, the
+// operation fails with an error wrapping [context.DeadlineExceeded] and any
+// lock is released, even if the callback itself does not honor its context.
+//
+// A timeout of 0 means no timeout, which is the default.
+// Negative durations are not allowed and will result in an error.
+func WithPromptTimeout(timeout time.Duration) Options {
+	return func(c *config) error {
+		if timeout < 0 {
+			return errors.New("prompt timeout duration cannot be negative")
+		}
+		c.promptTimeout = timeout
+		return nil
+	}
+}
+
+// WithCompression compresses secrets with gzip at the given level before
+// they are encrypted, which reduces the size of large secrets on disk.
+//
+// The level follows [compress/gzip], i.e. [compress/gzip.HuffmanOnly] to
+// [compress/gzip.BestCompression], with [compress/gzip.DefaultCompression]
+// picking a balanced default. Metadata is never compressed.
+//
+// The compression is recorded in each secret file, so secrets written without
+// compression remain readable and stores without this option can still read
+// compressed secrets.
+func WithCompression(level int) Options {
+	return func(c *config) error {
+		if err := secretfile.ValidateCompressionLevel(level); err != nil {
+			return err
+		}
+		c.compress = true
+		c.compressionLevel = level
+		return nil
+	}
+}
+
+// WithVersionHistory keeps up to n previous versions of a secret when it gets
+// overwritten, so that it can be rolled back, see [store.ListVersions] and
+// [store.GetVersion]. The oldest versions are pruned first.
+//
+// Previous versions are kept, encrypted as they were, in the directory of
+// the secret and are deleted along with it. The default of 0 keeps no
+// previous version, a save overwrites the secret.
+func WithVersionHistory(n int) Options {
+	return func(c *config) error {
+		if n < 0 {
+			return fmt.Errorf("version history cannot be negative: %d", n)
+		}
+		c.versionHistory = n
+		return nil
+	}
+}
+
+// WithRootOwnership hands ownership of the root directory given to [New] over
+// to the store, so that it gets closed by [store.Store.Close].
+//
+// By default the caller that opened the root remains responsible for closing
+// it, and Close does not release anything.
+func WithRootOwnership() Options {
+	return func(c *config) error {
+		c.ownsRoot = true
+		return nil
+	}
+}
+
 type encryptionFuncs interface {
-	EncryptionPassword | EncryptionSSH | EncryptionAgeX25519
+	EncryptionPassword | EncryptionSSH | EncryptionAgeX25519 | EncryptionAgePlugin
 }
 
 // WithEncryptionCallbackFunc registers a callback used to prompt the user
@@ -489,7 +948,7 @@ func WithEncryptionCallbackFunc[K encryptionFuncs](callback K) Options {
 }
 
 type decryptionFuncs interface {
-	DecryptionPassword | DecryptionSSH | DecryptionAgeX25519
+	DecryptionPassword | DecryptionSSH | DecryptionAgeX25519 | DecryptionAgePlugin
 }
 
 // WithDecryptionCallbackFunc registers a callback used to prompt the user
@@ -15,6 +15,8 @@
 package posixage
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
@@ -24,12 +26,19 @@ import (
 	"encoding/pem"
 	"errors"
 	"io/fs"
+	"maps"
 	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"filippo.io/age"
+	"filippo.io/age/plugin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -245,6 +254,13 @@ func TestPOSIXAge(t *testing.T) {
 			assert.EqualValues(t, &mocks.MockCredential{Attributes: secret.Attributes}, storeSecrets[id])
 			assert.EqualValues(t, secret.Metadata(), storeSecrets[id].Metadata())
 		}
+
+		withValues, err := s.GetAll(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, withValues, 2)
+		for id, secret := range secrets {
+			assert.EqualValues(t, secret, withValues[id])
+		}
 	})
 
 	t.Run("can filter secrets", func(t *testing.T) {
@@ -305,6 +321,47 @@ func TestPOSIXAge(t *testing.T) {
 		assert.EqualValues(t, secrets[secretOne], storeSecrets[secretOne])
 	})
 
+	t.Run("can filter secret metadata without decrypting", func(t *testing.T) {
+		root, err := os.OpenRoot(t.TempDir())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, root.Close())
+		})
+
+		masterKey := uuid.NewString()
+		s, err := New(root,
+			func(_ context.Context, _ store.ID) *mocks.MockCredential {
+				return &mocks.MockCredential{}
+			},
+			WithLogger(&testLogger{t}),
+			WithDecryptionCallbackFunc[DecryptionPassword](func(_ context.Context) ([]byte, error) {
+				return nil, errors.New("decryption must not be called")
+			}),
+			WithEncryptionCallbackFunc[EncryptionPassword](func(_ context.Context) ([]byte, error) {
+				return []byte(masterKey), nil
+			}),
+		)
+		require.NoError(t, err)
+
+		secretOne := store.MustParseID("something/secret1/" + uuid.NewString())
+		secretTwo := store.MustParseID("something2/secret2/" + uuid.NewString())
+		for _, id := range []store.ID{secretOne, secretTwo} {
+			require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{
+				Username:   uuid.NewString(),
+				Password:   uuid.NewString(),
+				Attributes: map[string]string{"id": id.String()},
+			}))
+		}
+
+		storeSecrets, err := s.FilterMetadata(t.Context(), store.MustParsePattern("something/**"))
+		require.NoError(t, err)
+		assert.Len(t, storeSecrets, 1)
+		assert.EqualValues(t, &mocks.MockCredential{Attributes: map[string]string{"id": secretOne.String()}}, storeSecrets[secretOne])
+
+		_, err = s.FilterMetadata(t.Context(), store.MustParsePattern("unknown/**"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+
 	t.Run("can use multiple keys to encrypt and decrypt", func(t *testing.T) {
 		root, err := os.OpenRoot(t.TempDir())
 		require.NoError(t, err)
@@ -440,7 +497,8 @@ func TestPOSIXAge(t *testing.T) {
 		require.NoError(t, err)
 		secretFiles, err := fs.ReadDir(secretRoot.FS(), ".")
 		require.NoError(t, err)
-		assert.Len(t, secretFiles, 3)
+		// metadata, one file per key type and the preferred key type hint
+		assert.Len(t, secretFiles, 4)
 
 		x := s.(*fileStore[*mocks.MockCredential])
 		x.registeredEncryptionFuncs = []promptCaller{
@@ -1002,3 +1060,1075 @@ func TestScryptWorkFactorMigration(t *testing.T) {
 		})
 	}
 }
+
+func TestPromptTimeout(t *testing.T) {
+	newStore := func(t *testing.T, root *os.Root, encrypt EncryptionPassword, decrypt DecryptionPassword) store.Store {
+		t.Helper()
+		s, err := New(root,
+			func(_ context.Context, _ store.ID) *mocks.MockCredential {
+				return &mocks.MockCredential{}
+			},
+			WithLogger(&testLogger{t}),
+			WithScryptWorkFactor(10),
+			WithPromptTimeout(50*time.Millisecond),
+			WithEncryptionCallbackFunc(encrypt),
+			WithDecryptionCallbackFunc(decrypt),
+		)
+		require.NoError(t, err)
+		return s
+	}
+	password := func(context.Context) ([]byte, error) {
+		return []byte("a-password"), nil
+	}
+	// blocking ignores its context, like a callback stuck reading stdin.
+	blocked := make(chan struct{})
+	t.Cleanup(func() { close(blocked) })
+	blocking := func(context.Context) ([]byte, error) {
+		<-blocked
+		return []byte("a-password"), nil
+	}
+
+	t.Run("save times out and releases the lock", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newStore(t, root, blocking, password)
+		id := secrets.MustParseID("test/" + uuid.NewString())
+
+		err := s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pass"})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// the lock was released, so a writer that does not block can proceed
+		writer := newStore(t, root, password, password)
+		require.NoError(t, writer.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pass"}))
+	})
+
+	t.Run("get times out and releases the lock", func(t *testing.T) {
+		root := newTempRoot(t)
+		id := secrets.MustParseID("test/" + uuid.NewString())
+		writer := newStore(t, root, password, password)
+		require.NoError(t, writer.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pass"}))
+
+		s := newStore(t, root, password, blocking)
+		_, err := s.Get(t.Context(), id)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		require.NoError(t, s.Delete(t.Context(), id))
+	})
+
+	t.Run("rejects negative timeouts", func(t *testing.T) {
+		_, err := New(newTempRoot(t),
+			func(_ context.Context, _ store.ID) *mocks.MockCredential {
+				return &mocks.MockCredential{}
+			},
+			WithEncryptionCallbackFunc[EncryptionPassword](password),
+			WithDecryptionCallbackFunc[DecryptionPassword](password),
+			WithPromptTimeout(-time.Second),
+		)
+		assert.Error(t, err)
+	})
+}
+
+// TestSavePromptsBeforeLocking asserts that a slow encryption callback does
+// not block readers, as Save only takes the lock to write the encrypted files.
+func TestSavePromptsBeforeLocking(t *testing.T) {
+	root := newTempRoot(t)
+	password := func(context.Context) ([]byte, error) {
+		return []byte("a-password"), nil
+	}
+
+	prompting := make(chan struct{})
+	release := make(chan struct{})
+	s, err := New(root,
+		func(_ context.Context, _ store.ID) *mocks.MockCredential {
+			return &mocks.MockCredential{}
+		},
+		WithLogger(&testLogger{t}),
+		WithScryptWorkFactor(10),
+		WithEncryptionCallbackFunc[EncryptionPassword](func(ctx context.Context) ([]byte, error) {
+			select {
+			case prompting <- struct{}{}:
+				<-release
+			default:
+			}
+			return password(ctx)
+		}),
+		WithDecryptionCallbackFunc[DecryptionPassword](password),
+	)
+	require.NoError(t, err)
+
+	existing := secrets.MustParseID("test/existing")
+	require.NoError(t, s.Save(t.Context(), existing, &mocks.MockCredential{Username: "bob", Password: "pass"}))
+
+	saved := make(chan error, 1)
+	go func() {
+		saved <- s.Save(t.Context(), secrets.MustParseID("test/slow"), &mocks.MockCredential{Username: "jeff", Password: "pass"})
+	}()
+	<-prompting
+
+	// the slow Save is still prompting, readers must not be blocked by it.
+	got, err := s.Get(t.Context(), existing)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", got.(*mocks.MockCredential).Username)
+	_, err = s.GetAllMetadata(t.Context())
+	require.NoError(t, err)
+
+	close(release)
+	require.NoError(t, <-saved)
+	got, err = s.Get(t.Context(), secrets.MustParseID("test/slow"))
+	require.NoError(t, err)
+	assert.Equal(t, "jeff", got.(*mocks.MockCredential).Username)
+}
+
+func TestClose(t *testing.T) {
+	t.Run("does not close the root by default", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+		require.NoError(t, s.Close())
+		_, err := root.Stat(".")
+		assert.NoError(t, err)
+	})
+	t.Run("closes the root it owns", func(t *testing.T) {
+		root, err := os.OpenRoot(t.TempDir())
+		require.NoError(t, err)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10), WithRootOwnership())
+		require.NoError(t, s.Close())
+		_, err = root.Stat(".")
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+}
+
+// validatingCredential is a [mocks.MockCredential] that requires both a
+// username and a password.
+type validatingCredential struct {
+	mocks.MockCredential
+}
+
+var errInvalidCredential = errors.New("username and password are required")
+
+func (v *validatingCredential) Validate() error {
+	if v.Username == "" || v.Password == "" {
+		return errInvalidCredential
+	}
+	return nil
+}
+
+func TestSaveValidatesSecret(t *testing.T) {
+	root := newTempRoot(t)
+	s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+	id := secrets.MustParseID("test/validated")
+
+	invalid := &validatingCredential{mocks.MockCredential{Username: "bob"}}
+	require.ErrorIs(t, s.Save(t.Context(), id, invalid), errInvalidCredential)
+	require.ErrorIs(t, s.Upsert(t.Context(), id, invalid), errInvalidCredential)
+
+	entries, err := fs.ReadDir(root.FS(), ".")
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing must be written for an invalid secret")
+
+	valid := &validatingCredential{mocks.MockCredential{Username: "bob", Password: "pass"}}
+	require.NoError(t, s.Save(t.Context(), id, valid))
+	got, err := s.Get(t.Context(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", got.(*mocks.MockCredential).Username)
+}
+
+func TestCompression(t *testing.T) {
+	largeSecret := func() *mocks.MockCredential {
+		return &mocks.MockCredential{
+			Username:   uuid.NewString(),
+			Password:   strings.Repeat(uuid.NewString(), 1000),
+			Attributes: map[string]string{"kind": "large"},
+		}
+	}
+
+	t.Run("round-trips with every key type", func(t *testing.T) {
+		root := newTempRoot(t)
+		masterKey := uuid.NewString()
+		identity, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		prv, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		pub, err := ssh.NewPublicKey(&prv.PublicKey)
+		require.NoError(t, err)
+		privatePem := pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(prv),
+		})
+
+		s, err := New(root,
+			func(_ context.Context, _ store.ID) *mocks.MockCredential {
+				return &mocks.MockCredential{}
+			},
+			WithLogger(&testLogger{t}),
+			WithScryptWorkFactor(10),
+			WithCompression(gzip.BestCompression),
+			WithEncryptionCallbackFunc[EncryptionPassword](func(_ context.Context) ([]byte, error) {
+				return []byte(masterKey), nil
+			}),
+			WithEncryptionCallbackFunc[EncryptionAgeX25519](func(_ context.Context) ([]byte, error) {
+				return []byte(identity.Recipient().String()), nil
+			}),
+			WithEncryptionCallbackFunc[EncryptionSSH](func(_ context.Context) ([]byte, error) {
+				return ssh.MarshalAuthorizedKey(pub), nil
+			}),
+			WithDecryptionCallbackFunc[DecryptionPassword](func(_ context.Context) ([]byte, error) {
+				return []byte(masterKey), nil
+			}),
+		)
+		require.NoError(t, err)
+
+		secret := largeSecret()
+		id := secrets.MustParseID("test/compressed/" + uuid.NewString())
+		require.NoError(t, s.Save(t.Context(), id, secret))
+
+		encryptedFile := readPassSecret(t, root, id)
+		assert.True(t, bytes.HasPrefix(encryptedFile, []byte("posixage-compression: gzip\n")))
+		assert.Less(t, len(encryptedFile), len(secret.Password), "large secret should be stored compressed")
+
+		got, err := s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+
+		x := s.(*fileStore[*mocks.MockCredential])
+		x.registeredDecryptionFunc = []promptCaller{
+			DecryptionAgeX25519(func(_ context.Context) ([]byte, error) {
+				return []byte(identity.String()), nil
+			}),
+		}
+		got, err = s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+
+		x.registeredDecryptionFunc = []promptCaller{
+			DecryptionSSH(func(_ context.Context) ([]byte, error) {
+				return privatePem, nil
+			}),
+		}
+		got, err = s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+
+	t.Run("metadata is not compressed", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10), WithCompression(gzip.DefaultCompression))
+
+		secret := largeSecret()
+		id := secrets.MustParseID("test/compressed/" + uuid.NewString())
+		require.NoError(t, s.Save(t.Context(), id, secret))
+
+		metadata, err := s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		require.Contains(t, metadata, id)
+		assert.Equal(t, secret.Attributes, metadata[id].Metadata())
+	})
+
+	t.Run("reads uncompressed secrets and is readable without the option", func(t *testing.T) {
+		root := newTempRoot(t)
+		plain := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+		compressed := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10), WithCompression(gzip.BestSpeed))
+
+		legacy := largeSecret()
+		legacyID := secrets.MustParseID("test/legacy/" + uuid.NewString())
+		require.NoError(t, plain.Save(t.Context(), legacyID, legacy))
+		assert.False(t, bytes.HasPrefix(readPassSecret(t, root, legacyID), []byte("posixage-compression:")))
+
+		got, err := compressed.Get(t.Context(), legacyID)
+		require.NoError(t, err)
+		assert.Equal(t, legacy, got)
+
+		secret := largeSecret()
+		id := secrets.MustParseID("test/compressed/" + uuid.NewString())
+		require.NoError(t, compressed.Save(t.Context(), id, secret))
+
+		got, err = plain.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+
+	t.Run("rejects invalid levels", func(t *testing.T) {
+		for _, level := range []int{gzip.HuffmanOnly - 1, gzip.BestCompression + 1} {
+			_, err := New(newTempRoot(t),
+				func(_ context.Context, _ store.ID) *mocks.MockCredential {
+					return &mocks.MockCredential{}
+				},
+				WithEncryptionCallbackFunc[EncryptionPassword](func(_ context.Context) ([]byte, error) {
+					return []byte("a-password"), nil
+				}),
+				WithDecryptionCallbackFunc[DecryptionPassword](func(_ context.Context) ([]byte, error) {
+					return []byte("a-password"), nil
+				}),
+				WithCompression(level),
+			)
+			assert.Error(t, err, "level=%d should be rejected", level)
+		}
+	})
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("applies staged writes on commit", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+		cert := secrets.MustParseID("tls/cert")
+		key := secrets.MustParseID("tls/key")
+		old := secrets.MustParseID("tls/old")
+		require.NoError(t, s.Save(t.Context(), cert, &mocks.MockCredential{Username: "cert", Password: "old"}))
+		require.NoError(t, s.Save(t.Context(), old, &mocks.MockCredential{Username: "old", Password: "old"}))
+
+		b, err := store.BeginBatch(s)
+		require.NoError(t, err)
+		require.NoError(t, b.Save(t.Context(), cert, &mocks.MockCredential{Username: "cert", Password: "new"}))
+		require.NoError(t, b.Save(t.Context(), key, &mocks.MockCredential{Username: "key", Password: "new"}))
+		require.NoError(t, b.Delete(t.Context(), old))
+
+		_, err = s.Get(t.Context(), key)
+		require.Error(t, err, "staged secrets must not be visible before commit")
+
+		require.NoError(t, b.Commit(t.Context()))
+		got, err := s.Get(t.Context(), cert)
+		require.NoError(t, err)
+		assert.Equal(t, "new", got.(*mocks.MockCredential).Password)
+		got, err = s.Get(t.Context(), key)
+		require.NoError(t, err)
+		assert.Equal(t, "new", got.(*mocks.MockCredential).Password)
+		_, err = s.Get(t.Context(), old)
+		assert.Error(t, err)
+
+		entries, err := fs.ReadDir(root.FS(), ".")
+		require.NoError(t, err)
+		for _, e := range entries {
+			assert.False(t, strings.HasPrefix(e.Name(), batchDirPrefix), "the staging directory must be removed")
+		}
+		assert.ErrorIs(t, b.Commit(t.Context()), store.ErrBatchClosed)
+	})
+
+	t.Run("discard writes nothing", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+		b, err := store.BeginBatch(s)
+		require.NoError(t, err)
+		require.NoError(t, b.Save(t.Context(), secrets.MustParseID("foo"), &mocks.MockCredential{Username: "foo", Password: "bar"}))
+		require.NoError(t, b.Discard())
+		assert.ErrorIs(t, b.Commit(t.Context()), store.ErrBatchClosed)
+
+		entries, err := fs.ReadDir(root.FS(), ".")
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("rollback restores previous secrets", func(t *testing.T) {
+		root := newTempRoot(t)
+		require.NoError(t, root.MkdirAll("staging/old/replaced", 0o700))
+		require.NoError(t, root.Mkdir("replaced", 0o700))
+		require.NoError(t, root.Mkdir("added", 0o700))
+		require.NoError(t, root.Mkdir("staging/old/deleted", 0o700))
+
+		require.NoError(t, rollback(root, "staging/old", []appliedOp{
+			{name: "replaced", movedOld: true, placedNew: true},
+			{name: "added", placedNew: true},
+			{name: "deleted", movedOld: true},
+		}))
+
+		entries, err := fs.ReadDir(root.FS(), ".")
+		require.NoError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		assert.ElementsMatch(t, []string{"deleted", "replaced", "staging"}, names)
+		oldEntries, err := fs.ReadDir(root.FS(), "staging/old")
+		require.NoError(t, err)
+		assert.Empty(t, oldEntries)
+	})
+
+	t.Run("interrupted batches are not listed", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+		id := secrets.MustParseID("foo")
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "foo", Password: "bar"}))
+		require.NoError(t, root.MkdirAll(batchDirPrefix+"leftover/new/"+secretfile.IDToDirName(secrets.MustParseID("staged")), 0o700))
+
+		metadata, err := s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, metadata, 1)
+		all, err := s.Filter(t.Context(), secrets.MustParsePattern("**"))
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+}
+
+func TestAgeRecipientsFile(t *testing.T) {
+	var identities []*age.X25519Identity
+	recipientsFile := "# team recipients\n\n"
+	for i := range 3 {
+		identity, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		identities = append(identities, identity)
+		recipientsFile += "# member " + strconv.Itoa(i) + "\n" + identity.Recipient().String() + "\n"
+	}
+	path := filepath.Join(t.TempDir(), "recipients.txt")
+	require.NoError(t, os.WriteFile(path, []byte(recipientsFile), 0o600))
+
+	root := newTempRoot(t)
+	newStore := func(identity *age.X25519Identity) store.Store {
+		s, err := New(root,
+			func(_ context.Context, _ store.ID) *mocks.MockCredential {
+				return &mocks.MockCredential{}
+			},
+			WithLogger(&testLogger{t}),
+			WithEncryptionCallbackFunc(AgeRecipientsFile(path)),
+			WithDecryptionCallbackFunc[DecryptionAgeX25519](func(_ context.Context) ([]byte, error) {
+				return []byte(identity.String()), nil
+			}),
+		)
+		require.NoError(t, err)
+		return s
+	}
+
+	secret := &mocks.MockCredential{Username: uuid.NewString(), Password: uuid.NewString()}
+	id := secrets.MustParseID("team/" + uuid.NewString())
+	require.NoError(t, newStore(identities[0]).Save(t.Context(), id, secret))
+
+	for i, identity := range identities {
+		got, err := newStore(identity).Get(t.Context(), id)
+		require.NoError(t, err, "identity %d should decrypt", i)
+		assert.Equal(t, secret, got)
+	}
+
+	t.Run("identities not listed cannot decrypt", func(t *testing.T) {
+		outsider, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		_, err = newStore(outsider).Get(t.Context(), id)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing or empty recipients file", func(t *testing.T) {
+		emptyFile := filepath.Join(t.TempDir(), "empty.txt")
+		require.NoError(t, os.WriteFile(emptyFile, []byte("# nobody\n"), 0o600))
+
+		for _, p := range []string{filepath.Join(t.TempDir(), "missing.txt"), emptyFile} {
+			s, err := New(newTempRoot(t),
+				func(_ context.Context, _ store.ID) *mocks.MockCredential {
+					return &mocks.MockCredential{}
+				},
+				WithEncryptionCallbackFunc(AgeRecipientsFile(p)),
+				WithDecryptionCallbackFunc[DecryptionAgeX25519](func(_ context.Context) ([]byte, error) {
+					return []byte(identities[0].String()), nil
+				}),
+			)
+			require.NoError(t, err)
+			assert.Error(t, s.Save(t.Context(), id, secret), "saving with %s should fail", p)
+		}
+	})
+}
+
+func TestPreferredKeyTypeHint(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	password := uuid.NewString()
+
+	var calls []string
+	ageDecryption := WithDecryptionCallbackFunc[DecryptionAgeX25519](func(_ context.Context) ([]byte, error) {
+		calls = append(calls, "age")
+		return []byte(identity.String()), nil
+	})
+	passwordDecryption := WithDecryptionCallbackFunc[DecryptionPassword](func(_ context.Context) ([]byte, error) {
+		calls = append(calls, "password")
+		return []byte(password), nil
+	})
+
+	root := newTempRoot(t)
+	newStore := func(opts ...Options) store.Store {
+		s, err := New(root,
+			func(_ context.Context, _ store.ID) *mocks.MockCredential {
+				return &mocks.MockCredential{}
+			},
+			append([]Options{
+				WithLogger(&testLogger{t}),
+				WithScryptWorkFactor(10),
+				WithEncryptionCallbackFunc[EncryptionAgeX25519](func(_ context.Context) ([]byte, error) {
+					return []byte(identity.Recipient().String()), nil
+				}),
+				WithEncryptionCallbackFunc[EncryptionPassword](func(_ context.Context) ([]byte, error) {
+					return []byte(password), nil
+				}),
+			}, opts...)...,
+		)
+		require.NoError(t, err)
+		return s
+	}
+
+	// the secret is saved by a user decrypting with the password
+	secret := &mocks.MockCredential{Username: uuid.NewString(), Password: uuid.NewString()}
+	id := secrets.MustParseID("hint/" + uuid.NewString())
+	require.NoError(t, newStore(passwordDecryption).Save(t.Context(), id, secret))
+
+	t.Run("preferred key type is tried first", func(t *testing.T) {
+		calls = nil
+		got, err := newStore(ageDecryption, passwordDecryption).Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+		assert.Equal(t, []string{"password"}, calls)
+	})
+	t.Run("other key types are still tried", func(t *testing.T) {
+		calls = nil
+		got, err := newStore(ageDecryption).Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+		assert.Equal(t, []string{"age"}, calls)
+	})
+	t.Run("secrets without hint use the registration order", func(t *testing.T) {
+		require.NoError(t, root.Remove(filepath.Join(secretfile.IDToDirName(id), secretfile.HintFileName)))
+		calls = nil
+		_, err := newStore(ageDecryption, passwordDecryption).Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"age"}, calls)
+	})
+}
+
+func TestList(t *testing.T) {
+	root := newTempRoot(t)
+	s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+	for _, id := range []string{"app/c", "app/a", "app/b", "other/a"} {
+		require.NoError(t, s.Save(t.Context(), secrets.MustParseID(id), &mocks.MockCredential{
+			Username:   id,
+			Password:   "secret",
+			Attributes: map[string]string{"id": id},
+		}))
+	}
+	pattern := secrets.MustParsePattern("app/*")
+
+	var ids []string
+	var cursor string
+	for {
+		result, err := store.List(t.Context(), s, pattern, store.ListOptions{Limit: 2, Cursor: cursor})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(result.Items), 2)
+		for _, item := range result.Items {
+			ids = append(ids, item.ID.String())
+			got := item.Secret.(*mocks.MockCredential)
+			assert.Equal(t, map[string]string{"id": item.ID.String()}, got.Attributes)
+			assert.Empty(t, got.Password, "listing must not decrypt secrets")
+		}
+		if result.Cursor == "" {
+			break
+		}
+		cursor = result.Cursor
+	}
+	assert.ElementsMatch(t, []string{"app/a", "app/b", "app/c"}, ids)
+
+	all, err := store.List(t.Context(), s, pattern, store.ListOptions{})
+	require.NoError(t, err)
+	var again []string
+	for _, item := range all.Items {
+		again = append(again, item.ID.String())
+	}
+	assert.Equal(t, ids, again, "the order must be stable between calls")
+	assert.Empty(t, all.Cursor)
+}
+
+func TestFilterByLabels(t *testing.T) {
+	root := newTempRoot(t)
+	s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+	for id, labels := range map[string]map[string]string{
+		"payments/db":  {"team": "payments", "env": "prod"},
+		"payments/api": {"team": "payments", "env": "dev"},
+		"search/db":    {"team": "search", "env": "prod"},
+	} {
+		require.NoError(t, s.Save(t.Context(), secrets.MustParseID(id), &mocks.MockCredential{
+			Username:   id,
+			Password:   "secret",
+			Attributes: labels,
+		}))
+	}
+
+	filtered := func(labels map[string]string) []string {
+		t.Helper()
+		secrets, err := store.FilterByLabels(t.Context(), s, labels)
+		require.NoError(t, err)
+		var ids []string
+		for id, secret := range secrets {
+			ids = append(ids, id.String())
+			got := secret.(*mocks.MockCredential)
+			assert.Equal(t, id.String(), got.Username)
+			assert.Equal(t, "secret", got.Password)
+		}
+		return ids
+	}
+	assert.ElementsMatch(t, []string{"payments/db", "payments/api"}, filtered(map[string]string{"team": "payments"}))
+	assert.ElementsMatch(t, []string{"payments/db", "search/db"}, filtered(map[string]string{"env": "prod"}))
+	assert.ElementsMatch(t, []string{"payments/db"}, filtered(map[string]string{"team": "payments", "env": "prod"}))
+
+	_, err := store.FilterByLabels(t.Context(), s, map[string]string{"team": "payments", "env": "staging"})
+	assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+}
+
+func TestSaveIfAbsent(t *testing.T) {
+	root := newTempRoot(t)
+	prompts := 0
+	s, err := New(root,
+		func(_ context.Context, _ store.ID) *mocks.MockCredential {
+			return &mocks.MockCredential{}
+		},
+		WithLogger(&testLogger{t}),
+		WithScryptWorkFactor(10),
+		WithEncryptionCallbackFunc[EncryptionPassword](func(context.Context) ([]byte, error) {
+			prompts++
+			return []byte("a-password"), nil
+		}),
+		WithDecryptionCallbackFunc[DecryptionPassword](func(context.Context) ([]byte, error) {
+			return []byte("a-password"), nil
+		}),
+	)
+	require.NoError(t, err)
+
+	id := secrets.MustParseID("test/create-only")
+	require.NoError(t, store.SaveIfAbsent(t.Context(), s, id, &mocks.MockCredential{Username: "bob", Password: "first"}))
+	assert.Equal(t, 1, prompts)
+
+	err = store.SaveIfAbsent(t.Context(), s, id, &mocks.MockCredential{Username: "jeff", Password: "second"})
+	require.ErrorIs(t, err, store.ErrCredentialExists)
+	assert.Equal(t, 1, prompts, "must not prompt for a secret that already exists")
+
+	got, err := s.Get(t.Context(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "first", got.(*mocks.MockCredential).Password)
+}
+
+func TestIncompleteSecret(t *testing.T) {
+	root := newTempRoot(t)
+	s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+	complete := secrets.MustParseID("app/complete")
+	truncated := secrets.MustParseID("app/truncated")
+	noMetadata := secrets.MustParseID("app/no-metadata")
+	for _, id := range []store.ID{complete, truncated, noMetadata} {
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "secret"}))
+	}
+
+	// simulate a crash of a Persist writing files in place, and one
+	// interrupted before writing the metadata file
+	truncatedDir := secretfile.IDToDirName(truncated)
+	entries, err := fs.ReadDir(root.FS(), truncatedDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), secretfile.SecretFileName) {
+			require.NoError(t, root.WriteFile(path.Join(truncatedDir, entry.Name()), nil, 0o600))
+		}
+	}
+	require.NoError(t, root.Remove(path.Join(secretfile.IDToDirName(noMetadata), secretfile.MetadataFileName)))
+
+	_, err = s.Get(t.Context(), truncated)
+	assert.ErrorIs(t, err, ErrIncompleteSecret)
+	_, err = s.Get(t.Context(), noMetadata)
+	assert.ErrorIs(t, err, ErrIncompleteSecret)
+
+	all, err := s.GetAllMetadata(t.Context())
+	require.NoError(t, err)
+	assert.Contains(t, all, complete)
+	assert.NotContains(t, all, noMetadata, "a secret without metadata must be skipped")
+
+	listed, err := store.List(t.Context(), s, secrets.MustParsePattern("app/*"), store.ListOptions{})
+	require.NoError(t, err)
+	var ids []string
+	for _, item := range listed.Items {
+		ids = append(ids, item.ID.String())
+	}
+	assert.NotContains(t, ids, noMetadata.String())
+
+	got, err := s.Filter(t.Context(), secrets.MustParsePattern("app/*"))
+	require.NoError(t, err)
+	assert.Equal(t, []store.ID{complete}, slices.Collect(maps.Keys(got)))
+}
+
+func TestVersionHistory(t *testing.T) {
+	t.Run("keeps the configured number of previous versions", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10), WithVersionHistory(2))
+		id := secrets.MustParseID("app/token")
+		for _, password := range []string{"first", "second", "third", "fourth"} {
+			require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: password}))
+		}
+
+		got, err := s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, "fourth", got.(*mocks.MockCredential).Password)
+
+		versions, err := store.ListVersions(t.Context(), s, id)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, 3, versions[0].Version, "the most recent version comes first")
+		assert.Equal(t, 2, versions[1].Version)
+		assert.False(t, versions[0].SavedAt.Before(versions[1].SavedAt))
+		for version, password := range map[int]string{3: "third", 2: "second"} {
+			got, err := store.GetVersion(t.Context(), s, id, version)
+			require.NoError(t, err)
+			assert.Equal(t, password, got.(*mocks.MockCredential).Password)
+			assert.Equal(t, "bob", got.(*mocks.MockCredential).Username)
+		}
+		_, err = store.GetVersion(t.Context(), s, id, 1)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound, "the oldest version must be pruned")
+
+		for ref, password := range map[string]string{"app/token@3": "third", "app/token@latest": "fourth", "app/token": "fourth"} {
+			vid, err := store.ParseVersionedID(ref)
+			require.NoError(t, err)
+			got, err := store.GetVersioned(t.Context(), s, vid)
+			require.NoError(t, err)
+			assert.Equal(t, password, got.(*mocks.MockCredential).Password, ref)
+		}
+
+		all, err := s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, all, 1, "previous versions are not listed as secrets")
+
+		require.NoError(t, s.Delete(t.Context(), id))
+		_, err = store.ListVersions(t.Context(), s, id)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+	t.Run("batches keep previous versions", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10), WithVersionHistory(1))
+		id := secrets.MustParseID("app/token")
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "first"}))
+
+		batch, err := store.BeginBatch(s)
+		require.NoError(t, err)
+		require.NoError(t, batch.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "second"}))
+		require.NoError(t, batch.Commit(t.Context()))
+
+		got, err := store.GetVersion(t.Context(), s, id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "first", got.(*mocks.MockCredential).Password)
+	})
+	t.Run("no history by default", func(t *testing.T) {
+		root := newTempRoot(t)
+		s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+		id := secrets.MustParseID("app/token")
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "first"}))
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "second"}))
+
+		versions, err := store.ListVersions(t.Context(), s, id)
+		require.NoError(t, err)
+		assert.Empty(t, versions)
+	})
+	t.Run("negative history is rejected", func(t *testing.T) {
+		_, err := New(newTempRoot(t), func(_ context.Context, _ store.ID) *mocks.MockCredential {
+			return &mocks.MockCredential{}
+		}, WithVersionHistory(-1))
+		assert.ErrorContains(t, err, "version history cannot be negative")
+	})
+}
+
+const stubPluginName = "stub"
+
+// TestMain runs the test binary as the age-plugin-stub binary when invoked
+// under that name, see [installStubPlugin].
+func TestMain(m *testing.M) {
+	if strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe") == "age-plugin-"+stubPluginName {
+		os.Exit(runStubPlugin())
+	}
+	os.Exit(m.Run())
+}
+
+// runStubPlugin implements an age plugin whose recipients and identities wrap
+// X25519 ones.
+func runStubPlugin() int {
+	p, err := plugin.New(stubPluginName)
+	if err != nil {
+		return 1
+	}
+	p.HandleRecipient(func(data []byte) (age.Recipient, error) {
+		return age.ParseX25519Recipient(string(data))
+	})
+	p.HandleIdentity(func(data []byte) (age.Identity, error) {
+		return age.ParseX25519Identity(string(data))
+	})
+	return p.Main()
+}
+
+// installStubPlugin copies the test binary as age-plugin-stub into a directory
+// added to $PATH.
+func installStubPlugin(t *testing.T) {
+	t.Helper()
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	data, err := os.ReadFile(executable)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	name := "age-plugin-" + stubPluginName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestAgePlugin(t *testing.T) {
+	x25519, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	recipient := plugin.EncodeRecipient(stubPluginName, []byte(x25519.Recipient().String()))
+	identity := plugin.EncodeIdentity(stubPluginName, []byte(x25519.String()))
+
+	newStore := func(root *os.Root) store.Store {
+		s, err := New(root,
+			func(_ context.Context, _ store.ID) *mocks.MockCredential {
+				return &mocks.MockCredential{}
+			},
+			WithLogger(&testLogger{t}),
+			WithEncryptionCallbackFunc[EncryptionAgePlugin](func(_ context.Context) ([]byte, error) {
+				return []byte(recipient), nil
+			}),
+			WithEncryptionCallbackFunc[EncryptionPassword](func(_ context.Context) ([]byte, error) {
+				return []byte("a-password"), nil
+			}),
+			WithDecryptionCallbackFunc[DecryptionAgePlugin](func(_ context.Context) ([]byte, error) {
+				return []byte(identity), nil
+			}),
+			WithDecryptionCallbackFunc[DecryptionPassword](func(_ context.Context) ([]byte, error) {
+				return []byte("a-password"), nil
+			}),
+			WithScryptWorkFactor(10),
+		)
+		require.NoError(t, err)
+		return s
+	}
+
+	secret := &mocks.MockCredential{Username: uuid.NewString(), Password: uuid.NewString()}
+	id := secrets.MustParseID("plugin/" + uuid.NewString())
+
+	t.Run("encrypts and decrypts through the plugin", func(t *testing.T) {
+		installStubPlugin(t)
+		root := newTempRoot(t)
+		require.NoError(t, newStore(root).Save(t.Context(), id, secret))
+
+		got, err := newStore(root).Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+
+		pluginOnly, err := New(root,
+			func(_ context.Context, _ store.ID) *mocks.MockCredential {
+				return &mocks.MockCredential{}
+			},
+			WithEncryptionCallbackFunc[EncryptionAgePlugin](func(_ context.Context) ([]byte, error) {
+				return []byte(recipient), nil
+			}),
+			WithDecryptionCallbackFunc[DecryptionAgePlugin](func(_ context.Context) ([]byte, error) {
+				return []byte(identity), nil
+			}),
+		)
+		require.NoError(t, err)
+		got, err = pluginOnly.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+	t.Run("an unavailable plugin falls back to the next decryption key", func(t *testing.T) {
+		installStubPlugin(t)
+		root := newTempRoot(t)
+		require.NoError(t, newStore(root).Save(t.Context(), id, secret))
+
+		t.Setenv("PATH", t.TempDir())
+		got, err := newStore(root).Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	root := newTempRoot(t)
+	s := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+	healthy := secrets.MustParseID("healthy")
+	corrupt := secrets.MustParseID("corrupt")
+	other := secrets.MustParseID("other-password")
+	for _, id := range []store.ID{healthy, corrupt} {
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: id.String(), Password: "secret"}))
+	}
+	require.NoError(t, newPasswordStore(t, root, "another-password", WithScryptWorkFactor(10)).
+		Save(t.Context(), other, &mocks.MockCredential{Username: "other", Password: "secret"}))
+
+	corruptDir := secretfile.IDToDirName(corrupt)
+	require.NoError(t, root.WriteFile(path.Join(corruptDir, secretfile.MetadataFileName), []byte("{not json"), 0o600))
+	invalidDir := "not base64!"
+	require.NoError(t, root.Mkdir(invalidDir, 0o700))
+
+	report, err := Verify(t.Context(), s)
+	require.NoError(t, err)
+	assert.Equal(t, []store.ID{healthy}, report.Healthy)
+	assert.Equal(t, []store.ID{other}, report.Undecryptable)
+	require.Len(t, report.Corrupt, 2)
+	byDir := map[string]CorruptEntry{}
+	for _, entry := range report.Corrupt {
+		assert.Error(t, entry.Err)
+		assert.False(t, entry.Quarantined)
+		byDir[entry.DirName] = entry
+	}
+	assert.Equal(t, corrupt, byDir[corruptDir].ID)
+	assert.Nil(t, byDir[invalidDir].ID)
+
+	t.Run("quarantine moves corrupt directories", func(t *testing.T) {
+		report, err := Verify(t.Context(), s, WithQuarantine())
+		require.NoError(t, err)
+		require.Len(t, report.Corrupt, 2)
+		for _, entry := range report.Corrupt {
+			assert.True(t, entry.Quarantined)
+			_, err := root.Stat(entry.DirName)
+			require.ErrorIs(t, err, fs.ErrNotExist)
+			_, err = root.Stat(path.Join(QuarantineDirName, entry.DirName))
+			require.NoError(t, err)
+		}
+
+		report, err = Verify(t.Context(), s)
+		require.NoError(t, err)
+		assert.Empty(t, report.Corrupt)
+		assert.Equal(t, []store.ID{healthy}, report.Healthy)
+
+		listed, err := s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, listed, 2)
+	})
+	t.Run("only posixage stores", func(t *testing.T) {
+		_, err := Verify(t.Context(), &mocks.MockStore{})
+		assert.Error(t, err)
+	})
+}
+
+func TestCanDecrypt(t *testing.T) {
+	root := newTempRoot(t)
+	prv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pub, err := ssh.NewPublicKey(&prv.PublicKey)
+	require.NoError(t, err)
+	sshStore, err := New(root,
+		func(_ context.Context, _ store.ID) *mocks.MockCredential {
+			return &mocks.MockCredential{}
+		},
+		WithLogger(&testLogger{t}),
+		WithEncryptionCallbackFunc[EncryptionSSH](func(_ context.Context) ([]byte, error) {
+			return ssh.MarshalAuthorizedKey(pub), nil
+		}),
+		WithDecryptionCallbackFunc[DecryptionSSH](func(_ context.Context) ([]byte, error) {
+			return pem.EncodeToMemory(&pem.Block{
+				Type:  "RSA PRIVATE KEY",
+				Bytes: x509.MarshalPKCS1PrivateKey(prv),
+			}), nil
+		}),
+	)
+	require.NoError(t, err)
+	sshOnly := secrets.MustParseID("ssh-only")
+	require.NoError(t, sshStore.Save(t.Context(), sshOnly, &mocks.MockCredential{Username: "bob", Password: "secret"}))
+
+	passwordStore := newPasswordStore(t, root, "a-password", WithScryptWorkFactor(10))
+	withPassword := secrets.MustParseID("password")
+	require.NoError(t, passwordStore.Save(t.Context(), withPassword, &mocks.MockCredential{Username: "bob", Password: "secret"}))
+
+	t.Run("secret encrypted with a registered key type", func(t *testing.T) {
+		ok, err := CanDecrypt(t.Context(), sshStore, sshOnly)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = CanDecrypt(t.Context(), passwordStore, withPassword)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("secret encrypted only for another key type", func(t *testing.T) {
+		ok, err := CanDecrypt(t.Context(), passwordStore, sshOnly)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("secret encrypted with another password", func(t *testing.T) {
+		otherPassword := newPasswordStore(t, root, "another-password", WithScryptWorkFactor(10))
+		ok, err := CanDecrypt(t.Context(), otherPassword, withPassword)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := CanDecrypt(t.Context(), passwordStore, secrets.MustParseID("missing"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+	t.Run("only posixage stores", func(t *testing.T) {
+		_, err := CanDecrypt(t.Context(), &mocks.MockStore{}, sshOnly)
+		assert.Error(t, err)
+	})
+}
+
+func TestPerSecretLocking(t *testing.T) {
+	root := newTempRoot(t)
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	s, err := New(root,
+		func(_ context.Context, _ store.ID) *mocks.MockCredential {
+			return &mocks.MockCredential{}
+		},
+		WithLogger(&testLogger{t}),
+		WithScryptWorkFactor(10),
+		WithEncryptionCallbackFunc[EncryptionPassword](func(_ context.Context) ([]byte, error) {
+			return []byte("a-password"), nil
+		}),
+		// decrypting blocks until release is closed, holding the locks of
+		// the operation
+		WithDecryptionCallbackFunc[DecryptionPassword](func(_ context.Context) ([]byte, error) {
+			select {
+			case entered <- struct{}{}:
+			default:
+			}
+			<-release
+			return []byte("a-password"), nil
+		}),
+	)
+	require.NoError(t, err)
+	f, ok := s.(*fileStore[*mocks.MockCredential])
+	require.True(t, ok)
+
+	a, b := secrets.MustParseID("a"), secrets.MustParseID("b")
+	require.NotEqual(t, stripe(a), stripe(b), "the secrets must not share a lock")
+	secret := &mocks.MockCredential{Username: "bob", Password: "secret"}
+	require.NoError(t, s.Save(t.Context(), a, secret))
+
+	save := func(id store.ID) <-chan error {
+		done := make(chan error, 1)
+		go func() { done <- s.Save(t.Context(), id, secret) }()
+		return done
+	}
+
+	t.Run("saves to different secrets proceed in parallel", func(t *testing.T) {
+		// as if a save of a was in progress
+		unlock, err := f.tryLockID(t.Context(), a, true)
+		require.NoError(t, err)
+		defer unlock()
+
+		select {
+		case err := <-save(b):
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("saving b was blocked by the save of a")
+		}
+
+		blocked := save(a)
+		select {
+		case <-blocked:
+			t.Fatal("saving a was not blocked by its lock")
+		case <-time.After(100 * time.Millisecond):
+		}
+		unlock()
+		require.NoError(t, <-blocked)
+	})
+
+	t.Run("a listing blocks saves", func(t *testing.T) {
+		listed := make(chan error, 1)
+		go func() {
+			_, err := s.Filter(t.Context(), secrets.MustParsePattern("**"))
+			listed <- err
+		}()
+		<-entered
+
+		blockedA, blockedB := save(a), save(b)
+		select {
+		case <-blockedA:
+			t.Fatal("saving a was not blocked by the listing")
+		case <-blockedB:
+			t.Fatal("saving b was not blocked by the listing")
+		case <-time.After(100 * time.Millisecond):
+		}
+		close(release)
+		require.NoError(t, <-listed)
+		require.NoError(t, <-blockedA)
+		require.NoError(t, <-blockedB)
+	})
+}
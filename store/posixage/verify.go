@@ -0,0 +1,283 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package posixage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/posixage/internal/secretfile"
+)
+
+// QuarantineDirName is the directory under the store root where [Verify]
+// moves corrupt secret directories when [WithQuarantine] is used. It is
+// ignored when listing secrets.
+const QuarantineDirName = ".quarantine"
+
+// internalDir reports whether the directory under the store root is used by
+// the store itself and does not hold a secret.
+func internalDir(name string) bool {
+	return strings.HasPrefix(name, batchDirPrefix) || name == QuarantineDirName
+}
+
+// Report is the result of [Verify].
+type Report struct {
+	// Healthy secrets could be restored and decrypted.
+	Healthy []store.ID
+	// Undecryptable secrets could be restored, but none of the registered
+	// decryption callbacks could decrypt them.
+	Undecryptable []store.ID
+	// Corrupt directories could not be restored as a secret.
+	Corrupt []CorruptEntry
+}
+
+// CorruptEntry is a secret directory that could not be restored.
+type CorruptEntry struct {
+	// DirName is the name of the directory under the store root.
+	DirName string
+	// ID is nil when the directory name does not decode to a secret ID.
+	ID  store.ID
+	Err error
+	// Quarantined is set when the directory was moved under
+	// [QuarantineDirName].
+	Quarantined bool
+}
+
+type verifyConfig struct {
+	quarantine bool
+}
+
+type VerifyOptions func(c *verifyConfig) error
+
+// WithQuarantine moves the corrupt directories found by [Verify] into the
+// [QuarantineDirName] directory of the store root, so that they can be
+// inspected or removed later without getting in the way of the store.
+func WithQuarantine() VerifyOptions {
+	return func(c *verifyConfig) error {
+		c.quarantine = true
+		return nil
+	}
+}
+
+type verifier interface {
+	verify(ctx context.Context, cfg verifyConfig) (Report, error)
+}
+
+// Verify walks a store created with [New] and attempts to restore and decrypt
+// every secret, reporting which secrets are healthy, which cannot be
+// decrypted with the registered decryption callbacks and which directories
+// are corrupt.
+//
+// Listing operations like [store.Store.Filter] log and skip corrupt
+// directories, Verify helps find them. Decryption callbacks may get invoked
+// for each secret.
+func Verify(ctx context.Context, s store.Store, opts ...VerifyOptions) (Report, error) {
+	cfg := verifyConfig{}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return Report{}, err
+		}
+	}
+	v, ok := s.(verifier)
+	if !ok {
+		return Report{}, fmt.Errorf("cannot verify a %T, only posixage stores are supported", s)
+	}
+	return v.verify(ctx, cfg)
+}
+
+func (f *fileStore[T]) verify(ctx context.Context, cfg verifyConfig) (Report, error) {
+	report, err := f.verifyAll(ctx)
+	if err != nil || !cfg.quarantine || len(report.Corrupt) == 0 {
+		return report, err
+	}
+
+	// Decryption callbacks are only invoked under the non-exclusive store
+	// lock, the corrupt directories are checked again once it is exclusive.
+	unlock, err := f.tryLock(ctx)
+	if err != nil {
+		return report, err
+	}
+	defer unlock()
+
+	for i, entry := range report.Corrupt {
+		if _, err := f.filesystem.Lstat(entry.DirName); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if entry.ID != nil {
+			if _, err := f.restoreSecret(ctx, entry.ID); err == nil {
+				continue
+			}
+		}
+		if err := f.quarantine(entry.DirName); err != nil {
+			return report, err
+		}
+		report.Corrupt[i].Quarantined = true
+	}
+	return report, nil
+}
+
+func (f *fileStore[T]) verifyAll(ctx context.Context) (Report, error) {
+	unlock, err := f.tryRLock(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	defer unlock()
+
+	entries, err := fs.ReadDir(f.filesystem.FS(), ".")
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	for _, d := range entries {
+		if !d.IsDir() || internalDir(d.Name()) {
+			continue
+		}
+		if err := context.Cause(ctx); err != nil {
+			return Report{}, err
+		}
+
+		id, err := secretfile.DirNameToID(d.Name())
+		if err != nil {
+			report.Corrupt = append(report.Corrupt, CorruptEntry{DirName: d.Name(), Err: err})
+			continue
+		}
+		if err := f.verifySecret(ctx, id); err != nil {
+			var corrupt corruptError
+			if errors.As(err, &corrupt) {
+				report.Corrupt = append(report.Corrupt, CorruptEntry{DirName: d.Name(), ID: id, Err: corrupt.err})
+				continue
+			}
+			if ctxErr := context.Cause(ctx); ctxErr != nil {
+				return Report{}, ctxErr
+			}
+			f.logger.Warnf("could not decrypt secret %s: %s", id, err)
+			report.Undecryptable = append(report.Undecryptable, id)
+			continue
+		}
+		report.Healthy = append(report.Healthy, id)
+	}
+	return report, nil
+}
+
+// corruptError wraps the errors caused by the content of a secret directory,
+// as opposed to decryption errors.
+type corruptError struct {
+	err error
+}
+
+func (c corruptError) Error() string {
+	return c.err.Error()
+}
+
+// verifySecret restores and decrypts the secret id. The store lock must be
+// held.
+func (f *fileStore[T]) verifySecret(ctx context.Context, id store.ID) error {
+	unlockStripe, err := f.lockStripe(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	defer unlockStripe()
+
+	encryptedSecrets, err := f.restoreSecret(ctx, id)
+	if err != nil {
+		return err
+	}
+	plaintext, err := f.decryptSecret(ctx, encryptedSecrets)
+	if err != nil {
+		return err
+	}
+	clear(plaintext)
+	return nil
+}
+
+// restoreSecret restores the encrypted secret id without decrypting it,
+// returning a [corruptError] when the directory cannot be restored.
+func (f *fileStore[T]) restoreSecret(ctx context.Context, id store.ID) ([]secretfile.EncryptedSecret, error) {
+	encryptedSecrets, metadata, err := secretfile.RestoreSecret(id, f.filesystem)
+	if err != nil {
+		return nil, corruptError{err}
+	}
+	if len(encryptedSecrets) == 0 {
+		return nil, corruptError{errors.New("no secret file found")}
+	}
+	if err := f.factory(ctx, id).SetMetadata(metadata); err != nil {
+		return nil, corruptError{fmt.Errorf("invalid metadata: %w", err)}
+	}
+	return encryptedSecrets, nil
+}
+
+type decryptionChecker interface {
+	canDecrypt(ctx context.Context, id store.ID) (bool, error)
+}
+
+// CanDecrypt reports whether the decryption callbacks registered on a store
+// created with [New] can decrypt the secret stored under id, without
+// returning its value: the plaintext is zeroed right away. It helps telling
+// which secrets can be opened before e.g. exporting them.
+//
+// Like [store.Store.Get], decryption callbacks get invoked. A secret none of
+// them can decrypt is reported with false and no error, while
+// [store.ErrCredentialNotFound] is returned when there is no secret under id.
+func CanDecrypt(ctx context.Context, s store.Store, id store.ID) (bool, error) {
+	c, ok := s.(decryptionChecker)
+	if !ok {
+		return false, fmt.Errorf("cannot check decryption of a %T, only posixage stores are supported", s)
+	}
+	return c.canDecrypt(ctx, id)
+}
+
+func (f *fileStore[T]) canDecrypt(ctx context.Context, id store.ID) (bool, error) {
+	unlock, err := f.tryLockID(ctx, id, false)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	encryptedSecrets, _, err := secretfile.RestoreSecret(id, f.filesystem)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, store.ErrCredentialNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	plaintext, err := f.decryptSecret(ctx, encryptedSecrets)
+	if err != nil {
+		if ctxErr := context.Cause(ctx); ctxErr != nil {
+			return false, ctxErr
+		}
+		return false, nil
+	}
+	clear(plaintext)
+	return true, nil
+}
+
+func (f *fileStore[T]) quarantine(dirName string) error {
+	if err := f.filesystem.MkdirAll(QuarantineDirName, 0o700); err != nil {
+		return err
+	}
+	target := path.Join(QuarantineDirName, dirName)
+	// keep the directory quarantined by a previous run
+	if _, err := f.filesystem.Lstat(target); err == nil {
+		target += "-" + rand.Text()
+	}
+	return f.filesystem.Rename(dirName, target)
+}
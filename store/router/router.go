@@ -0,0 +1,185 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package router provides a [store.Store] that routes secrets to different
+// backends based on their ID:
+//
+//	s, err := router.New([]router.Route{
+//		{Pattern: store.MustParsePattern("db/**"), Store: vaultStore},
+//		{Pattern: store.MustParsePattern("registry/**"), Store: keychainStore},
+//		{Pattern: store.MustParsePattern("**"), Store: posixageStore},
+//	})
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/x/secrets"
+)
+
+// ErrNoRoute is returned when writing a secret whose ID does not match the
+// pattern of any route.
+var ErrNoRoute = errors.New("no route matches secret ID")
+
+// Route sends the secrets matching Pattern to Store.
+type Route struct {
+	Pattern store.Pattern
+	Store   store.Store
+}
+
+var _ store.Store = &router{}
+
+type router struct {
+	routes []Route
+}
+
+// New returns a [store.Store] that dispatches each operation to the routes.
+//
+// Get, Save, Upsert and Delete use the first route whose pattern matches the
+// ID, so routes listed first take precedence over overlapping ones. Filter,
+// FilterMetadata, GetAll and GetAllMetadata query every route and merge the
+// results, keeping only the secrets that are routed to the store that
+// returned them.
+//
+// Writing a secret that matches no route returns [ErrNoRoute], reading it
+// returns [store.ErrCredentialNotFound].
+//
+// Close closes every store of the routes once.
+func New(routes []Route) (store.Store, error) {
+	if len(routes) == 0 {
+		return nil, errors.New("router requires at least one route")
+	}
+	for i, r := range routes {
+		if r.Pattern == nil || r.Store == nil {
+			return nil, fmt.Errorf("route %d requires a pattern and a store", i)
+		}
+	}
+	return &router{routes: slices.Clone(routes)}, nil
+}
+
+// route returns the index of the first route matching id, or -1.
+func (r *router) route(id store.ID) int {
+	return slices.IndexFunc(r.routes, func(route Route) bool {
+		return route.Pattern.Match(id)
+	})
+}
+
+func (r *router) storeFor(id store.ID) (store.Store, error) {
+	i := r.route(id)
+	if i == -1 {
+		return nil, fmt.Errorf("%w: %s", ErrNoRoute, id)
+	}
+	return r.routes[i].Store, nil
+}
+
+func (r *router) Close() error {
+	var closed []store.Store
+	var errs []error
+	for _, route := range r.routes {
+		if slices.Contains(closed, route.Store) {
+			continue
+		}
+		closed = append(closed, route.Store)
+		if err := route.Store.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *router) Delete(ctx context.Context, id store.ID) error {
+	s, err := r.storeFor(id)
+	if err != nil {
+		return err
+	}
+	return s.Delete(ctx, id)
+}
+
+func (r *router) Get(ctx context.Context, id store.ID) (store.Secret, error) {
+	i := r.route(id)
+	if i == -1 {
+		return nil, store.ErrCredentialNotFound
+	}
+	return r.routes[i].Store.Get(ctx, id)
+}
+
+func (r *router) Save(ctx context.Context, id store.ID, secret store.Secret) error {
+	s, err := r.storeFor(id)
+	if err != nil {
+		return err
+	}
+	return s.Save(ctx, id, secret)
+}
+
+func (r *router) Upsert(ctx context.Context, id store.ID, secret store.Secret) error {
+	s, err := r.storeFor(id)
+	if err != nil {
+		return err
+	}
+	return s.Upsert(ctx, id, secret)
+}
+
+func (r *router) GetAll(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return r.Filter(ctx, store.MustParsePattern("**"))
+}
+
+func (r *router) GetAllMetadata(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return r.FilterMetadata(ctx, store.MustParsePattern("**"))
+}
+
+func (r *router) Filter(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	return r.fanOut(ctx, pattern, store.Store.Filter)
+}
+
+func (r *router) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	return r.fanOut(ctx, pattern, store.Store.FilterMetadata)
+}
+
+type filterFunc func(s store.Store, ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error)
+
+// fanOut calls filter on the store of every route and merges the results.
+// A store can hold secrets outside of its route or shadowed by a previous
+// route, so only the secrets routed to the store are kept.
+func (r *router) fanOut(ctx context.Context, pattern store.Pattern, filter filterFunc) (map[store.ID]store.Secret, error) {
+	result := map[store.ID]store.Secret{}
+	for i, route := range r.routes {
+		// narrow the query down to the route when one includes the other
+		query := pattern
+		if narrowed, ok := secrets.Filter(route.Pattern, pattern); ok {
+			query = narrowed
+		}
+
+		found, err := filter(route.Store, ctx, query)
+		if errors.Is(err, store.ErrCredentialNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for id, secret := range found {
+			if pattern.Match(id) && r.route(id) == i {
+				result[id] = secret
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+	return result, nil
+}
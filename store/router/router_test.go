@@ -0,0 +1,170 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/mocks"
+)
+
+func newSecret(value string) *mocks.MockCredential {
+	return &mocks.MockCredential{Username: value, Password: value}
+}
+
+func TestRouter(t *testing.T) {
+	// "registry/docker/**" is listed before the broader "registry/**" route
+	// and takes precedence for the IDs matching both.
+	newRouter := func(t *testing.T) (store.Store, *mocks.MockStore, *mocks.MockStore) {
+		t.Helper()
+		first, second := &mocks.MockStore{}, &mocks.MockStore{}
+		s, err := New([]Route{
+			{Pattern: store.MustParsePattern("registry/docker/**"), Store: first},
+			{Pattern: store.MustParsePattern("registry/**"), Store: second},
+			{Pattern: store.MustParsePattern("db/**"), Store: first},
+		})
+		require.NoError(t, err)
+		return s, first, second
+	}
+
+	t.Run("writes go to the first matching route", func(t *testing.T) {
+		s, first, second := newRouter(t)
+		require.NoError(t, s.Save(t.Context(), store.MustParseID("registry/docker/hub"), newSecret("hub")))
+		require.NoError(t, s.Upsert(t.Context(), store.MustParseID("registry/ghcr"), newSecret("ghcr")))
+		require.NoError(t, s.Save(t.Context(), store.MustParseID("db/postgres"), newSecret("postgres")))
+
+		firstAll, err := first.GetAll(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, firstAll, 2)
+		assert.Contains(t, firstAll, store.MustParseID("registry/docker/hub"))
+		assert.Contains(t, firstAll, store.MustParseID("db/postgres"))
+
+		secondAll, err := second.GetAll(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, secondAll, 1)
+		assert.Contains(t, secondAll, store.MustParseID("registry/ghcr"))
+
+		got, err := s.Get(t.Context(), store.MustParseID("registry/ghcr"))
+		require.NoError(t, err)
+		assert.Equal(t, newSecret("ghcr"), got)
+
+		require.NoError(t, s.Delete(t.Context(), store.MustParseID("registry/docker/hub")))
+		_, err = first.Get(t.Context(), store.MustParseID("registry/docker/hub"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+
+	t.Run("unmatched ids", func(t *testing.T) {
+		s, _, _ := newRouter(t)
+		id := store.MustParseID("other/secret")
+		assert.ErrorIs(t, s.Save(t.Context(), id, newSecret("x")), ErrNoRoute)
+		assert.ErrorIs(t, s.Upsert(t.Context(), id, newSecret("x")), ErrNoRoute)
+		assert.ErrorIs(t, s.Delete(t.Context(), id), ErrNoRoute)
+		_, err := s.Get(t.Context(), id)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+
+	t.Run("filter merges routes and honors precedence", func(t *testing.T) {
+		s, first, second := newRouter(t)
+		require.NoError(t, s.Save(t.Context(), store.MustParseID("registry/docker/hub"), newSecret("hub")))
+		require.NoError(t, s.Save(t.Context(), store.MustParseID("registry/ghcr"), newSecret("ghcr")))
+		require.NoError(t, s.Save(t.Context(), store.MustParseID("db/postgres"), newSecret("postgres")))
+		// shadowed by the first route and outside of any route, so neither
+		// must be returned
+		require.NoError(t, second.Save(t.Context(), store.MustParseID("registry/docker/hub"), newSecret("shadowed")))
+		require.NoError(t, first.Save(t.Context(), store.MustParseID("unrouted"), newSecret("unrouted")))
+
+		all, err := s.GetAll(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, map[store.ID]store.Secret{
+			store.MustParseID("registry/docker/hub"): newSecret("hub"),
+			store.MustParseID("registry/ghcr"):       newSecret("ghcr"),
+			store.MustParseID("db/postgres"):         newSecret("postgres"),
+		}, all)
+
+		registry, err := s.Filter(t.Context(), store.MustParsePattern("registry/**"))
+		require.NoError(t, err)
+		assert.Len(t, registry, 2)
+		assert.Equal(t, newSecret("hub"), registry[store.MustParseID("registry/docker/hub")])
+
+		metadata, err := s.FilterMetadata(t.Context(), store.MustParsePattern("db/*"))
+		require.NoError(t, err)
+		assert.Len(t, metadata, 1)
+
+		all, err = s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+
+		_, err = s.Filter(t.Context(), store.MustParsePattern("nothing/**"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+
+	t.Run("filter errors are returned", func(t *testing.T) {
+		errFilter := errors.New("filter failed")
+		s, err := New([]Route{
+			{Pattern: store.MustParsePattern("**"), Store: &failingStore{MockStore: &mocks.MockStore{}, err: errFilter}},
+		})
+		require.NoError(t, err)
+		_, err = s.GetAll(t.Context())
+		assert.ErrorIs(t, err, errFilter)
+	})
+
+	t.Run("close closes every store once", func(t *testing.T) {
+		shared := &closeCountingStore{MockStore: &mocks.MockStore{}}
+		other := &closeCountingStore{MockStore: &mocks.MockStore{}}
+		s, err := New([]Route{
+			{Pattern: store.MustParsePattern("a/**"), Store: shared},
+			{Pattern: store.MustParsePattern("b/**"), Store: other},
+			{Pattern: store.MustParsePattern("c/**"), Store: shared},
+		})
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+		assert.Equal(t, 1, shared.closed)
+		assert.Equal(t, 1, other.closed)
+	})
+
+	t.Run("invalid routes", func(t *testing.T) {
+		_, err := New(nil)
+		assert.Error(t, err)
+		_, err = New([]Route{{Pattern: store.MustParsePattern("**")}})
+		assert.Error(t, err)
+		_, err = New([]Route{{Store: &mocks.MockStore{}}})
+		assert.Error(t, err)
+	})
+}
+
+type failingStore struct {
+	*mocks.MockStore
+	err error
+}
+
+func (f *failingStore) Filter(context.Context, store.Pattern) (map[store.ID]store.Secret, error) {
+	return nil, f.err
+}
+
+type closeCountingStore struct {
+	*mocks.MockStore
+	closed int
+}
+
+func (c *closeCountingStore) Close() error {
+	c.closed++
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sizelimit provides a [store.Store] decorator rejecting secrets
+// whose marshaled value exceeds a maximum size.
+//
+// OS keychains limit the size of the values they store, and fail with
+// obscure errors, or truncate the value, when it is exceeded. Wrapping the
+// store checks the size upfront and fails with [ErrSecretTooLarge] instead.
+package sizelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/docker/secrets-engine/store"
+)
+
+// ErrSecretTooLarge is returned when writing a secret whose marshaled value
+// exceeds the limit given to [Wrap].
+var ErrSecretTooLarge = errors.New("secret too large")
+
+var _ store.Store = &limitedStore{}
+
+type limitedStore struct {
+	store.Store
+	maxBytes int
+}
+
+// Wrap returns a [store.Store] rejecting the writes of secrets whose
+// marshaled value is larger than maxBytes with [ErrSecretTooLarge]. All
+// other operations are passed through to inner unchanged.
+func Wrap(inner store.Store, maxBytes int) (store.Store, error) {
+	if inner == nil {
+		return nil, errors.New("store cannot be nil")
+	}
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("maximum secret size must be positive, got %d", maxBytes)
+	}
+	return &limitedStore{Store: inner, maxBytes: maxBytes}, nil
+}
+
+// check returns [ErrSecretTooLarge] when the marshaled value of secret
+// exceeds the limit.
+func (l *limitedStore) check(id store.ID, secret store.Secret) error {
+	data, err := secret.Marshal()
+	if err != nil {
+		return err
+	}
+	if len(data) > l.maxBytes {
+		return fmt.Errorf("%w: %s is %d bytes, the maximum is %d bytes", ErrSecretTooLarge, id, len(data), l.maxBytes)
+	}
+	return nil
+}
+
+func (l *limitedStore) Save(ctx context.Context, id store.ID, secret store.Secret) error {
+	if err := l.check(id, secret); err != nil {
+		return err
+	}
+	return l.Store.Save(ctx, id, secret)
+}
+
+func (l *limitedStore) Upsert(ctx context.Context, id store.ID, secret store.Secret) error {
+	if err := l.check(id, secret); err != nil {
+		return err
+	}
+	return l.Store.Upsert(ctx, id, secret)
+}
+
+// SaveIfAbsent saves the secret unless one already exists, see
+// [store.SaveIfAbsent].
+func (l *limitedStore) SaveIfAbsent(ctx context.Context, id store.ID, secret store.Secret) error {
+	if err := l.check(id, secret); err != nil {
+		return err
+	}
+	return store.SaveIfAbsent(ctx, l.Store, id, secret)
+}
+
+// List pages through the secrets of the wrapped store, see [store.List].
+func (l *limitedStore) List(ctx context.Context, pattern store.Pattern, opts store.ListOptions) (store.ListResult, error) {
+	return store.List(ctx, l.Store, pattern, opts)
+}
+
+// FilterByLabels returns the secrets of the wrapped store carrying all of
+// labels, see [store.FilterByLabels].
+func (l *limitedStore) FilterByLabels(ctx context.Context, labels map[string]string) (map[store.ID]store.Secret, error) {
+	return store.FilterByLabels(ctx, l.Store, labels)
+}
+
+// BeginBatch starts a batch on the wrapped store, see [store.BeginBatch].
+// Secrets staged for saving are checked against the limit as well.
+func (l *limitedStore) BeginBatch() (store.Batch, error) {
+	b, err := store.BeginBatch(l.Store)
+	if err != nil {
+		return nil, err
+	}
+	return &limitedBatch{Batch: b, store: l}, nil
+}
+
+// ListVersions lists the previous versions of a secret of the wrapped store,
+// see [store.ListVersions].
+func (l *limitedStore) ListVersions(ctx context.Context, id store.ID) ([]store.VersionInfo, error) {
+	return store.ListVersions(ctx, l.Store, id)
+}
+
+// GetVersion returns a previous version of a secret of the wrapped store, see
+// [store.GetVersion].
+func (l *limitedStore) GetVersion(ctx context.Context, id store.ID, version int) (store.Secret, error) {
+	return store.GetVersion(ctx, l.Store, id, version)
+}
+
+type limitedBatch struct {
+	store.Batch
+	store *limitedStore
+}
+
+func (b *limitedBatch) Save(ctx context.Context, id store.ID, secret store.Secret) error {
+	if err := b.store.check(id, secret); err != nil {
+		return err
+	}
+	return b.Batch.Save(ctx, id, secret)
+}
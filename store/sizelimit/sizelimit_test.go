@@ -0,0 +1,134 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizelimit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/mocks"
+)
+
+// credential returns a secret whose marshaled value is exactly size bytes.
+func credential(size int) *mocks.MockCredential {
+	return &mocks.MockCredential{Username: "u", Password: strings.Repeat("p", size-len("u:"))}
+}
+
+type batchingStore struct {
+	mocks.MockStore
+}
+
+func (b *batchingStore) BeginBatch() (store.Batch, error) {
+	return store.NewBestEffortBatch(&b.MockStore), nil
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("rejects invalid arguments", func(t *testing.T) {
+		_, err := Wrap(nil, 10)
+		assert.Error(t, err)
+		_, err = Wrap(&mocks.MockStore{}, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("writes are limited", func(t *testing.T) {
+		inner := &mocks.MockStore{}
+		s, err := Wrap(inner, 16)
+		require.NoError(t, err)
+		id := store.MustParseID("foo/bar")
+
+		writes := map[string]func(store.Secret) error{
+			"save":           func(secret store.Secret) error { return s.Save(t.Context(), id, secret) },
+			"upsert":         func(secret store.Secret) error { return s.Upsert(t.Context(), id, secret) },
+			"save_if_absent": func(secret store.Secret) error { return store.SaveIfAbsent(t.Context(), s, id, secret) },
+		}
+		for name, write := range writes {
+			t.Run(name, func(t *testing.T) {
+				require.NoError(t, inner.Delete(t.Context(), id))
+
+				assert.ErrorIs(t, write(credential(17)), ErrSecretTooLarge)
+				_, err := inner.Get(t.Context(), id)
+				assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+
+				require.NoError(t, write(credential(16)))
+				secret, err := inner.Get(t.Context(), id)
+				require.NoError(t, err)
+				assert.Equal(t, credential(16), secret)
+			})
+		}
+	})
+
+	t.Run("batched saves are limited", func(t *testing.T) {
+		inner := &batchingStore{}
+		s, err := Wrap(inner, 16)
+		require.NoError(t, err)
+
+		b, err := store.BeginBatch(s)
+		require.NoError(t, err)
+		assert.ErrorIs(t, b.Save(t.Context(), store.MustParseID("foo/big"), credential(17)), ErrSecretTooLarge)
+		require.NoError(t, b.Save(t.Context(), store.MustParseID("foo/small"), credential(16)))
+		require.NoError(t, b.Commit(t.Context()))
+
+		all, err := inner.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+		assert.Contains(t, all, store.MustParseID("foo/small"))
+	})
+
+	t.Run("other operations are passed through", func(t *testing.T) {
+		inner := &mocks.MockStore{}
+		id := store.MustParseID("foo/bar")
+		require.NoError(t, inner.Save(t.Context(), id, credential(64)))
+		s, err := Wrap(inner, 16)
+		require.NoError(t, err)
+
+		secret, err := s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, credential(64), secret)
+
+		all, err := s.GetAll(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+
+		metadata, err := s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, metadata, 1)
+
+		filtered, err := s.Filter(t.Context(), store.MustParsePattern("foo/*"))
+		require.NoError(t, err)
+		assert.Len(t, filtered, 1)
+
+		filtered, err = s.FilterMetadata(t.Context(), store.MustParsePattern("foo/*"))
+		require.NoError(t, err)
+		assert.Len(t, filtered, 1)
+
+		result, err := store.List(t.Context(), s, store.MustParsePattern("**"), store.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, result.Items, 1)
+
+		_, err = store.BeginBatch(s)
+		assert.ErrorIs(t, err, store.ErrBatchNotSupported)
+		_, err = store.ListVersions(t.Context(), s, id)
+		assert.ErrorIs(t, err, store.ErrVersioningNotSupported)
+
+		require.NoError(t, s.Delete(t.Context(), id))
+		_, err = inner.Get(t.Context(), id)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+		assert.NoError(t, s.Close())
+	})
+}
@@ -0,0 +1,435 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite provides a single-file secret store backed by SQLite, with
+// the values of the secrets encrypted with [age](https://github.com/FiloSottile/age).
+//
+// Secrets are rows of a table holding their ID, encrypted value and metadata
+// as JSON. Metadata is public and also stored in an indexed table, so that
+// [store.FilterByLabels] is a single query. Every write happens in a
+// transaction.
+//
+// The driver requires cgo: without it, [New] fails with an error of the
+// driver stating so.
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"filippo.io/age"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/docker/secrets-engine/store"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS secrets (
+	id       TEXT PRIMARY KEY,
+	value    BLOB NOT NULL,
+	metadata TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS labels (
+	id    TEXT NOT NULL,
+	key   TEXT NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (id, key)
+);
+CREATE INDEX IF NOT EXISTS labels_key_value ON labels (key, value);
+`
+
+type sqliteStore[T store.Secret] struct {
+	db      *sql.DB
+	factory store.Factory[T]
+	*config
+}
+
+var _ store.Store = &sqliteStore[store.Secret]{}
+var _ store.AbsentSaver = &sqliteStore[store.Secret]{}
+var _ store.LabelFilterer = &sqliteStore[store.Secret]{}
+
+type config struct {
+	recipients []age.Recipient
+	identities []age.Identity
+}
+
+type Options func(c *config) error
+
+// WithRecipients adds the recipients secrets are encrypted to when saved.
+func WithRecipients(recipients ...age.Recipient) Options {
+	return func(c *config) error {
+		c.recipients = append(c.recipients, recipients...)
+		return nil
+	}
+}
+
+// WithIdentities adds the identities used to decrypt secrets.
+func WithIdentities(identities ...age.Identity) Options {
+	return func(c *config) error {
+		c.identities = append(c.identities, identities...)
+		return nil
+	}
+}
+
+// New opens the SQLite database at path, creating it if needed, and returns
+// a [store.Store] keeping its secrets in it. The database is created readable
+// only by the current user.
+//
+// At least one recipient and one identity must be provided, see
+// [WithRecipients] and [WithIdentities]. age supports passwords, X25519, SSH
+// and plugin keys as both.
+//
+// When built without cgo, New always returns an error, as the driver cannot
+// open databases.
+func New[T store.Secret](path string, f store.Factory[T], opts ...Options) (store.Store, error) {
+	cfg := &config{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	if len(cfg.recipients) == 0 {
+		return nil, errors.New("requires at least one recipient")
+	}
+	if len(cfg.identities) == 0 {
+		return nil, errors.New("requires at least one identity")
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	// writes take the database lock when the transaction starts, so that
+	// concurrent transactions wait for each other instead of failing.
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+	return &sqliteStore[T]{db: db, factory: f, config: cfg}, nil
+}
+
+func (s *sqliteStore[T]) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqliteStore[T]) Delete(ctx context.Context, id store.ID) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM secrets WHERE id = ?`, id.String()); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM labels WHERE id = ?`, id.String())
+		return err
+	})
+}
+
+func (s *sqliteStore[T]) Get(ctx context.Context, id store.ID) (store.Secret, error) {
+	var value []byte
+	var metadata string
+	err := s.db.QueryRowContext(ctx, `SELECT value, metadata FROM secrets WHERE id = ?`, id.String()).Scan(&value, &metadata)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrCredentialNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.newSecret(ctx, row{id: id, value: value, metadata: metadata})
+}
+
+func (s *sqliteStore[T]) GetAll(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return s.Filter(ctx, store.MustParsePattern("**"))
+}
+
+func (s *sqliteStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return s.FilterMetadata(ctx, store.MustParsePattern("**"))
+}
+
+func (s *sqliteStore[T]) Filter(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	rows, err := s.filter(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return s.getAll(ctx, rows)
+}
+
+func (s *sqliteStore[T]) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	rows, err := s.filter(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+	secrets := make(map[store.ID]store.Secret, len(rows))
+	for _, r := range rows {
+		// the value is never read, so listing never decrypts
+		secret, err := s.withMetadata(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		secrets[r.id] = secret
+	}
+	return secrets, nil
+}
+
+// FilterByLabels returns the secrets whose metadata contains all of labels,
+// see [store.FilterByLabels]. Only the matching secrets are read and
+// decrypted.
+func (s *sqliteStore[T]) FilterByLabels(ctx context.Context, labels map[string]string) (map[store.ID]store.Secret, error) {
+	if len(labels) == 0 {
+		return s.GetAll(ctx)
+	}
+	var matches []string
+	var args []any
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		matches = append(matches, `SELECT id FROM labels WHERE key = ? AND value = ?`)
+		args = append(args, k, labels[k])
+	}
+	query := `SELECT id, metadata FROM secrets WHERE id IN (` + strings.Join(matches, " INTERSECT ") + `) ORDER BY id`
+	rows, err := s.query(ctx, store.MustParsePattern("**"), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return s.getAll(ctx, rows)
+}
+
+func (s *sqliteStore[T]) Save(ctx context.Context, id store.ID, secret store.Secret) error {
+	value, metadata, err := s.encrypt(secret)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.write(ctx, tx, id, value, metadata)
+	})
+}
+
+// SaveIfAbsent saves the secret unless one already exists under id, see
+// [store.SaveIfAbsent]. The check and the write happen in one transaction.
+func (s *sqliteStore[T]) SaveIfAbsent(ctx context.Context, id store.ID, secret store.Secret) error {
+	value, metadata, err := s.encrypt(secret)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		var exists int
+		err := tx.QueryRowContext(ctx, `SELECT 1 FROM secrets WHERE id = ?`, id.String()).Scan(&exists)
+		if err == nil {
+			return store.ErrCredentialExists
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return s.write(ctx, tx, id, value, metadata)
+	})
+}
+
+func (s *sqliteStore[T]) Upsert(ctx context.Context, id store.ID, secret store.Secret) error {
+	return s.Save(ctx, id, secret)
+}
+
+// encrypt returns the encrypted value of secret and its metadata. It happens
+// before any transaction starts, so that other writers are not blocked by it.
+func (s *sqliteStore[T]) encrypt(secret store.Secret) ([]byte, map[string]string, error) {
+	if err := store.Validate(secret); err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := secret.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer clear(plaintext)
+
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, s.recipients...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, nil, err
+	}
+	return encrypted.Bytes(), secret.Metadata(), nil
+}
+
+// write replaces the secret stored under id and its labels.
+func (s *sqliteStore[T]) write(ctx context.Context, tx *sql.Tx, id store.ID, value []byte, metadata map[string]string) error {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO secrets (id, value, metadata) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET value = excluded.value, metadata = excluded.metadata`,
+		id.String(), value, string(encoded))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM labels WHERE id = ?`, id.String()); err != nil {
+		return err
+	}
+	for k, v := range metadata {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO labels (id, key, value) VALUES (?, ?, ?)`, id.String(), k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore[T]) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type row struct {
+	id       store.ID
+	value    []byte
+	metadata string
+}
+
+// filter returns the rows whose ID matches pattern, without their value.
+func (s *sqliteStore[T]) filter(ctx context.Context, pattern store.Pattern) ([]row, error) {
+	condition, args := prefixCondition(pattern)
+	return s.query(ctx, pattern, `SELECT id, metadata FROM secrets`+condition+` ORDER BY id`, args...)
+}
+
+// prefixCondition returns the WHERE clause selecting the IDs starting with
+// the literal components of pattern, those preceding its first wildcard, so
+// that the rows that cannot match are not read.
+func prefixCondition(pattern store.Pattern) (string, []any) {
+	components := strings.Split(pattern.String(), "/")
+	i := slices.IndexFunc(components, func(c string) bool {
+		return strings.Contains(c, "*")
+	})
+	switch i {
+	case -1:
+		return ` WHERE id = ?`, []any{pattern.String()}
+	case 0:
+		return "", nil
+	}
+	prefix := strings.Join(components[:i], "/")
+	// IDs under prefix sort between prefix+"/" and prefix+"0", as '0' follows
+	// '/'. A trailing '**' also matches prefix itself.
+	return ` WHERE id = ? OR (id >= ? AND id < ?)`, []any{prefix, prefix + "/", prefix + "0"}
+}
+
+// query returns the rows of query whose ID matches pattern. Query must select
+// the id and metadata columns: values are only read for the matching rows,
+// see [sqliteStore.getAll].
+func (s *sqliteStore[T]) query(ctx context.Context, pattern store.Pattern, query string, args ...any) ([]row, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []row
+	for rows.Next() {
+		var rawID string
+		var r row
+		if err := rows.Scan(&rawID, &r.metadata); err != nil {
+			return nil, err
+		}
+		r.id, err = store.ParseID(rawID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret ID %q in database: %w", rawID, err)
+		}
+		if pattern.Match(r.id) {
+			result = append(result, r)
+		}
+	}
+	return result, rows.Err()
+}
+
+// getAll reads and decrypts the secrets of rows. Secrets deleted since rows
+// were read are skipped.
+func (s *sqliteStore[T]) getAll(ctx context.Context, rows []row) (map[store.ID]store.Secret, error) {
+	secrets := make(map[store.ID]store.Secret, len(rows))
+	for _, r := range rows {
+		secret, err := s.Get(ctx, r.id)
+		if errors.Is(err, store.ErrCredentialNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		secrets[r.id] = secret
+	}
+	if len(secrets) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+	return secrets, nil
+}
+
+// newSecret decrypts the value of r and returns the secret it holds, with
+// its metadata.
+func (s *sqliteStore[T]) newSecret(ctx context.Context, r row) (store.Secret, error) {
+	reader, err := age.Decrypt(bytes.NewReader(r.value), s.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting secret %s: %w", r.id, err)
+	}
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting secret %s: %w", r.id, err)
+	}
+	defer clear(plaintext)
+
+	secret, err := s.withMetadata(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := secret.Unmarshal(plaintext); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (s *sqliteStore[T]) withMetadata(ctx context.Context, r row) (store.Secret, error) {
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(r.metadata), &metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata of secret %s: %w", r.id, err)
+	}
+	secret := s.factory(ctx, r.id)
+	if err := secret.SetMetadata(metadata); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
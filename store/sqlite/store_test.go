@@ -0,0 +1,249 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/mocks"
+)
+
+func newStore(t *testing.T, path string, identity *age.X25519Identity) store.Store {
+	t.Helper()
+	s, err := New(path,
+		func(_ context.Context, _ store.ID) *mocks.MockCredential {
+			return &mocks.MockCredential{}
+		},
+		WithRecipients(identity.Recipient()),
+		WithIdentities(identity),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, s.Close()) })
+	return s
+}
+
+func newIdentity(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return identity
+}
+
+func sortedIDs(secrets map[store.ID]store.Secret) []string {
+	var ids []string
+	for id := range secrets {
+		ids = append(ids, id.String())
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestNew(t *testing.T) {
+	identity := newIdentity(t)
+	factory := func(_ context.Context, _ store.ID) *mocks.MockCredential { return &mocks.MockCredential{} }
+	path := filepath.Join(t.TempDir(), "secrets.db")
+
+	_, err := New(path, factory, WithIdentities(identity))
+	assert.ErrorContains(t, err, "recipient")
+	_, err = New(path, factory, WithRecipients(identity.Recipient()))
+	assert.ErrorContains(t, err, "identity")
+
+	newStore(t, path, identity)
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+	}
+}
+
+func TestCRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.db")
+	identity := newIdentity(t)
+	s := newStore(t, path, identity)
+	id := store.MustParseID("db/password")
+
+	_, err := s.Get(t.Context(), id)
+	require.ErrorIs(t, err, store.ErrCredentialNotFound)
+	_, err = s.GetAllMetadata(t.Context())
+	require.ErrorIs(t, err, store.ErrCredentialNotFound)
+
+	require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{
+		Username:   "bob",
+		Password:   "secret",
+		Attributes: map[string]string{"team": "payments"},
+	}))
+	secret, err := s.Get(t.Context(), id)
+	require.NoError(t, err)
+	assert.Equal(t, &mocks.MockCredential{
+		Username:   "bob",
+		Password:   "secret",
+		Attributes: map[string]string{"team": "payments"},
+	}, secret)
+
+	t.Run("values are encrypted at rest", func(t *testing.T) {
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "bob:secret")
+	})
+	t.Run("upsert overwrites the value and metadata", func(t *testing.T) {
+		require.NoError(t, s.Upsert(t.Context(), id, &mocks.MockCredential{Username: "alice", Password: "other"}))
+		secret, err := s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, &mocks.MockCredential{Username: "alice", Password: "other", Attributes: map[string]string{}}, secret)
+		_, err = store.FilterByLabels(t.Context(), s, map[string]string{"team": "payments"})
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound, "labels of the previous value must be removed")
+	})
+	t.Run("metadata is listed without decrypting", func(t *testing.T) {
+		secrets, err := s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		require.Contains(t, secrets, id)
+		assert.Empty(t, secrets[id].(*mocks.MockCredential).Password)
+	})
+	t.Run("save if absent", func(t *testing.T) {
+		err := store.SaveIfAbsent(t.Context(), s, id, &mocks.MockCredential{Username: "eve", Password: "x"})
+		require.ErrorIs(t, err, store.ErrCredentialExists)
+		other := store.MustParseID("db/other")
+		require.NoError(t, store.SaveIfAbsent(t.Context(), s, other, &mocks.MockCredential{Username: "eve", Password: "x"}))
+		require.NoError(t, s.Delete(t.Context(), other))
+	})
+	t.Run("the database can be reopened", func(t *testing.T) {
+		reopened := newStore(t, path, identity)
+		secret, err := reopened.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", secret.(*mocks.MockCredential).Username)
+	})
+	t.Run("other identities cannot decrypt", func(t *testing.T) {
+		other := newStore(t, path, newIdentity(t))
+		_, err := other.Get(t.Context(), id)
+		assert.ErrorContains(t, err, "decrypting secret db/password")
+	})
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, s.Delete(t.Context(), id))
+		_, err := s.Get(t.Context(), id)
+		require.ErrorIs(t, err, store.ErrCredentialNotFound)
+		assert.NoError(t, s.Delete(t.Context(), id), "deleting a missing secret is not an error")
+	})
+}
+
+func TestFilter(t *testing.T) {
+	s := newStore(t, filepath.Join(t.TempDir(), "secrets.db"), newIdentity(t))
+	for _, id := range []string{"app", "app/a", "app/b", "app/nested/c", "app0", "apps/a", "other/a"} {
+		require.NoError(t, s.Save(t.Context(), store.MustParseID(id), &mocks.MockCredential{Username: id, Password: "pw"}))
+	}
+
+	secrets, err := s.Filter(t.Context(), store.MustParsePattern("app/*"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app/a", "app/b"}, sortedIDs(secrets))
+	assert.Equal(t, "app/a", secrets[store.MustParseID("app/a")].(*mocks.MockCredential).Username)
+
+	secrets, err = s.FilterMetadata(t.Context(), store.MustParsePattern("app/**"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "app/a", "app/b", "app/nested/c"}, sortedIDs(secrets))
+
+	secrets, err = s.Filter(t.Context(), store.MustParsePattern("app/nested/c"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app/nested/c"}, sortedIDs(secrets))
+
+	secrets, err = s.FilterMetadata(t.Context(), store.MustParsePattern("*/a"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app/a", "apps/a", "other/a"}, sortedIDs(secrets))
+
+	secrets, err = s.GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, secrets, 7)
+
+	_, err = s.Filter(t.Context(), store.MustParsePattern("unknown/*"))
+	assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+}
+
+func TestPrefixCondition(t *testing.T) {
+	for pattern, expected := range map[string]struct {
+		condition string
+		args      []any
+	}{
+		"**":       {"", nil},
+		"*/a":      {"", nil},
+		"app/a":    {" WHERE id = ?", []any{"app/a"}},
+		"app/*/b":  {" WHERE id = ? OR (id >= ? AND id < ?)", []any{"app", "app/", "app0"}},
+		"app/b/**": {" WHERE id = ? OR (id >= ? AND id < ?)", []any{"app/b", "app/b/", "app/b0"}},
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			condition, args := prefixCondition(store.MustParsePattern(pattern))
+			assert.Equal(t, expected.condition, condition)
+			assert.Equal(t, expected.args, args)
+		})
+	}
+}
+
+func TestFilterByLabels(t *testing.T) {
+	s := newStore(t, filepath.Join(t.TempDir(), "secrets.db"), newIdentity(t))
+	for id, labels := range map[string]map[string]string{
+		"payments/db":  {"team": "payments", "env": "prod"},
+		"payments/api": {"team": "payments", "env": "dev"},
+		"search/db":    {"team": "search", "env": "prod"},
+		"unlabeled":    nil,
+	} {
+		require.NoError(t, s.Save(t.Context(), store.MustParseID(id), &mocks.MockCredential{
+			Username:   id,
+			Password:   "pw",
+			Attributes: labels,
+		}))
+	}
+
+	filtered := func(labels map[string]string) []string {
+		t.Helper()
+		secrets, err := store.FilterByLabels(t.Context(), s, labels)
+		require.NoError(t, err)
+		return sortedIDs(secrets)
+	}
+	assert.Equal(t, []string{"payments/api", "payments/db"}, filtered(map[string]string{"team": "payments"}))
+	assert.Equal(t, []string{"payments/db", "search/db"}, filtered(map[string]string{"env": "prod"}))
+	assert.Equal(t, []string{"payments/db"}, filtered(map[string]string{"team": "payments", "env": "prod"}))
+	assert.Equal(t, []string{"payments/api", "payments/db", "search/db", "unlabeled"}, filtered(nil))
+
+	_, err := store.FilterByLabels(t.Context(), s, map[string]string{"team": "payments", "env": "staging"})
+	assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.db")
+	identity := newIdentity(t)
+	stores := []store.Store{newStore(t, path, identity), newStore(t, path, identity)}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			id := store.MustParseID("secret/" + string(rune('a'+i)))
+			assert.NoError(t, stores[i%2].Save(t.Context(), id, &mocks.MockCredential{Username: "u", Password: "p"}))
+		})
+	}
+	wg.Wait()
+
+	secrets, err := stores[0].GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, secrets, 20)
+}
@@ -16,18 +16,21 @@ package store
 
 import (
 	"context"
+	"io"
 
 	"github.com/docker/secrets-engine/x/secrets"
 )
 
 type (
-	ID      = secrets.ID
-	Pattern = secrets.Pattern
+	ID          = secrets.ID
+	VersionedID = secrets.VersionedID
+	Pattern     = secrets.Pattern
 )
 
 var (
 	ParseID          = secrets.ParseID
 	MustParseID      = secrets.MustParseID
+	ParseVersionedID = secrets.ParseVersionedID
 	ParsePattern     = secrets.ParsePattern
 	MustParsePattern = secrets.MustParsePattern
 )
@@ -71,13 +74,43 @@ type Secret interface {
 	SetMetadata(map[string]string) error
 }
 
+// Validator can optionally be implemented by a [Secret] to reject malformed
+// values before they are persisted, e.g. an empty value or a credential
+// missing a required attribute.
+//
+// Store backends call Validate from [Store.Save] and [Store.Upsert] before
+// writing anything; a non-nil error is returned to the caller as is.
+type Validator interface {
+	Validate() error
+}
+
+// Validate calls [Validator.Validate] if the secret implements [Validator].
+// Secrets that don't implement it are always valid.
+func Validate(secret Secret) error {
+	if v, ok := secret.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
 // Store defines a strict format for secrets to conform to when interacting
 // with the secrets engine
 type Store interface {
+	// Closer releases any resources held by the store. The store must not be
+	// used after Close has been called.
+	io.Closer
+
 	// Delete removes credentials from the store for a given ID.
 	Delete(ctx context.Context, id ID) error
 	// Get retrieves credentials from the store for a given ID.
 	Get(ctx context.Context, id ID) (Secret, error)
+	// GetAll retrieves all the credentials from the store, including their
+	// sensitive data. It is equivalent to calling [Store.Filter] with the "**"
+	// pattern.
+	//
+	// Prefer [Store.GetAllMetadata] when the values are not needed, since
+	// reading them may require the underlying store to be unlocked.
+	GetAll(ctx context.Context) (map[ID]Secret, error)
 	// GetAllMetadata retrieves all the credentials from the store.
 	// Credentials retrieved will only call [Secret.SetMetadata] so that the
 	// underlying store does not get queried for each secret's sensitive data.
@@ -97,6 +130,15 @@ type Store interface {
 	// called; in that order. Any error produced by any of them would result in
 	// an early return with a nil secrets map.
 	Filter(ctx context.Context, pattern Pattern) (map[ID]Secret, error)
+	// FilterMetadata returns a map of secrets based on a [Pattern] without
+	// retrieving their sensitive data.
+	//
+	// Like [Store.GetAllMetadata], secrets returned will only have
+	// [Secret.SetMetadata] called, so that listing secrets never triggers
+	// a decryption or an unlock prompt of the underlying store.
+	//
+	// [ErrCredentialNotFound] is returned when no secret matches.
+	FilterMetadata(ctx context.Context, pattern Pattern) (map[ID]Secret, error)
 }
 
 type Factory[T Secret] func(context.Context, ID) T
@@ -0,0 +1,73 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// authMethod retrieves a Vault token.
+type authMethod interface {
+	login(ctx context.Context, c *kvClient) (string, error)
+	// renewable reports whether calling login again may produce a new token
+	// after the previous one expired.
+	renewable() bool
+}
+
+type tokenAuth struct {
+	token string
+}
+
+func (t tokenAuth) login(context.Context, *kvClient) (string, error) {
+	if t.token == "" {
+		return "", errors.New("no token provided")
+	}
+	return t.token, nil
+}
+
+func (t tokenAuth) renewable() bool {
+	return false
+}
+
+type appRoleAuth struct {
+	mount    string
+	roleID   string
+	secretID string
+}
+
+func (a appRoleAuth) login(ctx context.Context, c *kvClient) (string, error) {
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	body := map[string]string{
+		"role_id":   a.roleID,
+		"secret_id": a.secretID,
+	}
+	if err := c.send(ctx, http.MethodPost, "auth/"+a.mount+"/login", nil, "", body, &resp); err != nil {
+		return "", err
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("approle login did not return a client token")
+	}
+	return resp.Auth.ClientToken, nil
+}
+
+func (a appRoleAuth) renewable() bool {
+	return true
+}
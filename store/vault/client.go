@@ -0,0 +1,270 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// errNotFound is returned by the client when Vault responds with a 404.
+var errNotFound = errors.New("vault: not found")
+
+// ResponseError is returned when Vault responds with an unexpected status
+// code.
+type ResponseError struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *ResponseError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("vault: unexpected status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("vault: unexpected status code %d: %s", e.StatusCode, strings.Join(e.Errors, ", "))
+}
+
+// kvData is the data section of a KV v2 read response.
+type kvData struct {
+	Data     map[string]any `json:"data"`
+	Metadata struct {
+		CustomMetadata map[string]string `json:"custom_metadata"`
+	} `json:"metadata"`
+}
+
+// kvMetadata is the data section of a KV v2 metadata read response.
+type kvMetadata struct {
+	CurrentVersion int                  `json:"current_version"`
+	CustomMetadata map[string]string    `json:"custom_metadata"`
+	Versions       map[string]kvVersion `json:"versions"`
+}
+
+type kvVersion struct {
+	DeletionTime string `json:"deletion_time"`
+	Destroyed    bool   `json:"destroyed"`
+}
+
+// deleted reports whether the current version has been soft-deleted or
+// destroyed.
+func (m kvMetadata) deleted() bool {
+	if m.CurrentVersion == 0 {
+		return true
+	}
+	v, ok := m.Versions[strconv.Itoa(m.CurrentVersion)]
+	return ok && (v.DeletionTime != "" || v.Destroyed)
+}
+
+// kvClient is a minimal client for the Vault KV v2 HTTP API.
+type kvClient struct {
+	httpClient *http.Client
+	address    string
+	mount      string
+	namespace  string
+	auth       authMethod
+
+	mu    sync.Mutex
+	token string
+}
+
+func (c *kvClient) getToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" {
+		return c.token, nil
+	}
+	token, err := c.auth.login(ctx, c)
+	if err != nil {
+		return "", fmt.Errorf("vault: authentication failed: %w", err)
+	}
+	c.token = token
+	return token, nil
+}
+
+// resetToken drops the cached token so that the next request logs in again.
+func (c *kvClient) resetToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+}
+
+// do sends an authenticated request to Vault and decodes the JSON response
+// into out, if out is non-nil.
+//
+// Requests rejected with a 403 are retried once after logging in again for
+// authentication methods that can renew their token.
+func (c *kvClient) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	token, err := c.getToken(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.send(ctx, method, path, query, token, body, out)
+	var respErr *ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden && c.auth.renewable() {
+		c.resetToken()
+		if token, err = c.getToken(ctx); err != nil {
+			return err
+		}
+		return c.send(ctx, method, path, query, token, body, out)
+	}
+	return err
+}
+
+func (c *kvClient) send(ctx context.Context, method, path string, query url.Values, token string, body, out any) error {
+	u, err := url.Parse(c.address)
+	if err != nil {
+		return err
+	}
+	u = u.JoinPath("v1", path)
+	u.RawQuery = query.Encode()
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode == http.StatusNoContent:
+		return nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		respErr := &ResponseError{StatusCode: resp.StatusCode}
+		var errBody struct {
+			Errors []string `json:"errors"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&errBody) == nil {
+			respErr.Errors = errBody.Errors
+		}
+		return respErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// dataPath returns the API path of the KV v2 data endpoint for a secret.
+func (c *kvClient) dataPath(path string) string {
+	return c.mount + "/data/" + path
+}
+
+// metadataPath returns the API path of the KV v2 metadata endpoint for a
+// secret or folder.
+func (c *kvClient) metadataPath(path string) string {
+	return c.mount + "/metadata/" + path
+}
+
+func (c *kvClient) readData(ctx context.Context, path string) (*kvData, error) {
+	var resp struct {
+		Data kvData `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.dataPath(path), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *kvClient) writeData(ctx context.Context, path string, data map[string]any) error {
+	return c.do(ctx, http.MethodPost, c.dataPath(path), nil, map[string]any{"data": data}, nil)
+}
+
+func (c *kvClient) deleteData(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, c.dataPath(path), nil, nil, nil)
+}
+
+func (c *kvClient) readMetadata(ctx context.Context, path string) (*kvMetadata, error) {
+	var resp struct {
+		Data kvMetadata `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.metadataPath(path), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *kvClient) writeMetadata(ctx context.Context, path string, metadata map[string]string) error {
+	if metadata == nil {
+		// an empty map clears any previously stored custom metadata
+		metadata = map[string]string{}
+	}
+	return c.do(ctx, http.MethodPost, c.metadataPath(path), nil, map[string]any{"custom_metadata": metadata}, nil)
+}
+
+// list returns the paths of all secrets below the folder prefix, walking
+// sub-folders recursively. An empty prefix lists the whole mount.
+func (c *kvClient) list(ctx context.Context, prefix string) ([]string, error) {
+	var resp struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, c.metadataPath(prefix), url.Values{"list": {"true"}}, nil, &resp)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, key := range resp.Data.Keys {
+		if strings.HasSuffix(key, "/") {
+			sub, err := c.list(ctx, prefix+key)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, sub...)
+			continue
+		}
+		paths = append(paths, prefix+key)
+	}
+	return paths, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// integrationAddrEnv points the integration tests at a Vault dev server, e.g.
+//
+//	vault server -dev -dev-root-token-id=root
+//	VAULT_INTEGRATION_ADDR=http://127.0.0.1:8200 VAULT_INTEGRATION_TOKEN=root go test ./vault/...
+const (
+	integrationAddrEnv  = "VAULT_INTEGRATION_ADDR"
+	integrationTokenEnv = "VAULT_INTEGRATION_TOKEN"
+)
+
+func TestVaultIntegration(t *testing.T) {
+	addr := os.Getenv(integrationAddrEnv)
+	if addr == "" {
+		t.Skipf("%s is not set", integrationAddrEnv)
+	}
+	s, err := New(newMockCredential, WithAddress(addr), WithToken(os.Getenv(integrationTokenEnv)))
+	require.NoError(t, err)
+	testStore(t, s)
+}
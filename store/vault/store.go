@@ -0,0 +1,441 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/x/logging"
+)
+
+const (
+	// AddressEnv is the environment variable used for the Vault address
+	// when [WithAddress] is not provided.
+	AddressEnv = "VAULT_ADDR"
+	// TokenEnv is the environment variable used for the Vault token when
+	// no authentication option is provided.
+	TokenEnv = "VAULT_TOKEN"
+	// NamespaceEnv is the environment variable used for the Vault namespace
+	// when [WithNamespace] is not provided.
+	NamespaceEnv = "VAULT_NAMESPACE"
+
+	// DefaultMount is the mount path of the KV v2 secrets engine used when
+	// [WithMount] is not provided.
+	DefaultMount = "secret"
+	// DataKey is the key under which the secret value is stored in the
+	// KV v2 data map.
+	DataKey = "value"
+	// EncodingKey is set to "base64" in the KV v2 data map when the secret
+	// value is not valid UTF-8 and had to be encoded.
+	EncodingKey = "encoding"
+
+	encodingBase64 = "base64"
+)
+
+var _ store.Store = &vaultStore[store.Secret]{}
+
+type vaultStore[T store.Secret] struct {
+	client         *kvClient
+	factory        store.Factory[T]
+	logger         logging.Logger
+	ownsHTTPClient bool
+}
+
+// Close releases the idle connections of the HTTP client created by the
+// store. A client provided through [WithHTTPClient] is left untouched.
+func (v *vaultStore[T]) Close() error {
+	if v.ownsHTTPClient {
+		v.client.httpClient.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (v *vaultStore[T]) Delete(ctx context.Context, id store.ID) error {
+	err := v.client.deleteData(ctx, id.String())
+	if errors.Is(err, errNotFound) {
+		return store.ErrCredentialNotFound
+	}
+	return err
+}
+
+func (v *vaultStore[T]) Get(ctx context.Context, id store.ID) (store.Secret, error) {
+	data, err := v.client.readData(ctx, id.String())
+	if errors.Is(err, errNotFound) {
+		return nil, store.ErrCredentialNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.loadSecret(ctx, id, data)
+}
+
+func (v *vaultStore[T]) GetAll(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return v.Filter(ctx, store.MustParsePattern("**"))
+}
+
+func (v *vaultStore[T]) GetAllMetadata(ctx context.Context) (map[store.ID]store.Secret, error) {
+	return v.FilterMetadata(ctx, store.MustParsePattern("**"))
+}
+
+func (v *vaultStore[T]) FilterMetadata(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	paths, err := v.client.list(ctx, listPrefix(pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := map[store.ID]store.Secret{}
+	for _, path := range paths {
+		id, err := store.ParseID(path)
+		if err != nil {
+			v.logger.Warnf("could not parse vault path %s to secret ID: %s", path, err)
+			continue
+		}
+		if !pattern.Match(id) {
+			continue
+		}
+
+		metadata, err := v.client.readMetadata(ctx, path)
+		if errors.Is(err, errNotFound) {
+			// the secret was removed since listing
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if metadata.deleted() {
+			continue
+		}
+
+		secret := v.factory(ctx, id)
+		if err := secret.SetMetadata(metadata.CustomMetadata); err != nil {
+			return nil, err
+		}
+		secrets[id] = secret
+	}
+
+	if len(secrets) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+	return secrets, nil
+}
+
+// Save writes the value of the secret, then its metadata. The two writes are
+// not atomic: readers can briefly see the new value with the previous
+// metadata. When the metadata cannot be written, the previous value is
+// restored.
+func (v *vaultStore[T]) Save(ctx context.Context, id store.ID, s store.Secret) error {
+	if err := store.Validate(s); err != nil {
+		return err
+	}
+
+	value, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	defer clear(value)
+
+	data := map[string]any{}
+	if utf8.Valid(value) {
+		data[DataKey] = string(value)
+	} else {
+		data[DataKey] = base64.StdEncoding.EncodeToString(value)
+		data[EncodingKey] = encodingBase64
+	}
+
+	// KV v2 stores the data and the custom metadata through separate
+	// endpoints, keep the current value to restore it when the metadata
+	// cannot be written
+	previous, err := v.client.readData(ctx, id.String())
+	if errors.Is(err, errNotFound) {
+		previous = nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := v.client.writeData(ctx, id.String(), data); err != nil {
+		return err
+	}
+	if err := v.client.writeMetadata(ctx, id.String(), s.Metadata()); err != nil {
+		return errors.Join(err, v.rollback(context.WithoutCancel(ctx), id, previous))
+	}
+	return nil
+}
+
+// rollback restores the value a secret had before a failed [vaultStore.Save].
+// A secret that had no value is soft-deleted again.
+func (v *vaultStore[T]) rollback(ctx context.Context, id store.ID, previous *kvData) error {
+	var err error
+	if previous == nil {
+		err = v.client.deleteData(ctx, id.String())
+	} else {
+		err = v.client.writeData(ctx, id.String(), previous.Data)
+	}
+	if err != nil {
+		return fmt.Errorf("vault secret %s: could not restore the previous value: %w", id, err)
+	}
+	return nil
+}
+
+// Upsert behaves the same as [vaultStore.Save], since writing to KV v2
+// always creates a new version of the secret.
+func (v *vaultStore[T]) Upsert(ctx context.Context, id store.ID, s store.Secret) error {
+	return v.Save(ctx, id, s)
+}
+
+func (v *vaultStore[T]) Filter(ctx context.Context, pattern store.Pattern) (map[store.ID]store.Secret, error) {
+	paths, err := v.client.list(ctx, listPrefix(pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := map[store.ID]store.Secret{}
+	for _, path := range paths {
+		id, err := store.ParseID(path)
+		if err != nil {
+			v.logger.Warnf("could not parse vault path %s to secret ID: %s", path, err)
+			continue
+		}
+		if !pattern.Match(id) {
+			continue
+		}
+
+		data, err := v.client.readData(ctx, path)
+		// deleted secrets are still listed by the metadata endpoint
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		secret, err := v.loadSecret(ctx, id, data)
+		if err != nil {
+			return nil, err
+		}
+		secrets[id] = secret
+	}
+
+	if len(secrets) == 0 {
+		return nil, store.ErrCredentialNotFound
+	}
+	return secrets, nil
+}
+
+func (v *vaultStore[T]) loadSecret(ctx context.Context, id store.ID, data *kvData) (store.Secret, error) {
+	raw, ok := data.Data[DataKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault secret %s has no %q key", id, DataKey)
+	}
+
+	value := []byte(raw)
+	if encoding, _ := data.Data[EncodingKey].(string); encoding == encodingBase64 {
+		decoded, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("vault secret %s: %w", id, err)
+		}
+		value = decoded
+	}
+	defer clear(value)
+
+	secret := v.factory(ctx, id)
+	if err := secret.SetMetadata(data.Metadata.CustomMetadata); err != nil {
+		return nil, err
+	}
+	if err := secret.Unmarshal(value); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// listPrefix returns the folder holding every secret the pattern can match,
+// which is the leading run of literal components.
+func listPrefix(pattern store.Pattern) string {
+	var prefix strings.Builder
+	components := strings.Split(pattern.String(), "/")
+	// the last component is a secret name and never a folder
+	for _, c := range components[:len(components)-1] {
+		if strings.Contains(c, "*") {
+			break
+		}
+		prefix.WriteString(c + "/")
+	}
+	return prefix.String()
+}
+
+type config struct {
+	httpClient *http.Client
+	address    string
+	mount      string
+	namespace  string
+	auth       authMethod
+	logger     logging.Logger
+}
+
+type Options func(c *config) error
+
+// WithAddress sets the address of the Vault server, e.g.
+// "https://vault.example.com:8200".
+//
+// If not provided, the VAULT_ADDR environment variable is used.
+func WithAddress(address string) Options {
+	return func(c *config) error {
+		if address == "" {
+			return errors.New("vault address cannot be empty")
+		}
+		c.address = address
+		return nil
+	}
+}
+
+// WithToken authenticates all requests with a static Vault token.
+//
+// If no authentication option is provided, the VAULT_TOKEN environment
+// variable is used.
+func WithToken(token string) Options {
+	return func(c *config) error {
+		if token == "" {
+			return errors.New("vault token cannot be empty")
+		}
+		if c.auth != nil {
+			return errors.New("vault authentication method already configured")
+		}
+		c.auth = tokenAuth{token: token}
+		return nil
+	}
+}
+
+// WithAppRole authenticates using the AppRole auth method mounted at mount
+// (usually "approle").
+//
+// The login happens on the first request and is repeated when Vault rejects
+// the token, e.g. after it expired.
+func WithAppRole(mount, roleID, secretID string) Options {
+	return func(c *config) error {
+		if mount == "" || roleID == "" {
+			return errors.New("approle mount and role ID cannot be empty")
+		}
+		if c.auth != nil {
+			return errors.New("vault authentication method already configured")
+		}
+		c.auth = appRoleAuth{mount: mount, roleID: roleID, secretID: secretID}
+		return nil
+	}
+}
+
+// WithMount sets the mount path of the KV v2 secrets engine.
+// It defaults to [DefaultMount].
+func WithMount(mount string) Options {
+	return func(c *config) error {
+		mount = strings.Trim(mount, "/")
+		if mount == "" {
+			return errors.New("vault mount cannot be empty")
+		}
+		c.mount = mount
+		return nil
+	}
+}
+
+// WithNamespace sets the Vault Enterprise namespace used for all requests.
+//
+// If not provided, the VAULT_NAMESPACE environment variable is used.
+func WithNamespace(namespace string) Options {
+	return func(c *config) error {
+		c.namespace = namespace
+		return nil
+	}
+}
+
+// WithHTTPClient overrides the HTTP client used to communicate with Vault,
+// e.g. to configure TLS.
+func WithHTTPClient(client *http.Client) Options {
+	return func(c *config) error {
+		if client == nil {
+			return errors.New("http client cannot be nil")
+		}
+		c.httpClient = client
+		return nil
+	}
+}
+
+// WithLogger adds a custom logger to the store.
+// If a no logger has been specified, a noop logger is used instead.
+func WithLogger(l logging.Logger) Options {
+	return func(c *config) error {
+		c.logger = l
+		return nil
+	}
+}
+
+// New returns a [store.Store] backed by a HashiCorp Vault KV v2 secrets
+// engine.
+//
+// Each secret is stored at the path matching its ID. The secret value is
+// written to the [DataKey] of the KV data and its metadata is stored as
+// Vault custom metadata. Values that are not valid UTF-8 are stored base64
+// encoded, which is recorded under [EncodingKey].
+//
+// Deleting a secret performs a soft-delete of its latest version.
+func New[T store.Secret](f store.Factory[T], opts ...Options) (store.Store, error) {
+	cfg := &config{
+		address:   os.Getenv(AddressEnv),
+		mount:     DefaultMount,
+		namespace: os.Getenv(NamespaceEnv),
+		logger:    &noopLogger{},
+	}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.address == "" {
+		return nil, fmt.Errorf("no vault address provided, use WithAddress or set %s", AddressEnv)
+	}
+	if cfg.auth == nil {
+		token := os.Getenv(TokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("no vault authentication provided, use WithToken, WithAppRole or set %s", TokenEnv)
+		}
+		cfg.auth = tokenAuth{token: token}
+	}
+
+	ownsHTTPClient := cfg.httpClient == nil
+	if ownsHTTPClient {
+		cfg.httpClient = &http.Client{
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		}
+	}
+
+	return &vaultStore[T]{
+		client: &kvClient{
+			httpClient: cfg.httpClient,
+			address:    cfg.address,
+			mount:      cfg.mount,
+			namespace:  cfg.namespace,
+			auth:       cfg.auth,
+		},
+		factory:        f,
+		logger:         cfg.logger,
+		ownsHTTPClient: ownsHTTPClient,
+	}, nil
+}
@@ -0,0 +1,464 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/store"
+	"github.com/docker/secrets-engine/store/mocks"
+)
+
+type fakeEntry struct {
+	data    map[string]any
+	custom  map[string]string
+	version int
+	deleted bool
+}
+
+// fakeVault implements the subset of the Vault KV v2 and AppRole HTTP APIs
+// used by the store.
+type fakeVault struct {
+	mu      sync.Mutex
+	token   string
+	entries map[string]*fakeEntry
+	logins  int
+	// failMetadata rejects the writes to the metadata endpoint
+	failMetadata bool
+}
+
+func newFakeVault(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	f := &fakeVault{token: token, entries: map[string]*fakeEntry{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.logins++
+		writeJSON(w, map[string]any{"auth": map[string]any{"client_token": f.token}})
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		writeJSON(w, map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		f.serveData(w, r, strings.TrimPrefix(r.URL.Path, "/v1/secret/data/"))
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/"):
+		f.serveMetadata(w, r, strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeVault) serveData(w http.ResponseWriter, r *http.Request, path string) {
+	entry, ok := f.entries[path]
+	switch r.Method {
+	case http.MethodGet:
+		if !ok || entry.deleted || entry.data == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]any{"data": map[string]any{
+			"data":     entry.data,
+			"metadata": map[string]any{"custom_metadata": entry.custom, "version": entry.version},
+		}})
+	case http.MethodPost:
+		var body struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			entry = &fakeEntry{}
+			f.entries[path] = entry
+		}
+		entry.data = body.Data
+		entry.version++
+		entry.deleted = false
+		writeJSON(w, map[string]any{"data": map[string]any{"version": entry.version}})
+	case http.MethodDelete:
+		if ok {
+			entry.deleted = true
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeVault) serveMetadata(w http.ResponseWriter, r *http.Request, path string) {
+	if r.URL.Query().Get("list") == "true" {
+		keys := map[string]struct{}{}
+		for p := range f.entries {
+			rest, ok := strings.CutPrefix(p, path)
+			if !ok || rest == "" {
+				continue
+			}
+			if folder, _, isFolder := strings.Cut(rest, "/"); isFolder {
+				keys[folder+"/"] = struct{}{}
+			} else {
+				keys[rest] = struct{}{}
+			}
+		}
+		if len(keys) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		list := make([]string, 0, len(keys))
+		for k := range keys {
+			list = append(list, k)
+		}
+		slices.Sort(list)
+		writeJSON(w, map[string]any{"data": map[string]any{"keys": list}})
+		return
+	}
+
+	entry, ok := f.entries[path]
+	switch r.Method {
+	case http.MethodGet:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		deletionTime := ""
+		if entry.deleted {
+			deletionTime = "2026-01-01T00:00:00Z"
+		}
+		writeJSON(w, map[string]any{"data": map[string]any{
+			"current_version": entry.version,
+			"custom_metadata": entry.custom,
+			"versions": map[string]any{
+				strconv.Itoa(entry.version): map[string]any{"deletion_time": deletionTime, "destroyed": false},
+			},
+		}})
+	case http.MethodPost:
+		if f.failMetadata {
+			w.WriteHeader(http.StatusInternalServerError)
+			writeJSON(w, map[string]any{"errors": []string{"internal error"}})
+			return
+		}
+		var body struct {
+			CustomMetadata map[string]string `json:"custom_metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			entry = &fakeEntry{}
+			f.entries[path] = entry
+		}
+		entry.custom = body.CustomMetadata
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newMockCredential(_ context.Context, _ store.ID) *mocks.MockCredential {
+	return &mocks.MockCredential{}
+}
+
+func TestVault(t *testing.T) {
+	srv := newFakeVault(t, "root")
+	s, err := New(newMockCredential, WithAddress(srv.URL), WithToken("root"))
+	require.NoError(t, err)
+	testStore(t, s)
+}
+
+// testStore exercises a [store.Store] backed by an empty KV v2 mount.
+func testStore(t *testing.T, s store.Store) {
+	t.Helper()
+	prefix := "vault-test-" + uuid.NewString()
+
+	t.Run("get unknown secret", func(t *testing.T) {
+		_, err := s.Get(t.Context(), store.MustParseID(prefix+"/unknown"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+
+	t.Run("save and get", func(t *testing.T) {
+		id := store.MustParseID(prefix + "/save/" + uuid.NewString())
+		creds := &mocks.MockCredential{
+			Username: "bob",
+			Password: "bob-password",
+			Attributes: map[string]string{
+				"color": "blue",
+			},
+		}
+		require.NoError(t, s.Save(t.Context(), id, creds))
+
+		secret, err := s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, creds, secret)
+	})
+
+	t.Run("non UTF-8 values are preserved", func(t *testing.T) {
+		id := store.MustParseID(prefix + "/binary/" + uuid.NewString())
+		creds := &mocks.MockCredential{Username: "\xff\xfe", Password: "\x00\x80"}
+		require.NoError(t, s.Save(t.Context(), id, creds))
+
+		secret, err := s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, creds.Username, secret.(*mocks.MockCredential).Username)
+		assert.Equal(t, creds.Password, secret.(*mocks.MockCredential).Password)
+	})
+
+	t.Run("upsert overwrites secret and metadata", func(t *testing.T) {
+		id := store.MustParseID(prefix + "/upsert/" + uuid.NewString())
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{
+			Username:   "bob",
+			Password:   "old",
+			Attributes: map[string]string{"color": "blue"},
+		}))
+		updated := &mocks.MockCredential{
+			Username:   "bob",
+			Password:   "new",
+			Attributes: map[string]string{"shape": "round"},
+		}
+		require.NoError(t, s.Upsert(t.Context(), id, updated))
+
+		secret, err := s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, updated, secret)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		id := store.MustParseID(prefix + "/delete/" + uuid.NewString())
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pass"}))
+		require.NoError(t, s.Delete(t.Context(), id))
+
+		_, err := s.Get(t.Context(), id)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+
+	t.Run("filter and metadata", func(t *testing.T) {
+		base := prefix + "/filter"
+		for _, name := range []string{"a/one", "a/two", "b/three", "deleted"} {
+			require.NoError(t, s.Save(t.Context(), store.MustParseID(base+"/"+name), &mocks.MockCredential{
+				Username:   name,
+				Password:   "pass",
+				Attributes: map[string]string{"name": name},
+			}))
+		}
+		require.NoError(t, s.Delete(t.Context(), store.MustParseID(base+"/deleted")))
+
+		secrets, err := s.Filter(t.Context(), store.MustParsePattern(base+"/a/*"))
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2)
+		for id, secret := range secrets {
+			assert.True(t, strings.HasPrefix(id.String(), base+"/a/"))
+			assert.Equal(t, "pass", secret.(*mocks.MockCredential).Password)
+		}
+
+		secrets, err = s.Filter(t.Context(), store.MustParsePattern(base+"/**"))
+		require.NoError(t, err)
+		assert.Len(t, secrets, 3)
+
+		_, err = s.Filter(t.Context(), store.MustParsePattern(base+"/unknown/**"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+
+		metadata, err := s.FilterMetadata(t.Context(), store.MustParsePattern(base+"/a/**"))
+		require.NoError(t, err)
+		assert.Len(t, metadata, 2)
+		for _, secret := range metadata {
+			assert.Empty(t, secret.(*mocks.MockCredential).Password)
+		}
+
+		all, err := s.GetAllMetadata(t.Context())
+		require.NoError(t, err)
+		secret, ok := all[store.MustParseID(base+"/b/three")]
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"name": "b/three"}, secret.Metadata())
+		assert.Empty(t, secret.(*mocks.MockCredential).Password)
+		assert.NotContains(t, all, store.MustParseID(base+"/deleted"))
+
+		withValues, err := s.GetAll(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, withValues, len(all))
+		assert.NotEmpty(t, withValues[store.MustParseID(base+"/b/three")].(*mocks.MockCredential).Password)
+	})
+}
+
+func TestClose(t *testing.T) {
+	t.Run("leaves a provided http client untouched", func(t *testing.T) {
+		srv := newFakeVault(t, "root")
+		s, err := New(newMockCredential, WithAddress(srv.URL), WithToken("root"), WithHTTPClient(srv.Client()))
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+		_, err = s.Get(t.Context(), store.MustParseID("foo"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+	t.Run("closes idle connections of its own client", func(t *testing.T) {
+		srv := newFakeVault(t, "root")
+		s, err := New(newMockCredential, WithAddress(srv.URL), WithToken("root"))
+		require.NoError(t, err)
+		_, err = s.Get(t.Context(), store.MustParseID("foo"))
+		require.ErrorIs(t, err, store.ErrCredentialNotFound)
+		assert.NoError(t, s.Close())
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("requires an address", func(t *testing.T) {
+		t.Setenv(AddressEnv, "")
+		_, err := New(newMockCredential, WithToken("root"))
+		assert.ErrorContains(t, err, "no vault address provided")
+	})
+	t.Run("requires authentication", func(t *testing.T) {
+		t.Setenv(TokenEnv, "")
+		_, err := New(newMockCredential, WithAddress("http://127.0.0.1:8200"))
+		assert.ErrorContains(t, err, "no vault authentication provided")
+	})
+	t.Run("reads address and token from the environment", func(t *testing.T) {
+		srv := newFakeVault(t, "env-token")
+		t.Setenv(AddressEnv, srv.URL)
+		t.Setenv(TokenEnv, "env-token")
+		s, err := New(newMockCredential)
+		require.NoError(t, err)
+		_, err = s.Get(t.Context(), store.MustParseID("foo"))
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+	t.Run("cannot combine authentication methods", func(t *testing.T) {
+		_, err := New(newMockCredential, WithAddress("http://127.0.0.1:8200"), WithToken("root"), WithAppRole("approle", "role", "secret"))
+		assert.ErrorContains(t, err, "already configured")
+	})
+}
+
+func TestAuthentication(t *testing.T) {
+	t.Run("invalid token surfaces the vault error", func(t *testing.T) {
+		srv := newFakeVault(t, "root")
+		s, err := New(newMockCredential, WithAddress(srv.URL), WithToken("invalid"))
+		require.NoError(t, err)
+		_, err = s.Get(t.Context(), store.MustParseID("foo"))
+		var respErr *ResponseError
+		require.ErrorAs(t, err, &respErr)
+		assert.Equal(t, http.StatusForbidden, respErr.StatusCode)
+		assert.Equal(t, []string{"permission denied"}, respErr.Errors)
+	})
+	t.Run("approle logs in once and on rejected token", func(t *testing.T) {
+		f := &fakeVault{token: "approle-token", entries: map[string]*fakeEntry{}}
+		srv := httptest.NewServer(f)
+		t.Cleanup(srv.Close)
+
+		s, err := New(newMockCredential, WithAddress(srv.URL), WithAppRole("approle", "role", "secret"))
+		require.NoError(t, err)
+		id := store.MustParseID("foo")
+		require.NoError(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pass"}))
+		_, err = s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, 1, f.logins)
+
+		// simulate token expiry
+		f.mu.Lock()
+		f.token = "rotated-token"
+		f.mu.Unlock()
+		_, err = s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, 2, f.logins)
+	})
+}
+
+func TestSaveRollback(t *testing.T) {
+	newStore := func(t *testing.T) (*fakeVault, store.Store) {
+		t.Helper()
+		f := &fakeVault{token: "root", entries: map[string]*fakeEntry{}}
+		srv := httptest.NewServer(f)
+		t.Cleanup(srv.Close)
+		s, err := New(newMockCredential, WithAddress(srv.URL), WithToken("root"))
+		require.NoError(t, err)
+		return f, s
+	}
+	failMetadata := func(f *fakeVault) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.failMetadata = true
+	}
+
+	t.Run("restores the previous value when the metadata cannot be written", func(t *testing.T) {
+		f, s := newStore(t)
+		id := store.MustParseID("foo")
+		previous := &mocks.MockCredential{Username: "bob", Password: "pass", Attributes: map[string]string{"env": "dev"}}
+		require.NoError(t, s.Save(t.Context(), id, previous))
+
+		failMetadata(f)
+		err := s.Save(t.Context(), id, &mocks.MockCredential{Username: "alice", Password: "other", Attributes: map[string]string{"env": "prod"}})
+		var respErr *ResponseError
+		require.ErrorAs(t, err, &respErr)
+		assert.Equal(t, http.StatusInternalServerError, respErr.StatusCode)
+
+		secret, err := s.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, previous, secret)
+	})
+	t.Run("removes a new secret when the metadata cannot be written", func(t *testing.T) {
+		f, s := newStore(t)
+		failMetadata(f)
+		id := store.MustParseID("foo")
+		require.Error(t, s.Save(t.Context(), id, &mocks.MockCredential{Username: "bob", Password: "pass"}))
+
+		_, err := s.Get(t.Context(), id)
+		assert.ErrorIs(t, err, store.ErrCredentialNotFound)
+	})
+}
+
+func TestListPrefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		prefix  string
+	}{
+		{"**", ""},
+		{"foo", ""},
+		{"foo/bar", "foo/"},
+		{"foo/bar/*", "foo/bar/"},
+		{"foo/*/baz", "foo/"},
+		{"foo/**/baz/qux", "foo/"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			assert.Equal(t, tc.prefix, listPrefix(store.MustParsePattern(tc.pattern)))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/docker/secrets-engine/x/secrets"
+)
+
+// ErrVersioningNotSupported is returned by [ListVersions] and [GetVersion]
+// for stores that do not keep the previous versions of their secrets.
+var ErrVersioningNotSupported = errors.New("secret versioning not supported by this store")
+
+// VersionInfo describes a previous version of a secret.
+type VersionInfo struct {
+	// Version identifies the version for [GetVersion]. Versions of a secret
+	// are numbered in the order they were saved, the highest being the most
+	// recent.
+	Version int
+	// SavedAt is when the version was saved.
+	SavedAt time.Time
+}
+
+// Versioner can optionally be implemented by a [Store] keeping the previous
+// versions of a secret when it gets overwritten by [Store.Save] or
+// [Store.Upsert].
+type Versioner interface {
+	// ListVersions returns the previous versions kept for the secret of id,
+	// most recent first. The current version of the secret is not listed.
+	ListVersions(ctx context.Context, id ID) ([]VersionInfo, error)
+	// GetVersion returns the given previous version of the secret of id, or
+	// [ErrCredentialNotFound] if it is not kept.
+	GetVersion(ctx context.Context, id ID, version int) (Secret, error)
+}
+
+// ListVersions returns the previous versions kept for the secret of id, see
+// [Versioner.ListVersions].
+//
+// [ErrVersioningNotSupported] is returned if s does not implement
+// [Versioner].
+func ListVersions(ctx context.Context, s Store, id ID) ([]VersionInfo, error) {
+	v, ok := s.(Versioner)
+	if !ok {
+		return nil, ErrVersioningNotSupported
+	}
+	return v.ListVersions(ctx, id)
+}
+
+// GetVersion returns a previous version of the secret of id, e.g. to roll
+// back to it by saving it again, see [Versioner.GetVersion].
+//
+// [ErrVersioningNotSupported] is returned if s does not implement
+// [Versioner].
+func GetVersion(ctx context.Context, s Store, id ID, version int) (Secret, error) {
+	v, ok := s.(Versioner)
+	if !ok {
+		return nil, ErrVersioningNotSupported
+	}
+	return v.GetVersion(ctx, id, version)
+}
+
+// GetVersioned returns the version of the secret referenced by id, e.g. as
+// parsed by [ParseVersionedID] from "db/password@3". The current version is
+// read with [Store.Get], previous ones with [GetVersion].
+func GetVersioned(ctx context.Context, s Store, id VersionedID) (Secret, error) {
+	if id.Version == secrets.LatestVersion {
+		return s.Get(ctx, id.ID)
+	}
+	return GetVersion(ctx, s, id.ID, id.Version)
+}
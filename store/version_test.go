@@ -0,0 +1,29 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioningNotSupported(t *testing.T) {
+	s := &filterStore{}
+	_, err := ListVersions(t.Context(), s, MustParseID("app/a"))
+	assert.ErrorIs(t, err, ErrVersioningNotSupported)
+	_, err = GetVersion(t.Context(), s, MustParseID("app/a"), 1)
+	assert.ErrorIs(t, err, ErrVersioningNotSupported)
+}
@@ -18,6 +18,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 
 	"connectrpc.com/connect"
 
@@ -26,6 +29,7 @@ import (
 
 	resolverv1 "github.com/docker/secrets-engine/x/api/resolver/v1"
 	"github.com/docker/secrets-engine/x/api/resolver/v1/resolverv1connect"
+	"github.com/docker/secrets-engine/x/logging"
 	"github.com/docker/secrets-engine/x/secrets"
 )
 
@@ -33,25 +37,65 @@ var _ resolverv1connect.ResolverServiceHandler = &resolverService{}
 
 type resolverService struct {
 	resolver secrets.Resolver
+	// scope returns the caller scope of a request when coalescing
+	scope func(header http.Header) string
 }
 
-func NewResolverHandler(r secrets.Resolver) resolverv1connect.ResolverServiceHandler {
-	return &resolverService{r}
+// Option configures the handler returned by [NewResolverHandler].
+type Option func(*resolverService)
+
+// WithCoalescing shares a single call to the resolver between concurrent
+// requests for the same pattern, see [secrets.Coalesce]. scope returns the
+// scope of the caller of a request from its headers: requests of different
+// scopes never share a call. A nil scope puts all requests in the same scope.
+func WithCoalescing(scope func(header http.Header) string) Option {
+	return func(r *resolverService) {
+		r.resolver = secrets.Coalesce(r.resolver)
+		r.scope = scope
+		if r.scope == nil {
+			r.scope = func(http.Header) string { return "" }
+		}
+	}
+}
+
+// NewResolverHandler returns a handler resolving secrets through r.
+func NewResolverHandler(r secrets.Resolver, opts ...Option) resolverv1connect.ResolverServiceHandler {
+	s := &resolverService{resolver: r}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (r resolverService) GetSecrets(ctx context.Context, c *connect.Request[resolverv1.GetSecretsRequest]) (*connect.Response[resolverv1.GetSecretsResponse], error) {
-	msgPattern := c.Msg.GetPattern()
-	pattern, err := secrets.ParsePattern(msgPattern)
-	if err != nil {
-		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid pattern %q: %w", msgPattern, err))
+	msgPatterns := c.Msg.GetPatterns()
+	if c.Msg.HasPattern() || len(msgPatterns) == 0 {
+		msgPatterns = append([]string{c.Msg.GetPattern()}, msgPatterns...)
+	}
+	patterns := make([]secrets.Pattern, 0, len(msgPatterns))
+	for _, msgPattern := range msgPatterns {
+		pattern, err := secrets.ParsePattern(msgPattern)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid pattern %q: %w", msgPattern, err))
+		}
+		patterns = append(patterns, pattern)
 	}
 
-	envelopes, err := r.resolver.GetSecrets(ctx, pattern)
+	if r.scope != nil {
+		ctx = secrets.WithCallerScope(ctx, r.scope(c.Header()))
+	}
+	var envelopes []secrets.Envelope
+	var err error
+	if len(patterns) == 1 {
+		envelopes, err = r.resolver.GetSecrets(ctx, patterns[0])
+	} else {
+		envelopes, err = secrets.GetSecretsMany(ctx, r.resolver, patterns...)
+	}
 	if err != nil {
 		if errors.Is(err, secrets.ErrNotFound) {
 			return nil, connect.NewError(connect.CodeNotFound, secrets.ErrNotFound)
 		}
-		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get secret %q: %w", msgPattern, err))
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get secret %q: %w", strings.Join(msgPatterns, ", "), err))
 	}
 	if len(envelopes) == 0 {
 		return nil, connect.NewError(connect.CodeNotFound, secrets.ErrNotFound)
@@ -84,10 +128,61 @@ func NewResolverClient(httpClient connect.HTTPClient) secrets.Resolver {
 	return &resolverClient{resolverClient: resolverv1connect.NewResolverServiceClient(httpClient, "http://unix")}
 }
 
+// ErrPatternsUnsupported is returned by [ManyResolver.GetSecretsMany] when
+// the server does not support resolving several patterns in a single request.
+var ErrPatternsUnsupported = errors.New("the resolver does not support resolving several patterns at once")
+
+// ManyResolver is implemented by the resolver returned by
+// [NewResolverClient], resolving several patterns in a single request.
+type ManyResolver interface {
+	// GetSecretsMany returns the union of the secrets matching any of the
+	// patterns, sorted with [secrets.SortEnvelopes], each secret only once.
+	// [ErrPatternsUnsupported] is returned by servers predating the patterns
+	// field of the request, which only resolve a single pattern.
+	GetSecretsMany(ctx context.Context, patterns ...secrets.Pattern) ([]secrets.Envelope, error)
+}
+
+var _ ManyResolver = &resolverClient{}
+
+// GetSecrets implements [secrets.Resolver].
+//
+// Envelopes whose ID does not match pattern are dropped and logged with the
+// logger of ctx, if any, so that a buggy or malicious resolver cannot return
+// secrets outside of what was asked for. [secrets.ErrNotFound] is returned
+// when no envelope is left.
 func (r resolverClient) GetSecrets(ctx context.Context, pattern secrets.Pattern) ([]secrets.Envelope, error) {
 	req := connect.NewRequest(resolverv1.GetSecretsRequest_builder{
 		Pattern: proto.String(pattern.String()),
 	}.Build())
+	return r.getSecrets(ctx, req, pattern)
+}
+
+// GetSecretsMany implements [ManyResolver]. Like [resolverClient.GetSecrets],
+// envelopes matching none of the patterns are dropped.
+func (r resolverClient) GetSecretsMany(ctx context.Context, patterns ...secrets.Pattern) ([]secrets.Envelope, error) {
+	if len(patterns) == 0 {
+		return nil, errors.New("at least one pattern is required")
+	}
+	msgPatterns := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		msgPatterns = append(msgPatterns, pattern.String())
+	}
+	// the singular pattern is left unset: servers predating the patterns
+	// field reject the request instead of resolving a single pattern
+	req := connect.NewRequest(resolverv1.GetSecretsRequest_builder{
+		Patterns: msgPatterns,
+	}.Build())
+	envelopes, err := r.getSecrets(ctx, req, patterns...)
+	if connect.CodeOf(err) == connect.CodeInvalidArgument {
+		return nil, fmt.Errorf("%w: %w", ErrPatternsUnsupported, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return secrets.UniqueEnvelopes(envelopes), nil
+}
+
+func (r resolverClient) getSecrets(ctx context.Context, req *connect.Request[resolverv1.GetSecretsRequest], patterns ...secrets.Pattern) ([]secrets.Envelope, error) {
 	resp, err := r.resolverClient.GetSecrets(ctx, req)
 	if err != nil {
 		if connect.CodeOf(err) == connect.CodeNotFound {
@@ -102,6 +197,12 @@ func (r resolverClient) GetSecrets(ctx context.Context, pattern secrets.Pattern)
 		if err != nil {
 			continue
 		}
+		if !slices.ContainsFunc(patterns, func(pattern secrets.Pattern) bool { return pattern.Match(id) }) {
+			if logger, err := logging.FromContext(ctx); err == nil {
+				logger.Warnf("dropping secret %q of provider %q: it does not match the requested pattern %q", id, item.GetProvider(), joinPatterns(patterns))
+			}
+			continue
+		}
 		envelopes = append(envelopes, secrets.Envelope{
 			ID:         id,
 			Value:      item.GetValue(),
@@ -113,5 +214,16 @@ func (r resolverClient) GetSecrets(ctx context.Context, pattern secrets.Pattern)
 			ExpiresAt:  item.GetExpiresAt().AsTime(),
 		})
 	}
+	if len(envelopes) == 0 {
+		return nil, secrets.ErrNotFound
+	}
 	return envelopes, nil
 }
+
+func joinPatterns(patterns []secrets.Pattern) string {
+	s := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		s = append(s, pattern.String())
+	}
+	return strings.Join(s, ", ")
+}
@@ -15,9 +15,15 @@
 package resolver
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/synctest"
 
 	"connectrpc.com/connect"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +31,10 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	resolverv1 "github.com/docker/secrets-engine/x/api/resolver/v1"
+	"github.com/docker/secrets-engine/x/api/resolver/v1/resolverv1connect"
+	"github.com/docker/secrets-engine/x/logging"
 	"github.com/docker/secrets-engine/x/secrets"
+	"github.com/docker/secrets-engine/x/testhelper"
 )
 
 const (
@@ -174,3 +183,165 @@ func newGetSecretRequest(pattern secrets.Pattern) *connect.Request[resolverv1.Ge
 		Pattern: proto.String(pattern.String()),
 	}.Build())
 }
+
+// handlerClient serves requests with a handler, without a network connection.
+type handlerClient struct {
+	handler http.Handler
+}
+
+func (h handlerClient) Do(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func TestResolverClientKeepsMetadata(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(resolverv1connect.NewResolverServiceHandler(NewResolverHandler(newMockResolver(t))))
+	client := NewResolverClient(handlerClient{handler: mux})
+
+	envelopes, err := client.GetSecrets(t.Context(), mockPattern)
+	require.NoError(t, err)
+	require.Len(t, envelopes, 1)
+	assert.Equal(t, mockID, envelopes[0].ID)
+	assert.Equal(t, mockSecretValue, string(envelopes[0].Value))
+	assert.Equal(t, mockMetadata, envelopes[0].Metadata)
+}
+
+// staticResolver returns its envelopes whatever the requested pattern, like
+// a buggy or malicious resolver would.
+type staticResolver []secrets.Envelope
+
+func (s staticResolver) GetSecrets(context.Context, secrets.Pattern) ([]secrets.Envelope, error) {
+	return s, nil
+}
+
+func TestResolverClientDropsEnvelopesOutsideOfPattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(resolverv1connect.NewResolverServiceHandler(NewResolverHandler(staticResolver{
+		{ID: secrets.MustParseID("mine/a"), Value: []byte("a"), Provider: "rogue"},
+		{ID: secrets.MustParseID("theirs/b"), Value: []byte("b"), Provider: "rogue"},
+	})))
+	client := NewResolverClient(handlerClient{handler: mux})
+
+	var logs bytes.Buffer
+	ctx := logging.WithLogger(t.Context(), logging.NewDefaultLogger("test", logging.WithOut(&logs)))
+	envelopes, err := client.GetSecrets(ctx, secrets.MustParsePattern("mine/*"))
+	require.NoError(t, err)
+	require.Len(t, envelopes, 1)
+	assert.Equal(t, "mine/a", envelopes[0].ID.String())
+	assert.Contains(t, logs.String(), `dropping secret "theirs/b" of provider "rogue"`)
+
+	_, err = client.GetSecrets(ctx, secrets.MustParsePattern("other/*"))
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+}
+
+// countingResolver counts its calls, which block until release is closed.
+type countingResolver struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (c *countingResolver) GetSecrets(_ context.Context, pattern secrets.Pattern) ([]secrets.Envelope, error) {
+	c.calls.Add(1)
+	<-c.release
+	return []secrets.Envelope{{ID: secrets.MustParseID(pattern.String()), Value: []byte(mockSecretValue)}}, nil
+}
+
+func TestResolverServiceCoalescesIdenticalRequests(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		inner := &countingResolver{release: make(chan struct{})}
+		mux := http.NewServeMux()
+		mux.Handle(resolverv1connect.NewResolverServiceHandler(NewResolverHandler(inner, WithCoalescing(nil))))
+		client := NewResolverClient(handlerClient{handler: mux})
+
+		const n = 10
+		var wg sync.WaitGroup
+		for range n {
+			wg.Go(func() {
+				envelopes, err := client.GetSecrets(t.Context(), secrets.MustParsePattern("db/password"))
+				if assert.NoError(t, err) && assert.Len(t, envelopes, 1) {
+					assert.Equal(t, mockSecretValue, string(envelopes[0].Value))
+				}
+			})
+		}
+		// all the requests are waiting on the shared call
+		synctest.Wait()
+		close(inner.release)
+		wg.Wait()
+
+		assert.EqualValues(t, 1, inner.calls.Load())
+	})
+}
+
+func TestResolverServiceCoalescesByCallerScope(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		inner := &countingResolver{release: make(chan struct{})}
+		mux := http.NewServeMux()
+		mux.Handle(resolverv1connect.NewResolverServiceHandler(NewResolverHandler(inner, WithCoalescing(func(header http.Header) string {
+			return header.Get("X-Caller")
+		}))))
+		client := resolverv1connect.NewResolverServiceClient(handlerClient{handler: mux}, "http://unix")
+
+		var wg sync.WaitGroup
+		for _, caller := range []string{"alice", "alice", "bob"} {
+			wg.Go(func() {
+				req := connect.NewRequest(resolverv1.GetSecretsRequest_builder{
+					Pattern: proto.String("db/password"),
+				}.Build())
+				req.Header().Set("X-Caller", caller)
+				_, err := client.GetSecrets(t.Context(), req)
+				assert.NoError(t, err)
+			})
+		}
+		synctest.Wait()
+		close(inner.release)
+		wg.Wait()
+
+		assert.EqualValues(t, 2, inner.calls.Load())
+	})
+}
+
+func TestResolverServiceResolvesSeveralPatterns(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(resolverv1connect.NewResolverServiceHandler(NewResolverHandler(testhelper.MockResolver{Store: map[secrets.ID]string{
+		secrets.MustParseID("db/password"): "pw",
+		secrets.MustParseID("db/user"):     "user",
+		secrets.MustParseID("api/token"):   "token",
+	}})))
+	ids := func(envelopes []*resolverv1.GetSecretsResponse_Envelope) []string {
+		var ids []string
+		for _, e := range envelopes {
+			ids = append(ids, e.GetId())
+		}
+		return ids
+	}
+
+	t.Run("the union of pattern and patterns without duplicates", func(t *testing.T) {
+		client := resolverv1connect.NewResolverServiceClient(handlerClient{handler: mux}, "http://unix")
+		resp, err := client.GetSecrets(t.Context(), connect.NewRequest(resolverv1.GetSecretsRequest_builder{
+			Pattern:  proto.String("db/password"),
+			Patterns: []string{"api/token", "db/*"},
+		}.Build()))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api/token", "db/password", "db/user"}, ids(resp.Msg.GetEnvelopes()))
+	})
+	t.Run("an invalid pattern is rejected", func(t *testing.T) {
+		client := resolverv1connect.NewResolverServiceClient(handlerClient{handler: mux}, "http://unix")
+		_, err := client.GetSecrets(t.Context(), connect.NewRequest(resolverv1.GetSecretsRequest_builder{
+			Patterns: []string{"api/token", "db//"},
+		}.Build()))
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+	t.Run("client", func(t *testing.T) {
+		client, ok := NewResolverClient(handlerClient{handler: mux}).(ManyResolver)
+		require.True(t, ok)
+		envelopes, err := client.GetSecretsMany(t.Context(), secrets.MustParsePattern("api/token"), secrets.MustParsePattern("db/*"))
+		require.NoError(t, err)
+		require.Len(t, envelopes, 3)
+		assert.Equal(t, "api/token", envelopes[0].ID.String())
+
+		_, err = client.GetSecretsMany(t.Context(), secrets.MustParsePattern("unknown"), secrets.MustParsePattern("other"))
+		assert.ErrorIs(t, err, secrets.ErrNotFound)
+	})
+}
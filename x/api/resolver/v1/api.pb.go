@@ -24,6 +24,7 @@ const (
 type GetSecretsRequest struct {
 	state                  protoimpl.MessageState `protogen:"opaque.v1"`
 	xxx_hidden_Pattern     *string                `protobuf:"bytes,1,opt,name=pattern"`
+	xxx_hidden_Patterns    []string               `protobuf:"bytes,2,rep,name=patterns"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
@@ -65,9 +66,20 @@ func (x *GetSecretsRequest) GetPattern() string {
 	return ""
 }
 
+func (x *GetSecretsRequest) GetPatterns() []string {
+	if x != nil {
+		return x.xxx_hidden_Patterns
+	}
+	return nil
+}
+
 func (x *GetSecretsRequest) SetPattern(v string) {
 	x.xxx_hidden_Pattern = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *GetSecretsRequest) SetPatterns(v []string) {
+	x.xxx_hidden_Patterns = v
 }
 
 func (x *GetSecretsRequest) HasPattern() bool {
@@ -87,6 +99,10 @@ type GetSecretsRequest_builder struct {
 
 	// ID of the secret to resolve.
 	Pattern *string
+	// Patterns of the secrets to resolve, along with pattern if set. The
+	// response holds the union of the secrets matching any of them, each
+	// secret only once.
+	Patterns []string
 }
 
 func (b0 GetSecretsRequest_builder) Build() *GetSecretsRequest {
@@ -94,9 +110,10 @@ func (b0 GetSecretsRequest_builder) Build() *GetSecretsRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Pattern != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
 		x.xxx_hidden_Pattern = b.Pattern
 	}
+	x.xxx_hidden_Patterns = b.Patterns
 	return m0
 }
 
@@ -431,9 +448,10 @@ var File_resolver_v1_api_proto protoreflect.FileDescriptor
 
 const file_resolver_v1_api_proto_rawDesc = "" +
 	"\n" +
-	"\x15resolver/v1/api.proto\x12\vresolver.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"-\n" +
+	"\x15resolver/v1/api.proto\x12\vresolver.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"I\n" +
 	"\x11GetSecretsRequest\x12\x18\n" +
-	"\apattern\x18\x01 \x01(\tR\apattern\"\x89\x04\n" +
+	"\apattern\x18\x01 \x01(\tR\apattern\x12\x1a\n" +
+	"\bpatterns\x18\x02 \x03(\tR\bpatterns\"\x89\x04\n" +
 	"\x12GetSecretsResponse\x12F\n" +
 	"\tenvelopes\x18\x01 \x03(\v2(.resolver.v1.GetSecretsResponse.EnvelopeR\tenvelopes\x1a\xaa\x03\n" +
 	"\bEnvelope\x12\x0e\n" +
@@ -17,6 +17,8 @@ package api
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"golang.org/x/mod/semver"
 )
@@ -66,6 +68,94 @@ func Compare(a, b Version) int {
 	return semver.Compare(a.String(), b.String())
 }
 
+// LessThan reports whether a is lower than b, see [Compare].
+func LessThan(a, b Version) bool {
+	return Compare(a, b) < 0
+}
+
+type versionConstraint struct {
+	op      string
+	version Version
+}
+
+// VersionConstraint is a set of conditions a [Version] must all satisfy,
+// e.g. to only accept plugins implementing a compatible API.
+type VersionConstraint struct {
+	value       string
+	constraints []versionConstraint
+}
+
+// constraintOps lists the supported operators, longest first so that ">="
+// is not parsed as ">" followed by "=v2".
+var constraintOps = []string{">=", "<=", "!=", ">", "<", "="}
+
+// ParseVersionConstraint parses comma-separated conditions made of an
+// operator, one of =, !=, >, >=, < and <=, followed by a version, e.g.
+// ">=v2, <v3". Versions follow [NewVersion], where "v2" is short for
+// "v2.0.0".
+func ParseVersionConstraint(s string) (VersionConstraint, error) {
+	c := VersionConstraint{value: s}
+	for condition := range strings.SplitSeq(s, ",") {
+		condition = strings.TrimSpace(condition)
+		i := slices.IndexFunc(constraintOps, func(op string) bool {
+			return strings.HasPrefix(condition, op)
+		})
+		if i < 0 {
+			return VersionConstraint{}, fmt.Errorf("invalid version constraint %q: %q must start with one of %s", s, condition, strings.Join(constraintOps, " "))
+		}
+		op := constraintOps[i]
+		v, err := NewVersion(strings.TrimSpace(strings.TrimPrefix(condition, op)))
+		if err != nil {
+			return VersionConstraint{}, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.constraints = append(c.constraints, versionConstraint{op: op, version: v})
+	}
+	return c, nil
+}
+
+// MustParseVersionConstraint parses a constraint as
+// [ParseVersionConstraint] does, but panics when it is invalid.
+func MustParseVersionConstraint(s string) VersionConstraint {
+	c, err := ParseVersionConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Check reports whether v satisfies all the conditions of the constraint.
+//
+// Following semantic versioning, a pre-release is lower than its release, so
+// ">=v2" rejects "v2.0.0-rc.1" while "<v2" accepts it.
+func (c VersionConstraint) Check(v Version) bool {
+	for _, constraint := range c.constraints {
+		cmp := Compare(v, constraint.version)
+		var ok bool
+		switch constraint.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c VersionConstraint) String() string {
+	return c.value
+}
+
 func valid(s string) error {
 	if len(s) > 0 && s[0] != 'v' {
 		return ErrVPrefix
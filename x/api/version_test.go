@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_Version(t *testing.T) {
@@ -36,3 +37,68 @@ func Test_Version(t *testing.T) {
 		assert.ErrorIs(t, err, ErrEmptyVersion)
 	})
 }
+
+func TestCompare(t *testing.T) {
+	ordered := []string{
+		"v0.9.9",
+		"v1.0.0-do.not.use",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.10.0",
+		"v2.0.0-do.not.use",
+		"v2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, b := MustNewVersion(ordered[i]), MustNewVersion(ordered[j])
+			assert.Equal(t, compareInts(i, j), Compare(a, b), "%s vs %s", a, b)
+			assert.Equal(t, i < j, LessThan(a, b), "%s < %s", a, b)
+		}
+	}
+	assert.Zero(t, Compare(MustNewVersion("v2"), MustNewVersion("v2.0.0")), "v2 is short for v2.0.0")
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		accepted   []string
+		rejected   []string
+	}{
+		{">=v2, <v3", []string{"v2.0.0", "v2.5.1", "v2.99.0", "v3.0.0-rc.1"}, []string{"v1.9.0", "v2.0.0-do.not.use", "v3.0.0"}},
+		{">v1.0.0", []string{"v1.0.1", "v2.0.0"}, []string{"v1.0.0", "v1.0.0-do.not.use"}},
+		{"<=v1.2", []string{"v1.2.0", "v1.0.0-do.not.use"}, []string{"v1.2.1"}},
+		{"=v1.0.0-do.not.use", []string{"v1.0.0-do.not.use"}, []string{"v1.0.0"}},
+		{">=v1,!=v1.3.0", []string{"v1.2.0", "v1.4.0"}, []string{"v1.3.0"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.constraint, func(t *testing.T) {
+			c, err := ParseVersionConstraint(tc.constraint)
+			require.NoError(t, err)
+			assert.Equal(t, tc.constraint, c.String())
+			for _, v := range tc.accepted {
+				assert.True(t, c.Check(MustNewVersion(v)), v)
+			}
+			for _, v := range tc.rejected {
+				assert.False(t, c.Check(MustNewVersion(v)), v)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "v2", ">=", ">=2.0.0", "~>v2", ">=v2,,<v3", "=>v2"} {
+		t.Run("rejects "+invalid, func(t *testing.T) {
+			_, err := ParseVersionConstraint(invalid)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -35,7 +35,10 @@ const (
 )
 
 type cfg struct {
-	shutdownTimeout time.Duration
+	shutdownTimeout   time.Duration
+	maxStreams        int
+	keepAliveInterval time.Duration
+	acceptBacklog     int
 }
 
 type Option func(*cfg) *cfg
@@ -47,6 +50,60 @@ func WithShutdownTimeout(d time.Duration) Option {
 	}
 }
 
+// WithMaxStreams bounds the number of streams opened concurrently by the
+// [http.Client] returned for the session. Requests beyond the limit block
+// until a stream is released or their context is done. A limit of 0 or less,
+// the default, does not bound the streams.
+func WithMaxStreams(n int) Option {
+	return func(in *cfg) *cfg {
+		in.maxStreams = n
+		return in
+	}
+}
+
+// WithKeepAliveInterval sets how often keep-alive pings are sent on the
+// session, 30s by default. An interval of 0 or less disables keep-alives.
+func WithKeepAliveInterval(d time.Duration) Option {
+	return func(in *cfg) *cfg {
+		in.keepAliveInterval = d
+		return in
+	}
+}
+
+// WithAcceptBacklog sets how many incoming streams can wait to be accepted
+// by the session's server before new ones are refused, 256 by default.
+func WithAcceptBacklog(n int) Option {
+	return func(in *cfg) *cfg {
+		in.acceptBacklog = n
+		return in
+	}
+}
+
+func newCfg(option ...Option) *cfg {
+	defaults := yamux.DefaultConfig()
+	c := &cfg{
+		shutdownTimeout:   defaultShutdownTimeout,
+		keepAliveInterval: defaults.KeepAliveInterval,
+		acceptBacklog:     defaults.AcceptBacklog,
+	}
+	for _, o := range option {
+		c = o(c)
+	}
+	return c
+}
+
+func (c *cfg) yamuxConfig(logger logging.Logger) *yamux.Config {
+	config := yamux.DefaultConfig()
+	config.Logger = &loggerWrapper{logger}
+	config.LogOutput = nil
+	config.AcceptBacklog = c.acceptBacklog
+	config.EnableKeepAlive = c.keepAliveInterval > 0
+	if config.EnableKeepAlive {
+		config.KeepAliveInterval = c.keepAliveInterval
+	}
+	return config
+}
+
 type loggerWrapper struct {
 	logger logging.Logger
 }
@@ -64,26 +121,22 @@ func (l loggerWrapper) Println(v ...interface{}) {
 }
 
 func NewClientIPC(logger logging.Logger, sockConn io.ReadWriteCloser, handler http.Handler, onServerClosed func(error), option ...Option) (io.Closer, *http.Client, error) {
-	cfg := yamux.DefaultConfig()
-	cfg.Logger = &loggerWrapper{logger}
-	cfg.LogOutput = nil
-	session, err := yamux.Client(sockConn, cfg)
+	cfg := newCfg(option...)
+	session, err := yamux.Client(sockConn, cfg.yamuxConfig(logger))
 	if err != nil {
 		return nil, nil, fmt.Errorf("creating yamux client: %w", err)
 	}
-	i, c := newMuxedIPC(logger, session, handler, onServerClosed, option...)
+	i, c := newMuxedIPC(logger, session, handler, onServerClosed, cfg)
 	return i, c, nil
 }
 
 func NewServerIPC(logger logging.Logger, sockConn io.ReadWriteCloser, handler http.Handler, onServerClosed func(error), option ...Option) (io.Closer, *http.Client, error) {
-	cfg := yamux.DefaultConfig()
-	cfg.Logger = &loggerWrapper{logger}
-	cfg.LogOutput = nil
-	session, err := yamux.Server(sockConn, cfg)
+	cfg := newCfg(option...)
+	session, err := yamux.Server(sockConn, cfg.yamuxConfig(logger))
 	if err != nil {
 		return nil, nil, fmt.Errorf("creating yamux server: %w", err)
 	}
-	i, c := newMuxedIPC(logger, session, handler, onServerClosed, option...)
+	i, c := newMuxedIPC(logger, session, handler, onServerClosed, cfg)
 	return i, c, nil
 }
 
@@ -130,20 +183,16 @@ type ipcImpl struct {
 	teardown func() error
 }
 
-func newMuxedIPC(logger logging.Logger, session *yamux.Session, handler http.Handler, onClose func(error), option ...Option) (*ipcImpl, *http.Client) {
+func newMuxedIPC(logger logging.Logger, session *yamux.Session, handler http.Handler, onClose func(error), cfg *cfg) (*ipcImpl, *http.Client) {
 	// Note: Calling session.Close() needs to be done as the very last step as it shuts down all IPC!
 
-	cfg := &cfg{shutdownTimeout: defaultShutdownTimeout}
-	for _, o := range option {
-		cfg = o(cfg)
-	}
 	server := newIpcServer(session, handler, func(err error) error {
 		if onClose != nil {
 			onClose(err)
 		}
 		return session.Close()
 	})
-	c := createYamuxedClient(session)
+	c := createYamuxedClient(session, cfg.maxStreams)
 	return &ipcImpl{
 		server: server,
 		teardown: sync.OnceValue(func() error {
@@ -177,10 +226,16 @@ func (i *ipcImpl) Close() error {
 	return i.teardown()
 }
 
-func createYamuxedClient(session *yamux.Session) *http.Client {
+func createYamuxedClient(session *yamux.Session, maxStreams int) *http.Client {
+	dial := func(context.Context) (net.Conn, error) {
+		return session.Open()
+	}
+	if maxStreams > 0 {
+		dial = limitStreams(session, maxStreams)
+	}
 	transport := &http.Transport{
-		DialContext: func(context.Context, string, string) (net.Conn, error) {
-			return session.Open()
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx)
 		},
 		// We don't want to use http keepalive because
 		// - we keep re-using the underlying socket anyway
@@ -190,3 +245,33 @@ func createYamuxedClient(session *yamux.Session) *http.Client {
 	}
 	return &http.Client{Transport: transport}
 }
+
+// limitStreams returns a dial function opening at most n concurrent streams
+// on session. Dialing blocks while n streams are open, until one of them is
+// closed or ctx is done.
+func limitStreams(session *yamux.Session, n int) func(context.Context) (net.Conn, error) {
+	slots := make(chan struct{}, n)
+	return func(ctx context.Context) (net.Conn, error) {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+		conn, err := session.Open()
+		if err != nil {
+			<-slots
+			return nil, err
+		}
+		return &limitedStream{Conn: conn, release: sync.OnceFunc(func() { <-slots })}, nil
+	}
+}
+
+type limitedStream struct {
+	net.Conn
+	release func()
+}
+
+func (s *limitedStream) Close() error {
+	defer s.release()
+	return s.Conn.Close()
+}
@@ -15,6 +15,7 @@
 package ipc
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net"
@@ -22,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -163,6 +165,59 @@ func Test_ipc(t *testing.T) {
 	}
 }
 
+func Test_ipcMaxStreams(t *testing.T) {
+	t.Parallel()
+	engineConn, pluginConn := net.Pipe()
+	t.Cleanup(func() { engineConn.Close(); pluginConn.Close() })
+
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	handler := http.NewServeMux()
+	handler.HandleFunc("/block", func(w http.ResponseWriter, _ *http.Request) {
+		entered <- struct{}{}
+		<-unblock
+		fmt.Fprint(w, "unblocked")
+	})
+	handler.Handle(mockPingPath, newPingPongHandler("plugin"))
+
+	serverErr := make(chan error, 1)
+	go func() {
+		i, _, err := NewServerIPC(testhelper.TestLogger(t), pluginConn, handler, nil)
+		if err == nil {
+			t.Cleanup(func() { i.Close() })
+		}
+		serverErr <- err
+	}()
+	i, c, err := NewClientIPC(testhelper.TestLogger(t), engineConn, http.NewServeMux(), nil, WithMaxStreams(1), WithKeepAliveInterval(0), WithShutdownTimeout(0))
+	require.NoError(t, err)
+	t.Cleanup(func() { i.Close() })
+	require.NoError(t, <-serverErr)
+
+	blocked := make(chan string)
+	go func() {
+		resp, err := c.Get("http://unused/block")
+		if !assert.NoError(t, err) {
+			close(blocked)
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		blocked <- string(body)
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://unused"+mockPingPath, nil)
+	require.NoError(t, err)
+	_, err = c.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the only stream is in use")
+
+	close(unblock)
+	assert.Equal(t, "unblocked", <-blocked)
+	assertCommunicationToServer(t, c, "pong-plugin")
+}
+
 func newListener(t *testing.T, socketPath string) net.Listener {
 	t.Helper()
 	l, err := net.Listen("unix", socketPath)
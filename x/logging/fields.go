@@ -0,0 +1,100 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type fieldsKey struct{}
+
+// With returns a new context carrying the given key-value pairs, e.g.
+//
+//	ctx = logging.With(ctx, "plugin", name, "request_id", id)
+//
+// Loggers retrieved with [FromContext] from the returned context, or any
+// context derived from it, tag every record with the fields, including the
+// fields added by parent contexts. Fields can be added before or after a
+// logger is set with [WithLogger].
+func With(ctx context.Context, keyvals ...any) context.Context {
+	if len(keyvals) == 0 {
+		return ctx
+	}
+	fields := slices.Concat(fieldsFrom(ctx), keyvals)
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+func fieldsFrom(ctx context.Context) []any {
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+	return fields
+}
+
+// formatFields formats keyvals as "[key=value ...] ", escaped to be used as
+// part of a format string. A key without a value gets "MISSING" as value.
+func formatFields(keyvals []any) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < len(keyvals); i += 2 {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		var value any = "MISSING"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fmt.Fprintf(&b, "%v=%v", keyvals[i], value)
+	}
+	b.WriteString("] ")
+	return strings.ReplaceAll(b.String(), "%", "%%")
+}
+
+// withFields returns a logger tagging every record with the fields.
+func withFields(l Logger, fields []any) Logger {
+	tag := formatFields(fields)
+	// extend the prefix of the default logger instead of wrapping it, so
+	// that it keeps reporting the file and line of its caller
+	if d, ok := l.(*defaultLogger); ok {
+		enriched := *d
+		enriched.prefix += tag
+		return &enriched
+	}
+	return &fieldLogger{logger: l, tag: tag}
+}
+
+type fieldLogger struct {
+	logger Logger
+	tag    string
+}
+
+func (f *fieldLogger) Debugf(format string, v ...interface{}) {
+	if d, ok := f.logger.(DebugLogger); ok {
+		d.Debugf(f.tag+format, v...)
+	}
+}
+
+func (f *fieldLogger) Printf(format string, v ...interface{}) {
+	f.logger.Printf(f.tag+format, v...)
+}
+
+func (f *fieldLogger) Warnf(format string, v ...interface{}) {
+	f.logger.Warnf(f.tag+format, v...)
+}
+
+func (f *fieldLogger) Errorf(format string, v ...interface{}) {
+	f.logger.Errorf(f.tag+format, v...)
+}
@@ -31,6 +31,98 @@ type Logger interface {
 	Errorf(format string, v ...interface{})
 }
 
+// DebugLogger is a [Logger] that also writes debug records, like the loggers
+// created with [NewDefaultLogger]. It is kept separate from [Logger] so that
+// existing implementations of [Logger] keep satisfying it: callers check for
+// it and drop debug records otherwise.
+type DebugLogger interface {
+	Logger
+	Debugf(format string, v ...interface{})
+}
+
+// Level is the minimum severity of the records written by a logger created
+// with [NewDefaultLogger] or [NewLevelLogger].
+type Level int
+
+const (
+	// LevelDebug logs everything, including [DebugLogger.Debugf].
+	LevelDebug Level = iota
+	// LevelInfo logs [Logger.Printf] and above. It is the default.
+	LevelInfo
+	// LevelWarn logs [Logger.Warnf] and [Logger.Errorf].
+	LevelWarn
+	// LevelError only logs [Logger.Errorf].
+	LevelError
+)
+
+// LevelEnv is the environment variable read by [LevelFromEnv].
+const LevelEnv = "LOG_LEVEL"
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// ParseLevel parses one of "debug", "info", "warn" (or "warning") and
+// "error", ignoring case.
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warning" {
+		return LevelWarn, nil
+	}
+	for level, name := range levelNames {
+		if name == s {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("invalid log level %q, must be one of debug, info, warn or error", s)
+}
+
+// LevelFromEnv returns the level set by the [LevelEnv] environment variable,
+// or [LevelInfo] when it is not set.
+func LevelFromEnv() (Level, error) {
+	v, ok := os.LookupEnv(LevelEnv)
+	if !ok || v == "" {
+		return LevelInfo, nil
+	}
+	return ParseLevel(v)
+}
+
+// LevelOrEnv returns level when it was set explicitly, e.g. through a
+// command line flag, or the level of [LevelFromEnv] otherwise. It lets a
+// flag override an invalid environment variable.
+func LevelOrEnv(level Level, set bool) (Level, error) {
+	if set {
+		return level, nil
+	}
+	return LevelFromEnv()
+}
+
+// Set implements [flag.Value] (and pflag.Value) so that a level can be
+// bound to a command line flag.
+func (l *Level) Set(s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Type implements pflag.Value.
+func (l *Level) Type() string {
+	return "level"
+}
+
 type Option func(l *defaultLogger)
 
 func WithOut(out io.Writer) Option {
@@ -42,20 +134,30 @@ func WithOut(out io.Writer) Option {
 	}
 }
 
+// WithLevel sets the minimum level of the records written by the logger.
+func WithLevel(level Level) Option {
+	return func(l *defaultLogger) {
+		l.level = level
+	}
+}
+
 type defaultLogger struct {
 	logger *log.Logger
 	prefix string
+	level  Level
 }
 
 func newDefaultLogger(out io.Writer) *log.Logger {
 	return log.New(out, "", log.LstdFlags)
 }
 
+// NewDefaultLogger returns a [Logger] writing to stderr at [LevelInfo],
+// unless configured otherwise through options.
 func NewDefaultLogger(prefix string, options ...Option) Logger {
 	if prefix != "" && !strings.HasSuffix(prefix, ": ") {
 		prefix += ": "
 	}
-	logger := &defaultLogger{prefix: prefix}
+	logger := &defaultLogger{prefix: prefix, level: LevelInfo}
 	for _, option := range options {
 		option(logger)
 	}
@@ -65,11 +167,30 @@ func NewDefaultLogger(prefix string, options ...Option) Logger {
 	return logger
 }
 
+// NewLevelLogger is like [NewDefaultLogger] but only writes the records at
+// level or above.
+func NewLevelLogger(prefix string, level Level, options ...Option) Logger {
+	return NewDefaultLogger(prefix, append([]Option{WithLevel(level)}, options...)...)
+}
+
+func (d defaultLogger) Debugf(format string, v ...interface{}) {
+	if d.level > LevelDebug {
+		return
+	}
+	d.logger.Printf(suffix()+"[DEBUG] "+d.prefix+format, v...)
+}
+
 func (d defaultLogger) Printf(format string, v ...interface{}) {
+	if d.level > LevelInfo {
+		return
+	}
 	d.logger.Printf(suffix()+d.prefix+format, v...)
 }
 
 func (d defaultLogger) Warnf(format string, v ...interface{}) {
+	if d.level > LevelWarn {
+		return
+	}
 	d.logger.Printf(suffix()+"[WARN] "+d.prefix+format, v...)
 }
 
@@ -115,10 +236,16 @@ func WithLogger(ctx context.Context, logger Logger) context.Context {
 }
 
 // FromContext retrieves the current logger from the context. If no logger is
-// available, a new default logger gets returned.
+// available, an error is returned.
+//
+// The logger tags its records with the fields added through [With].
 func FromContext(ctx context.Context) (Logger, error) {
-	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
-		return logger, nil
+	logger, ok := ctx.Value(loggerKey{}).(Logger)
+	if !ok {
+		return nil, errors.New("no logger found in context")
+	}
+	if fields := fieldsFrom(ctx); len(fields) > 0 {
+		return withFields(logger, fields), nil
 	}
-	return nil, errors.New("no logger found in context")
+	return logger, nil
 }
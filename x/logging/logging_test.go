@@ -16,10 +16,13 @@ package logging
 
 import (
 	"bytes"
+	"fmt"
 	"log"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_logFilePrefix(t *testing.T) {
@@ -49,3 +52,168 @@ func Test_logFilePrefix(t *testing.T) {
 		assert.Contains(t, result, "logging_test.go")
 	})
 }
+
+func TestLevels(t *testing.T) {
+	logAll := func(l Logger) {
+		l.(DebugLogger).Debugf("debug record")
+		l.Printf("info record")
+		l.Warnf("warn record")
+		l.Errorf("error record")
+	}
+	tests := []struct {
+		level    Level
+		expected []string
+	}{
+		{LevelDebug, []string{"debug record", "info record", "warn record", "error record"}},
+		{LevelInfo, []string{"info record", "warn record", "error record"}},
+		{LevelWarn, []string{"warn record", "error record"}},
+		{LevelError, []string{"error record"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.level.String(), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logAll(NewLevelLogger("prefix", tc.level, WithOut(buf)))
+			result := buf.String()
+			for _, record := range []string{"debug record", "info record", "warn record", "error record"} {
+				if slices.Contains(tc.expected, record) {
+					assert.Contains(t, result, record)
+				} else {
+					assert.NotContains(t, result, record)
+				}
+			}
+		})
+	}
+
+	t.Run("default logger logs at info", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logAll(NewDefaultLogger("prefix", WithOut(buf)))
+		assert.NotContains(t, buf.String(), "debug record")
+		assert.Contains(t, buf.String(), "info record")
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, expected := range map[string]Level{
+		"debug":   LevelDebug,
+		"INFO":    LevelInfo,
+		" warn ":  LevelWarn,
+		"warning": LevelWarn,
+		"Error":   LevelError,
+	} {
+		level, err := ParseLevel(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, level, input)
+	}
+	_, err := ParseLevel("verbose")
+	assert.ErrorContains(t, err, `invalid log level "verbose"`)
+
+	var level Level
+	assert.NoError(t, level.Set("error"))
+	assert.Equal(t, LevelError, level)
+	assert.Error(t, level.Set(""))
+}
+
+func TestLevelFromEnv(t *testing.T) {
+	t.Setenv(LevelEnv, "")
+	level, err := LevelFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, LevelInfo, level)
+
+	t.Setenv(LevelEnv, "debug")
+	level, err = LevelFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, LevelDebug, level)
+
+	t.Setenv(LevelEnv, "loud")
+	_, err = LevelFromEnv()
+	assert.Error(t, err)
+}
+
+func TestLevelOrEnv(t *testing.T) {
+	t.Setenv(LevelEnv, "loud")
+	level, err := LevelOrEnv(LevelError, true)
+	assert.NoError(t, err)
+	assert.Equal(t, LevelError, level)
+	_, err = LevelOrEnv(LevelError, false)
+	assert.Error(t, err)
+
+	t.Setenv(LevelEnv, "warn")
+	level, err = LevelOrEnv(LevelInfo, false)
+	assert.NoError(t, err)
+	assert.Equal(t, LevelWarn, level)
+}
+
+type recordLogger struct {
+	records []string
+}
+
+func (r *recordLogger) Debugf(format string, v ...any) {
+	r.records = append(r.records, fmt.Sprintf(format, v...))
+}
+
+func (r *recordLogger) Printf(format string, v ...any) {
+	r.records = append(r.records, fmt.Sprintf(format, v...))
+}
+
+func (r *recordLogger) Warnf(format string, v ...any) {
+	r.records = append(r.records, fmt.Sprintf(format, v...))
+}
+
+func (r *recordLogger) Errorf(format string, v ...any) {
+	r.records = append(r.records, fmt.Sprintf(format, v...))
+}
+
+func TestWith(t *testing.T) {
+	t.Run("no fields keeps the logger", func(t *testing.T) {
+		logger := &recordLogger{}
+		ctx := With(WithLogger(t.Context(), logger))
+		l, err := FromContext(ctx)
+		require.NoError(t, err)
+		assert.Same(t, logger, l)
+	})
+	t.Run("fields are added to every record", func(t *testing.T) {
+		logger := &recordLogger{}
+		ctx := With(WithLogger(t.Context(), logger), "plugin", "foo", "request_id", 42)
+		l, err := FromContext(ctx)
+		require.NoError(t, err)
+		l.(DebugLogger).Debugf("debug %s", "record")
+		l.Printf("info")
+		l.Warnf("warn")
+		l.Errorf("error")
+		assert.Equal(t, []string{
+			"[plugin=foo request_id=42] debug record",
+			"[plugin=foo request_id=42] info",
+			"[plugin=foo request_id=42] warn",
+			"[plugin=foo request_id=42] error",
+		}, logger.records)
+	})
+	t.Run("fields accumulate and can be set before the logger", func(t *testing.T) {
+		logger := &recordLogger{}
+		parent := With(t.Context(), "plugin", "foo")
+		ctx := With(WithLogger(parent, logger), "request_id", "abc")
+		l, err := FromContext(ctx)
+		require.NoError(t, err)
+		l.Printf("record")
+		pl, err := FromContext(WithLogger(parent, logger))
+		require.NoError(t, err)
+		pl.Printf("record")
+		assert.Equal(t, []string{"[plugin=foo request_id=abc] record", "[plugin=foo] record"}, logger.records)
+	})
+	t.Run("missing value and format verbs", func(t *testing.T) {
+		logger := &recordLogger{}
+		ctx := With(WithLogger(t.Context(), logger), "pattern", "100%", "dangling")
+		l, err := FromContext(ctx)
+		require.NoError(t, err)
+		l.Printf("record")
+		assert.Equal(t, []string{"[pattern=100% dangling=MISSING] record"}, logger.records)
+	})
+	t.Run("default logger reports the caller", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		ctx := With(WithLogger(t.Context(), NewDefaultLogger("prefix", WithOut(buf))), "plugin", "foo")
+		l, err := FromContext(ctx)
+		require.NoError(t, err)
+		l.Printf("record")
+		assert.Contains(t, buf.String(), "logging_test.go")
+		assert.Contains(t, buf.String(), "[plugin=foo] record")
+	})
+}
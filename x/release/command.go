@@ -41,10 +41,12 @@ type opts struct {
 	skipGit     bool
 	level       helper.Level
 	noPropagate bool
+	changelog   bool
+	logLevel    logging.Level
 }
 
 func ReleaseCommand(cfg Config) (*cobra.Command, error) {
-	opts := opts{level: helper.Patch}
+	opts := opts{level: helper.Patch, logLevel: logging.LevelInfo}
 	if _, err := os.Stat(".git"); err != nil {
 		if os.IsNotExist(err) {
 			return nil, fmt.Errorf("not in a git repository root directory")
@@ -56,6 +58,10 @@ func ReleaseCommand(cfg Config) (*cobra.Command, error) {
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mod := args[0]
+			logLevel, err := logging.LevelOrEnv(opts.logLevel, cmd.Flags().Changed("log-level"))
+			if err != nil {
+				return err
+			}
 			if !opts.dryRun && !opts.skipGit {
 				if err := verifyReleaseRef(cmd.Context()); err != nil {
 					return err
@@ -68,22 +74,48 @@ func ReleaseCommand(cfg Config) (*cobra.Command, error) {
 			if _, ok := data[mod]; !ok {
 				return fmt.Errorf("module %s not found", mod)
 			}
+			logger := logging.NewLevelLogger("", logLevel)
+			if opts.changelog {
+				changelog, err := helper.Changelog(cmd.Context(), mod, data[mod].Version, helper.Version{}, projectFS{opts: opts, logger: logger})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), changelog)
+			}
+			var releaseFS helper.FS = &projectFS{opts: opts, beforeCommitHook: cfg.BeforeCommitHook, logger: logger}
+			var plan *helper.Plan
+			if opts.dryRun {
+				plan = &helper.Plan{FS: releaseFS}
+				releaseFS = plan
+			}
 			if opts.noPropagate {
 				modData, ok := data[mod]
 				if !ok {
 					return fmt.Errorf("module %s not found", mod)
 				}
-				return helper.BumpModule(cmd.Context(), mod, opts.level, modData, &projectFS{opts: opts, beforeCommitHook: cfg.BeforeCommitHook, logger: logging.NewDefaultLogger("")})
+				err = helper.BumpModule(cmd.Context(), mod, opts.level, modData, releaseFS)
+			} else {
+				err = helper.BumpIterative(cmd.Context(), mod, opts.level, data, releaseFS)
+			}
+			if err != nil || plan == nil {
+				return err
 			}
-			return helper.BumpIterative(cmd.Context(), mod, opts.level, data, &projectFS{opts: opts, beforeCommitHook: cfg.BeforeCommitHook, logger: logging.NewDefaultLogger("")})
+			fmt.Fprint(cmd.OutOrStdout(), plan)
+			return nil
 		},
 	}
 
 	flags := bump.Flags()
-	flags.BoolVar(&opts.dryRun, "dry", false, "Dry run: Only log all steps, no actual changes will be made.")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Dry run: Print the tags, go.mod bumps and commits of the release without making any change.")
+	flags.BoolVar(&opts.dryRun, "dry", false, "Deprecated alias of --dry-run.")
+	if err := flags.MarkDeprecated("dry", "use --dry-run instead"); err != nil {
+		return nil, err
+	}
 	flags.BoolVar(&opts.skipGit, "skip-git", false, "Skip git operations: Useful to preview only the go.mod changes.")
 	flags.Var(&opts.level, "release", fmt.Sprintf("Release type (default=patch): %s", helper.AllowedLevels()))
 	flags.BoolVar(&opts.noPropagate, "no-propagate", false, "Only release the specified module and do not propagate to internal downstream dependencies.")
+	flags.BoolVar(&opts.changelog, "changelog", false, "Print the changes to the module since its latest release, to use as release notes.")
+	flags.Var(&opts.logLevel, "log-level", fmt.Sprintf("Log level: debug, info, warn or error (default from %s, else info).", logging.LevelEnv))
 
 	return bump, nil
 }
@@ -177,6 +209,12 @@ func (m projectFS) GitCommit(ctx context.Context, commit string) error {
 	return gitCommit(ctx, commit)
 }
 
+// GitLog lists the commits between two revisions with the files they changed.
+// It never changes the repository, so it also runs in dry runs.
+func (m projectFS) GitLog(ctx context.Context, from, to string) ([]helper.Commit, error) {
+	return gitLog(ctx, from, to)
+}
+
 func newRepoData(versionExtraAllowList []string) (helper.RepoData, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -282,6 +320,36 @@ func gitPushTags(ctx context.Context) error {
 	return nil
 }
 
+// commitSeparator starts every commit in the output of gitLog, as subjects
+// and file names cannot contain it.
+const commitSeparator = "\x1e"
+
+func gitLog(ctx context.Context, from, to string) ([]helper.Commit, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	revs := to
+	if from != "" {
+		revs = from + ".." + to
+	}
+	out, err := runGit(ctx, "log", "--format="+commitSeparator+"%s", "--name-only", revs)
+	if err != nil {
+		return nil, fmt.Errorf("git log %s (%s): %s", revs, err, out)
+	}
+	var commits []helper.Commit
+	for _, entry := range strings.Split(out, commitSeparator)[1:] {
+		lines := strings.Split(entry, "\n")
+		commit := helper.Commit{Subject: lines[0]}
+		for _, file := range lines[1:] {
+			if file = strings.TrimSpace(file); file != "" {
+				commit.Files = append(commit.Files, file)
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
 func gitCommit(ctx context.Context, commit string) error {
 	cmd := exec.CommandContext(ctx, "git", "commit", "-am", commit)
 	if out, err := cmd.CombinedOutput(); err != nil {
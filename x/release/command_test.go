@@ -15,6 +15,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
@@ -23,6 +24,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/docker/secrets-engine/x/logging"
+	"github.com/docker/secrets-engine/x/release/helper"
 )
 
 // Not parallel: the git helpers shell out in the process working directory, so
@@ -53,6 +57,83 @@ func Test_verifyReleaseRef(t *testing.T) {
 	})
 }
 
+// Not parallel, see Test_verifyReleaseRef.
+func Test_gitLog(t *testing.T) {
+	repo := newGitRepoWithRemote(t)
+	repo.run(t, "tag", "x/v0.0.1", "-m", "x/v0.0.1")
+	repo.commit(t, "fix: root file")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo.dir, "x"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo.dir, "x", "go.mod"), []byte("module x\n"), 0o644))
+	repo.run(t, "add", "x/go.mod")
+	repo.run(t, "commit", "-m", "feat(x): add module")
+
+	commits, err := gitLog(repo.ctxAt(t), "x/v0.0.1", "")
+	require.NoError(t, err)
+	assert.Equal(t, []helper.Commit{
+		{Subject: "feat(x): add module", Files: []string{"x/go.mod"}},
+		{Subject: "fix: root file", Files: []string{"file.txt"}},
+	}, commits)
+
+	all, err := gitLog(repo.ctxAt(t), "", "x/v0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []helper.Commit{{Subject: "seed", Files: []string{"README.md"}}}, all)
+}
+
+// Not parallel, see Test_verifyReleaseRef.
+func Test_releaseDryRun(t *testing.T) {
+	repo := newGitRepoWithRemote(t)
+	for mod, content := range map[string]string{
+		"x":      "module github.com/docker/secrets-engine/x\n\ngo 1.25\n",
+		"client": "module github.com/docker/secrets-engine/client\n\ngo 1.25\n\nrequire github.com/docker/secrets-engine/x v0.0.1\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(repo.dir, mod), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo.dir, mod, "go.mod"), []byte(content), 0o644))
+	}
+	repo.run(t, "add", ".")
+	repo.run(t, "commit", "-m", "add modules")
+	repo.run(t, "tag", "x/v0.0.1", "-m", "x/v0.0.1")
+	repo.run(t, "tag", "client/v0.0.1", "-m", "client/v0.0.1")
+	ctx := repo.ctxAt(t)
+	head := repo.output(t, "rev-parse", "HEAD")
+
+	// the flag overrides an invalid environment variable
+	t.Setenv(logging.LevelEnv, "loud")
+	tests := []struct {
+		flag   string
+		notice string
+	}{
+		{flag: "--dry-run"},
+		{flag: "--dry", notice: "Flag --dry has been deprecated, use --dry-run instead\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.flag, func(t *testing.T) {
+			cmd, err := ReleaseCommand(Config{BeforeCommitHook: func() error {
+				t.Error("the commit hook must not run in a dry run")
+				return nil
+			}})
+			require.NoError(t, err)
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{"x", tc.flag, "--log-level", "error"})
+			require.NoError(t, cmd.ExecuteContext(ctx))
+
+			assert.Equal(t, tc.notice+`Tags to create:
+  x/v0.0.2
+  client/v0.0.2
+
+go.mod bumps:
+  client/go.mod: x -> v0.0.2
+
+Commits:
+  chore: bump x/v0.0.2
+`, out.String())
+			assert.Equal(t, head, repo.output(t, "rev-parse", "HEAD"), "no commit must be made")
+			assert.Equal(t, "client/v0.0.1\nx/v0.0.1\n", repo.output(t, "tag", "-l"), "no tag must be created")
+			assert.Empty(t, repo.output(t, "status", "--porcelain"), "no file must be changed")
+		})
+	}
+}
+
 type gitRepo struct {
 	dir string
 }
@@ -110,6 +191,15 @@ func (r *gitRepo) run(t *testing.T, args ...string) {
 	runGitIn(t, r.dir, args...)
 }
 
+func (r *gitRepo) output(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+	return string(out)
+}
+
 func runGitIn(t *testing.T, dir string, args ...string) {
 	t.Helper()
 	cmd := exec.Command("git", args...)
@@ -0,0 +1,134 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Commit is a commit listed by [FS.GitLog].
+type Commit struct {
+	Subject string
+	// Files changed by the commit, relative to the repository root.
+	Files []string
+}
+
+type changelogGroup struct {
+	title string
+	types []string
+}
+
+// changelogGroups are the sections of a changelog, in order. Commits that are
+// not conventional commits or of any other type are listed last.
+var changelogGroups = []changelogGroup{
+	{title: "Features", types: []string{"feat"}},
+	{title: "Fixes", types: []string{"fix"}},
+	{title: "Chores", types: []string{"chore"}},
+}
+
+const otherChanges = "Other changes"
+
+// conventionalCommit matches "type(scope)!: description", scope and "!" are
+// optional.
+var conventionalCommit = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+// Changelog returns the changes to the module mod between the releases from
+// and to as markdown, grouped by conventional commit type (feat, fix, chore).
+//
+// Only the commits changing files in the directory of mod are listed. An empty
+// from lists all the commits up to to, an empty to lists the commits that are
+// not released yet.
+func Changelog(ctx context.Context, mod string, from, to Version, i FS) (string, error) {
+	var fromRev, toRev string
+	if from.Current != "" {
+		fromRev = mod + "/" + from.Current
+	}
+	title := mod + " (unreleased)"
+	if to.Current != "" {
+		toRev = mod + "/" + to.Current
+		title = toRev
+	}
+	commits, err := i.GitLog(ctx, fromRev, toRev)
+	if err != nil {
+		return "", err
+	}
+
+	groups := map[string][]string{}
+	for _, c := range commits {
+		if !changesModule(mod, c.Files) {
+			continue
+		}
+		group, entry := parseCommitSubject(c.Subject)
+		groups[group] = append(groups[group], entry)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", title)
+	if len(groups) == 0 {
+		b.WriteString("\nNo changes.\n")
+		return b.String(), nil
+	}
+	for _, g := range changelogGroups {
+		writeChangelogGroup(&b, g.title, groups[g.title])
+	}
+	writeChangelogGroup(&b, otherChanges, groups[otherChanges])
+	return b.String(), nil
+}
+
+func changesModule(mod string, files []string) bool {
+	for _, f := range files {
+		if strings.HasPrefix(f, mod+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCommitSubject returns the changelog group of a commit and its entry.
+func parseCommitSubject(subject string) (string, string) {
+	m := conventionalCommit.FindStringSubmatch(subject)
+	if m == nil {
+		return otherChanges, subject
+	}
+	commitType, scope, breaking, description := strings.ToLower(m[1]), m[2], m[3], m[4]
+	entry := description
+	if scope != "" {
+		entry = "**" + scope + ":** " + entry
+	}
+	if breaking != "" {
+		entry = "**BREAKING** " + entry
+	}
+	for _, g := range changelogGroups {
+		for _, t := range g.types {
+			if t == commitType {
+				return g.title, entry
+			}
+		}
+	}
+	return otherChanges, subject
+}
+
+func writeChangelogGroup(b *strings.Builder, title string, entries []string) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, e := range entries {
+		fmt.Fprintf(b, "- %s\n", e)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_changelog(t *testing.T) {
+	t.Parallel()
+	log := []Commit{
+		{Subject: "feat(keychain): add labels", Files: []string{"store/keychain/keychain.go"}},
+		{Subject: "fix: do not leak the socket", Files: []string{"x/ipc/ipc.go", "client/client.go"}},
+		{Subject: "chore: bump x/v0.0.3-do.not.use", Files: []string{"plugin/go.mod"}},
+		{Subject: "feat!: drop the legacy API", Files: []string{"x/api/api.go"}},
+		{Subject: "docs: explain the release", Files: []string{"x/README.md"}},
+		{Subject: "Merge pull request #1", Files: []string{"x/go.mod"}},
+		{Subject: "feat: pass plugin", Files: []string{"plugins/pass/main.go"}},
+		{Subject: "chore(deps): bump golang.org/x/sys", Files: []string{"x/go.mod", "x/go.sum"}},
+	}
+	t.Run("groups the commits of the module by type", func(t *testing.T) {
+		repo := newMockRepoData()
+		m := &mockFS{log: log}
+		from := repo["x"].Version
+		next, err := from.GetNextVersion(Patch)
+		require.NoError(t, err)
+		changelog, err := Changelog(t.Context(), "x", from, Version{Current: next}, m)
+		require.NoError(t, err)
+		assert.Equal(t, [][2]string{{"x/v0.0.3-do.not.use", "x/v0.0.4-do.not.use"}}, m.logRanges)
+		assert.Equal(t, `## x/v0.0.4-do.not.use
+
+### Features
+
+- **BREAKING** drop the legacy API
+
+### Fixes
+
+- do not leak the socket
+
+### Chores
+
+- **deps:** bump golang.org/x/sys
+
+### Other changes
+
+- docs: explain the release
+- Merge pull request #1
+`, changelog)
+	})
+	t.Run("module directories are not prefixes of each other", func(t *testing.T) {
+		m := &mockFS{log: log}
+		changelog, err := Changelog(t.Context(), "plugin", Version{Current: "v0.1.0"}, Version{}, m)
+		require.NoError(t, err)
+		assert.Equal(t, [][2]string{{"plugin/v0.1.0", ""}}, m.logRanges)
+		assert.Equal(t, `## plugin (unreleased)
+
+### Chores
+
+- bump x/v0.0.3-do.not.use
+`, changelog)
+	})
+	t.Run("no changes", func(t *testing.T) {
+		changelog, err := Changelog(t.Context(), "runtime", Version{}, Version{Current: "v0.2.4"}, &mockFS{log: log})
+		require.NoError(t, err)
+		assert.Equal(t, "## runtime/v0.2.4\n\nNo changes.\n", changelog)
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PlannedBump is a go.mod edit recorded by a [Plan].
+type PlannedBump struct {
+	Path    string
+	Module  string
+	Version string
+}
+
+// Plan is an [FS] recording the tags, go.mod edits and commits of a release
+// instead of applying them, so that [BumpIterative] and [BumpModule] can be
+// previewed.
+//
+// FS is only used to find out whether a go.mod requires a module and must not
+// write it, and to list commits.
+type Plan struct {
+	FS FS
+
+	Tags    []string
+	Bumps   []PlannedBump
+	Commits []string
+}
+
+var _ FS = &Plan{}
+
+func (p *Plan) GitTag(_ context.Context, tag string) error {
+	p.Tags = append(p.Tags, tag)
+	return nil
+}
+
+func (p *Plan) GitCommit(_ context.Context, msg string) error {
+	p.Commits = append(p.Commits, msg)
+	return nil
+}
+
+func (p *Plan) BumpModInFile(path, module, version string) (bool, error) {
+	modified, err := p.FS.BumpModInFile(path, module, version)
+	if err != nil || !modified {
+		return modified, err
+	}
+	p.Bumps = append(p.Bumps, PlannedBump{Path: path, Module: module, Version: version})
+	return true, nil
+}
+
+func (p *Plan) GitLog(ctx context.Context, from, to string) ([]Commit, error) {
+	return p.FS.GitLog(ctx, from, to)
+}
+
+// String renders the plan, steps are listed in the order they would be
+// applied.
+func (p *Plan) String() string {
+	var b strings.Builder
+	writePlanSection(&b, "Tags to create", p.Tags)
+	b.WriteString("\n")
+	var bumps []string
+	for _, bump := range p.Bumps {
+		bumps = append(bumps, fmt.Sprintf("%s: %s -> %s", bump.Path, bump.Module, bump.Version))
+	}
+	writePlanSection(&b, "go.mod bumps", bumps)
+	b.WriteString("\n")
+	writePlanSection(&b, "Commits", p.Commits)
+	return b.String()
+}
+
+func writePlanSection(b *strings.Builder, title string, items []string) {
+	fmt.Fprintf(b, "%s:\n", title)
+	if len(items) == 0 {
+		b.WriteString("  (none)\n")
+		return
+	}
+	for _, item := range items {
+		fmt.Fprintf(b, "  %s\n", item)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_plan(t *testing.T) {
+	t.Parallel()
+	t.Run("records the release without applying it", func(t *testing.T) {
+		repo := newMockRepoData()
+		m := &mockFS{}
+		plan := &Plan{FS: m}
+		require.NoError(t, BumpIterative(t.Context(), "plugin", Minor, repo, plan))
+
+		assert.Empty(t, m.tagsCreated)
+		assert.Empty(t, m.commits)
+		assert.Equal(t, []string{"plugin/v0.2.0", "runtime/v0.2.4"}, plan.Tags)
+		assert.Equal(t, []PlannedBump{{Path: "runtime/go.mod", Module: "plugin", Version: "v0.2.0"}}, plan.Bumps)
+		assert.Equal(t, []string{"chore: bump plugin/v0.2.0"}, plan.Commits)
+		assert.Equal(t, `Tags to create:
+  plugin/v0.2.0
+  runtime/v0.2.4
+
+go.mod bumps:
+  runtime/go.mod: plugin -> v0.2.0
+
+Commits:
+  chore: bump plugin/v0.2.0
+`, plan.String())
+	})
+	t.Run("empty sections", func(t *testing.T) {
+		plan := &Plan{FS: &mockFS{}}
+		require.NoError(t, BumpIterative(t.Context(), "runtime", Patch, newMockRepoData(), plan))
+		assert.Equal(t, `Tags to create:
+  runtime/v0.2.4
+
+go.mod bumps:
+  (none)
+
+Commits:
+  (none)
+`, plan.String())
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"slices"
 	"strconv"
@@ -64,11 +65,19 @@ func AllowedLevels() string {
 	return strings.Join(quoted, ", ")
 }
 
+// ErrDependencyCycle is returned by [BumpIterative] when modules depend on
+// each other, as there is no order in which they can be bumped.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
 func BumpIterative(ctx context.Context, mod string, level Level, repo RepoData, i FS) error {
 	data, ok := repo[mod]
 	if !ok {
 		return fmt.Errorf("module %s not found", mod)
 	}
+	// refuse before anything gets tagged
+	if cycle := repo.FindCycle(); cycle != nil {
+		return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+	}
 	if err := BumpModule(ctx, mod, level, data, i); err != nil {
 		return err
 	}
@@ -112,6 +121,10 @@ type FS interface {
 	GitTag(ctx context.Context, tag string) error
 	GitCommit(ctx context.Context, msg string) error
 	BumpModInFile(path, module, version string) (bool, error)
+	// GitLog lists the commits reachable from the revision to but not from,
+	// newest first. An empty from lists all the commits, an empty to starts
+	// from HEAD.
+	GitLog(ctx context.Context, from, to string) ([]Commit, error)
 }
 
 func BumpModule(ctx context.Context, mod string, level Level, data ModData, i FS) error {
@@ -186,6 +199,43 @@ func (d RepoData) GetDependencyCount(mod string) int {
 	return len(data.dependencies)
 }
 
+// FindCycle returns the modules of a dependency cycle, starting and ending
+// with the same module, or nil if the modules do not depend on each other.
+func (d RepoData) FindCycle() []string {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(mod string) []string
+	visit = func(mod string) []string {
+		switch state[mod] {
+		case visiting:
+			start := slices.Index(path, mod)
+			return append(slices.Clone(path[start:]), mod)
+		case visited:
+			return nil
+		}
+		state[mod] = visiting
+		path = append(path, mod)
+		for _, dep := range slices.Sorted(maps.Keys(d[mod].dependencies)) {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[mod] = visited
+		return nil
+	}
+	for _, mod := range slices.Sorted(maps.Keys(d)) {
+		if cycle := visit(mod); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
 func (d RepoData) RemoveModule(mod string) {
 	delete(d, mod)
 	for _, v := range d {
@@ -16,6 +16,8 @@ package helper
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"strings"
 	"testing"
 
@@ -63,6 +65,24 @@ func Test_bumpIterative(t *testing.T) {
 		require.Equal(t, 1, len(m.commits))
 		assert.Equal(t, "chore: bump plugin/v0.1.1", m.commits[0])
 	})
+	t.Run("refuses to bump modules depending on each other", func(t *testing.T) {
+		repo := newMockRepoData(withDependency("x", "runtime"))
+		m := &mockFS{}
+		err := BumpIterative(t.Context(), "plugin", Patch, repo, m)
+		require.ErrorIs(t, err, ErrDependencyCycle)
+		assert.ErrorContains(t, err, "client -> x -> runtime -> client")
+		assert.Empty(t, m.tagsCreated)
+		assert.Empty(t, m.bumps)
+		assert.Empty(t, m.commits)
+	})
+	t.Run("refuses a module depending on itself", func(t *testing.T) {
+		repo := newMockRepoData(withDependency("plugin", "plugin"))
+		m := &mockFS{}
+		err := BumpIterative(t.Context(), "x", Patch, repo, m)
+		require.ErrorIs(t, err, ErrDependencyCycle)
+		assert.ErrorContains(t, err, "plugin -> plugin")
+		assert.Empty(t, m.tagsCreated)
+	})
 	t.Run("bump module without dependencies", func(t *testing.T) {
 		repo := newMockRepoData()
 		m := &mockFS{}
@@ -110,19 +130,40 @@ func Test_bumpModule(t *testing.T) {
 	})
 }
 
-func newMockRepoData() RepoData {
+func newMockRepoData(options ...mockRepoOption) RepoData {
 	modules := RepoData{}
 	modules.AddMod("x", Version{Current: "v0.0.3-do.not.use", KeepExtra: true}, []string{})
 	modules.AddMod("plugin", Version{Current: "v0.1.0"}, []string{"x"})
 	modules.AddMod("client", Version{Current: "v1.0.2"}, []string{"x"})
 	modules.AddMod("runtime", Version{Current: "v0.2.3"}, []string{"client", "plugin", "x"})
+	for _, opt := range options {
+		opt(modules)
+	}
 	return modules
 }
 
+type mockRepoOption func(RepoData)
+
+// withDependency makes mod depend on dep as well, e.g. to create a cycle.
+func withDependency(mod, dep string) mockRepoOption {
+	return func(modules RepoData) {
+		deps := slices.Collect(maps.Keys(modules[mod].dependencies))
+		modules.AddMod(mod, modules[mod].Version, append(deps, dep))
+	}
+}
+
 type mockFS struct {
 	tagsCreated []string
 	bumps       []bump
 	commits     []string
+	// log is returned by GitLog, whatever the range
+	log       []Commit
+	logRanges [][2]string
+}
+
+func (m *mockFS) GitLog(_ context.Context, from, to string) ([]Commit, error) {
+	m.logRanges = append(m.logRanges, [2]string{from, to})
+	return m.log, nil
 }
 
 func (m *mockFS) GitCommit(_ context.Context, commit string) error {
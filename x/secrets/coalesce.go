@@ -0,0 +1,146 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type coalescingResolver struct {
+	resolver Resolver
+
+	mu    sync.Mutex
+	calls map[coalesceKey]*coalescedCall
+}
+
+// coalesceKey identifies the calls that can be shared.
+type coalesceKey struct {
+	scope   string
+	pattern string
+}
+
+type callerScopeKey struct{}
+
+// WithCallerScope returns a copy of ctx carrying the scope of the caller,
+// e.g. the identity of the client a request comes from. [Coalesce] only
+// shares a call between callers of the same scope.
+func WithCallerScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, callerScopeKey{}, scope)
+}
+
+// coalescedCall is a fetch shared by all the concurrent callers asking for
+// the same pattern.
+type coalescedCall struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	// guarded by coalescingResolver.mu
+	waiters int
+
+	done      chan struct{}
+	envelopes []Envelope
+	err       error
+}
+
+// Coalesce returns a [Resolver] sharing a single call to r between concurrent
+// GetSecrets calls for the same pattern, so that a burst of identical requests
+// only reaches r once. Callers of different scopes, see [WithCallerScope],
+// never share a call.
+//
+// The shared call is detached from the cancellation and deadlines of the
+// callers: a caller giving up returns with the error of its context without
+// aborting the call for the others still waiting. The call is only cancelled
+// once all of them gave up, with the cause of the last one. The values of the
+// context of the shared call are those of the first caller.
+//
+// Each caller gets its own copy of the envelopes, so that clearing the value
+// of a secret does not affect the others.
+func Coalesce(r Resolver) Resolver {
+	return &coalescingResolver{
+		resolver: r,
+		calls:    map[coalesceKey]*coalescedCall{},
+	}
+}
+
+func (c *coalescingResolver) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	scope, _ := ctx.Value(callerScopeKey{}).(string)
+	key := coalesceKey{scope: scope, pattern: pattern.String()}
+
+	c.mu.Lock()
+	call, ok := c.calls[key]
+	if !ok {
+		shared, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+		call = &coalescedCall{ctx: shared, cancel: cancel, done: make(chan struct{})}
+		c.calls[key] = call
+		go c.fetch(key, call, pattern)
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return cloneEnvelopes(call.envelopes), call.err
+	case <-ctx.Done():
+		c.leave(key, call, context.Cause(ctx))
+		return nil, context.Cause(ctx)
+	}
+}
+
+func (c *coalescingResolver) fetch(key coalesceKey, call *coalescedCall, pattern Pattern) {
+	envelopes, err := c.resolver.GetSecrets(call.ctx, pattern)
+	if errors.Is(err, context.Canceled) && call.ctx.Err() != nil {
+		// report why the last caller gave up
+		err = context.Cause(call.ctx)
+	}
+
+	c.mu.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	c.mu.Unlock()
+
+	call.envelopes, call.err = envelopes, err
+	call.cancel(nil)
+	close(call.done)
+}
+
+// leave removes a caller that gave up from call, and cancels call with cause
+// when it was the last one waiting.
+func (c *coalescingResolver) leave(key coalesceKey, call *coalescedCall, cause error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	// later callers must not join a call that is being cancelled
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	call.cancel(cause)
+}
+
+func cloneEnvelopes(envelopes []Envelope) []Envelope {
+	if envelopes == nil {
+		return nil
+	}
+	clones := make([]Envelope, len(envelopes))
+	for i, e := range envelopes {
+		clones[i] = e.Clone()
+	}
+	return clones
+}
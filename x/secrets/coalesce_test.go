@@ -0,0 +1,184 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingResolver counts its calls and blocks them until release is closed
+// or their context is done.
+type blockingResolver struct {
+	calls   atomic.Int32
+	release chan struct{}
+	causes  chan error
+}
+
+func newBlockingResolver() *blockingResolver {
+	return &blockingResolver{release: make(chan struct{}), causes: make(chan error, 1)}
+}
+
+func (b *blockingResolver) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	b.calls.Add(1)
+	select {
+	case <-b.release:
+		return []Envelope{{ID: MustParseID(pattern.String()), Value: []byte("value")}}, nil
+	case <-ctx.Done():
+		b.causes <- context.Cause(ctx)
+		return nil, ctx.Err()
+	}
+}
+
+// waitForWaiters waits until n callers joined the call for pattern.
+func waitForWaiters(t *testing.T, r Resolver, pattern string, n int) {
+	t.Helper()
+	c := r.(*coalescingResolver)
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		call, ok := c.calls[coalesceKey{pattern: pattern}]
+		return ok && call.waiters == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestCoalesce(t *testing.T) {
+	t.Run("concurrent identical requests share a single call", func(t *testing.T) {
+		inner := newBlockingResolver()
+		r := Coalesce(inner)
+		const n = 20
+		results := make([][]Envelope, n)
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Go(func() {
+				results[i], errs[i] = r.GetSecrets(t.Context(), MustParsePattern("db/password"))
+			})
+		}
+		waitForWaiters(t, r, "db/password", n)
+		close(inner.release)
+		wg.Wait()
+
+		assert.EqualValues(t, 1, inner.calls.Load())
+		for i := range n {
+			require.NoError(t, errs[i])
+			require.Len(t, results[i], 1)
+			assert.Equal(t, []byte("value"), results[i][0].Value)
+		}
+		clear(results[0][0].Value)
+		assert.Equal(t, []byte("value"), results[1][0].Value, "callers must get their own copy")
+
+		_, err := r.GetSecrets(t.Context(), MustParsePattern("db/password"))
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, inner.calls.Load(), "completed calls must not be reused")
+	})
+	t.Run("different patterns are not coalesced", func(t *testing.T) {
+		inner := newBlockingResolver()
+		close(inner.release)
+		r := Coalesce(inner)
+		var wg sync.WaitGroup
+		for _, pattern := range []string{"a", "b"} {
+			wg.Go(func() {
+				_, err := r.GetSecrets(t.Context(), MustParsePattern(pattern))
+				assert.NoError(t, err)
+			})
+		}
+		wg.Wait()
+		assert.EqualValues(t, 2, inner.calls.Load())
+	})
+	t.Run("callers of different scopes do not share a call", func(t *testing.T) {
+		inner := newBlockingResolver()
+		r := Coalesce(inner)
+		var wg sync.WaitGroup
+		for _, scope := range []string{"alice", "bob"} {
+			wg.Go(func() {
+				_, err := r.GetSecrets(WithCallerScope(t.Context(), scope), MustParsePattern("db/password"))
+				assert.NoError(t, err)
+			})
+		}
+		// both calls are in progress at the same time
+		require.Eventually(t, func() bool {
+			return inner.calls.Load() == 2
+		}, time.Second, time.Millisecond)
+		close(inner.release)
+		wg.Wait()
+	})
+	t.Run("a caller giving up does not abort the call for the others", func(t *testing.T) {
+		inner := newBlockingResolver()
+		r := Coalesce(inner)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancelled := make(chan error)
+		go func() {
+			_, err := r.GetSecrets(ctx, MustParsePattern("a"))
+			cancelled <- err
+		}()
+		waitForWaiters(t, r, "a", 1)
+		done := make(chan error)
+		go func() {
+			_, err := r.GetSecrets(t.Context(), MustParsePattern("a"))
+			done <- err
+		}()
+		waitForWaiters(t, r, "a", 2)
+
+		cancel()
+		assert.ErrorIs(t, <-cancelled, context.Canceled)
+		waitForWaiters(t, r, "a", 1)
+		close(inner.release)
+		assert.NoError(t, <-done)
+		assert.EqualValues(t, 1, inner.calls.Load())
+	})
+	t.Run("the call is cancelled once every caller gave up", func(t *testing.T) {
+		inner := newBlockingResolver()
+		r := Coalesce(inner)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		_, err := r.GetSecrets(ctx, MustParsePattern("a"))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, <-inner.causes, context.Canceled)
+	})
+	t.Run("the deadline of a caller does not apply to the others", func(t *testing.T) {
+		inner := newBlockingResolver()
+		r := Coalesce(inner)
+		done := make(chan error, 1)
+		go func() {
+			_, err := r.GetSecrets(t.Context(), MustParsePattern("a"))
+			done <- err
+		}()
+		waitForWaiters(t, r, "a", 1)
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err := r.GetSecrets(ctx, MustParsePattern("a"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(inner.release)
+		assert.NoError(t, <-done)
+		assert.EqualValues(t, 1, inner.calls.Load())
+	})
+	t.Run("the call gets the deadline cause of the last caller", func(t *testing.T) {
+		inner := newBlockingResolver()
+		r := Coalesce(inner)
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err := r.GetSecrets(ctx, MustParsePattern("a"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, <-inner.causes, context.DeadlineExceeded)
+	})
+}
@@ -16,6 +16,7 @@ package secrets
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -24,9 +25,17 @@ type ErrInvalidID struct {
 }
 
 func (e ErrInvalidID) Error() string {
-	return fmt.Sprintf("invalid identifier: %q must match ^[A-Za-z0-9._:-]+(?:/[A-Za-z0-9._:-]+)*$", e.ID)
+	msg := fmt.Sprintf("invalid identifier: %q must match ^[A-Za-z0-9._:-]+(?:/[A-Za-z0-9._:-]+)*$", e.ID)
+	if strings.ContainsAny(e.ID, globMetaChars) {
+		msg += "; identifiers cannot contain wildcards, use a pattern to match several secrets"
+	}
+	return msg
 }
 
+// globMetaChars are the characters with a special meaning in patterns,
+// which are never part of an identifier.
+const globMetaChars = "*?{}!"
+
 // validIdentifier checks if an identifier is valid without using regexp or unicode.
 // Rules:
 // - Components separated by '/'
@@ -74,6 +83,27 @@ func split(s string) []string {
 	return strings.Split(s, "/")
 }
 
+// inlineComponents is the number of components [splitInto] handles without
+// allocating, which covers most identifiers and patterns.
+const inlineComponents = 16
+
+// splitInto appends the components of s to dst. Matching splits into arrays
+// of [inlineComponents] on the stack, so that it does not allocate.
+func splitInto(dst []string, s string) []string {
+	s = strings.Trim(s, "/")
+	if s == "" {
+		return dst
+	}
+	for {
+		component, rest, found := strings.Cut(s, "/")
+		dst = append(dst, component)
+		if !found {
+			return dst
+		}
+		s = rest
+	}
+}
+
 func match(pattern, path []string) bool {
 	pi, si := 0, 0
 	for pi < len(pattern) && si < len(path) {
@@ -141,8 +171,9 @@ type ID interface {
 type id string
 
 func (i id) Match(pattern Pattern) bool {
-	pathParts := split(string(i))
-	patternParts := split(pattern.String())
+	var pathBuf, patternBuf [inlineComponents]string
+	pathParts := splitInto(pathBuf[:0], string(i))
+	patternParts := appendPatternComponents(patternBuf[:0], pattern)
 
 	return match(patternParts, pathParts)
 }
@@ -174,3 +205,50 @@ func MustParseID(s string) ID {
 	}
 	return id(s)
 }
+
+// LatestVersion is the [VersionedID.Version] referencing the current
+// version of a secret.
+const LatestVersion = 0
+
+// VersionedID is an [ID] optionally referencing a previous version of the
+// secret, written "db/password@3". The ID is kept apart from the version so
+// that it can be matched against patterns as usual.
+type VersionedID struct {
+	ID ID
+	// Version is the version of the secret, numbered from 1, or
+	// [LatestVersion] for the current one.
+	Version int
+}
+
+// String formats the [VersionedID] so that [ParseVersionedID] parses it back.
+// The current version has no suffix.
+func (v VersionedID) String() string {
+	if v.Version == LatestVersion {
+		return v.ID.String()
+	}
+	return v.ID.String() + "@" + strconv.Itoa(v.Version)
+}
+
+// ParseVersionedID parses an [ID] followed by an optional "@version" suffix,
+// where version is a positive number or "latest". Without suffix, or with
+// "@latest", the current version is referenced.
+//
+// The ID itself follows the rules of [ParseID], which never allow '@', so
+// the suffix cannot be mistaken for part of the ID.
+func ParseVersionedID(s string) (VersionedID, error) {
+	base, version, hasVersion := strings.Cut(s, "@")
+	parsed, err := ParseID(base)
+	if err != nil {
+		return VersionedID{}, err
+	}
+	result := VersionedID{ID: parsed, Version: LatestVersion}
+	if !hasVersion || version == "latest" {
+		return result, nil
+	}
+	n, err := strconv.Atoi(version)
+	if err != nil || n < 1 || strconv.Itoa(n) != version {
+		return VersionedID{}, fmt.Errorf("invalid version %q of secret %s: must be a positive number or \"latest\"", version, parsed)
+	}
+	result.Version = n
+	return result, nil
+}
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseIDNew(t *testing.T) {
@@ -45,6 +46,56 @@ func TestParseIDNew(t *testing.T) {
 	}
 }
 
+func TestParseIDRejectsWildcards(t *testing.T) {
+	for _, c := range []string{"*", "?", "{", "}", "!"} {
+		t.Run(c, func(t *testing.T) {
+			id := "my/secret" + c
+			_, err := ParseID(id)
+			assert.ErrorIs(t, err, ErrInvalidID{id})
+			assert.ErrorContains(t, err, "use a pattern")
+		})
+	}
+	_, err := ParseID("my secret")
+	assert.NotContains(t, err.Error(), "use a pattern")
+}
+
+func TestParseVersionedID(t *testing.T) {
+	tests := []struct {
+		input     string
+		id        string
+		version   int
+		formatted string
+	}{
+		{"db/password", "db/password", LatestVersion, "db/password"},
+		{"db/password@latest", "db/password", LatestVersion, "db/password"},
+		{"db/password@3", "db/password", 3, "db/password@3"},
+		{"db/pass:word@12", "db/pass:word", 12, "db/pass:word@12"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := ParseVersionedID(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.id, v.ID.String())
+			assert.Equal(t, tc.version, v.Version)
+			assert.Equal(t, tc.formatted, v.String())
+
+			again, err := ParseVersionedID(v.String())
+			require.NoError(t, err)
+			assert.Equal(t, v, again)
+		})
+	}
+
+	for _, input := range []string{"db/password@", "db/password@0", "db/password@-1", "db/password@03", "db/password@next", "db/password@1@2", "db@1/password", "@1"} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, err := ParseVersionedID(input)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := ParseID("db/password@3")
+	assert.ErrorAs(t, err, &ErrInvalidID{}, "'@' is never part of an ID")
+}
+
 func TestIDComparable(t *testing.T) {
 	a := MustParseID("foo")
 	b := MustParseID("foo")
@@ -107,6 +158,41 @@ func TestMatchNew(t *testing.T) {
 			ids:      []string{"foo/bar", "foo/bar/baz/qux"},
 			expected: false,
 		},
+		{
+			pattern:  "a/*",
+			ids:      []string{"a/b"},
+			expected: true,
+		},
+		{
+			pattern:  "a/*",
+			ids:      []string{"a", "a/b/c", "b/a"},
+			expected: false,
+		},
+		{
+			pattern:  "a/**",
+			ids:      []string{"a", "a/b", "a/b/c"},
+			expected: true,
+		},
+		{
+			pattern:  "a/**",
+			ids:      []string{"b", "ab/c"},
+			expected: false,
+		},
+		{
+			pattern:  "*",
+			ids:      []string{"a"},
+			expected: true,
+		},
+		{
+			pattern:  "*",
+			ids:      []string{"a/b"},
+			expected: false,
+		},
+		{
+			pattern:  "**/*",
+			ids:      []string{"a", "a/b", "a/b/c"},
+			expected: true,
+		},
 		{
 			pattern:  "com.test.test/**",
 			ids:      []string{"com.test.test/test/bob", "com.test.test/test/alice"},
@@ -0,0 +1,66 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// Lister can optionally be implemented by a [Resolver] able to enumerate the
+// IDs of its secrets without fetching their values, e.g. a LIST instead of a
+// READ on a remote secret manager, which is cheaper and can be allowed by
+// different access rules.
+type Lister interface {
+	// ListSecrets returns the IDs of the secrets matching pattern, or
+	// [ErrNotFound] if there is none.
+	ListSecrets(ctx context.Context, pattern Pattern) ([]ID, error)
+}
+
+// ListSecrets returns the sorted IDs of the secrets of r matching pattern,
+// for callers that only need to know which secrets exist.
+//
+// It uses [Lister] when r implements it. Otherwise it falls back to
+// [Resolver.GetSecrets] and clears the values it got.
+func ListSecrets(ctx context.Context, r Resolver, pattern Pattern) ([]ID, error) {
+	var ids []ID
+	if l, ok := r.(Lister); ok {
+		listed, err := l.ListSecrets(ctx, pattern)
+		if err != nil {
+			return nil, err
+		}
+		ids = listed
+	} else {
+		envelopes, err := r.GetSecrets(ctx, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, envelope := range envelopes {
+			clear(envelope.Value)
+			ids = append(ids, envelope.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, ErrNotFound
+	}
+
+	slices.SortFunc(ids, func(a, b ID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return slices.CompactFunc(ids, func(a, b ID) bool {
+		return a.String() == b.String()
+	}), nil
+}
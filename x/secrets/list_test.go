@@ -0,0 +1,65 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listingResolver is a [Lister] that must never be asked for values.
+type listingResolver struct {
+	ids []ID
+}
+
+func (l listingResolver) GetSecrets(context.Context, Pattern) ([]Envelope, error) {
+	panic("GetSecrets must not be called on a Lister")
+}
+
+func (l listingResolver) ListSecrets(_ context.Context, pattern Pattern) ([]ID, error) {
+	var ids []ID
+	for _, id := range l.ids {
+		if pattern.Match(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func TestListSecrets(t *testing.T) {
+	t.Run("uses the lister", func(t *testing.T) {
+		r := listingResolver{ids: []ID{MustParseID("foo/b"), MustParseID("foo/a"), MustParseID("bar/c"), MustParseID("foo/a")}}
+		ids, err := ListSecrets(t.Context(), r, MustParsePattern("foo/*"))
+		require.NoError(t, err)
+		assert.Equal(t, []ID{MustParseID("foo/a"), MustParseID("foo/b")}, ids)
+
+		_, err = ListSecrets(t.Context(), r, MustParsePattern("baz/*"))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("falls back to resolving and clears the values", func(t *testing.T) {
+		value := []byte("secret")
+		r := staticResolver{{ID: MustParseID("foo/a"), Value: value}, {ID: MustParseID("bar/b")}}
+		ids, err := ListSecrets(t.Context(), r, MustParsePattern("foo/*"))
+		require.NoError(t, err)
+		assert.Equal(t, []ID{MustParseID("foo/a")}, ids)
+		assert.Equal(t, make([]byte, len(value)), value)
+
+		_, err = ListSecrets(t.Context(), r, MustParsePattern("baz/*"))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// GetSecretsMany resolves several patterns through r concurrently and returns
+// the union of the secrets matching any of them, sorted with [SortEnvelopes].
+// A secret matched by more than one pattern is returned once.
+//
+// Patterns matching no secret are ignored, [ErrNotFound] is only returned
+// when none of the patterns matched a secret.
+func GetSecretsMany(ctx context.Context, r Resolver, patterns ...Pattern) ([]Envelope, error) {
+	if len(patterns) == 0 {
+		return nil, errors.New("at least one pattern is required")
+	}
+
+	results := make([][]Envelope, len(patterns))
+	errs := make([]error, len(patterns))
+	var wg sync.WaitGroup
+	for i, pattern := range patterns {
+		wg.Go(func() {
+			results[i], errs[i] = r.GetSecrets(ctx, pattern)
+		})
+	}
+	wg.Wait()
+
+	var envelopes []Envelope
+	for i, err := range errs {
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, results[i]...)
+	}
+	if len(envelopes) == 0 {
+		return nil, ErrNotFound
+	}
+	return UniqueEnvelopes(envelopes), nil
+}
+
+// UniqueEnvelopes sorts envelopes with [SortEnvelopes] and removes the
+// duplicates, i.e. envelopes of the same [ID] and provider, in place.
+func UniqueEnvelopes(envelopes []Envelope) []Envelope {
+	SortEnvelopes(envelopes)
+	return slices.CompactFunc(envelopes, func(a, b Envelope) bool {
+		return CompareEnvelopes(a, b) == 0
+	})
+}
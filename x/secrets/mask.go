@@ -0,0 +1,77 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// fullMask is the placeholder printed instead of a fully masked value. Its
+// length is fixed so that it does not reveal the length of the value.
+const fullMask = "**********"
+
+// Masker turns a secret value into a representation that is safe to print or
+// log. Implementations must never include the raw value in their output.
+type Masker interface {
+	Mask(value []byte) string
+}
+
+// MaskerFunc adapts a function into a [Masker].
+type MaskerFunc func(value []byte) string
+
+func (f MaskerFunc) Mask(value []byte) string {
+	return f(value)
+}
+
+// FullMasker hides the value entirely, including its length.
+type FullMasker struct{}
+
+func (FullMasker) Mask([]byte) string {
+	return fullMask
+}
+
+// PartialMasker reveals the first and last Reveal characters of a value and,
+// if ShowLength is set, its length in bytes, e.g. "a********z (26 bytes)".
+//
+// Characters are only revealed for valid UTF-8 values holding at least four
+// times as many characters as revealed; shorter or binary values are fully
+// masked.
+type PartialMasker struct {
+	Reveal     int
+	ShowLength bool
+}
+
+func (p PartialMasker) Mask(value []byte) string {
+	masked := fullMask
+	if p.Reveal > 0 && utf8.Valid(value) && utf8.RuneCount(value) >= 4*p.Reveal {
+		runes := []rune(string(value))
+		masked = string(runes[:p.Reveal]) + strings.Repeat("*", 8) + string(runes[len(runes)-p.Reveal:])
+	}
+	if p.ShowLength {
+		masked += " (" + strconv.Itoa(len(value)) + " bytes)"
+	}
+	return masked
+}
+
+var _ Masker = FullMasker{}
+var _ Masker = PartialMasker{}
+
+// Mask returns a representation of value that is safe to print or log,
+// using [FullMasker].
+func Mask(value []byte) string {
+	return FullMasker{}.Mask(value)
+}
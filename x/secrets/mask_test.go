@@ -0,0 +1,63 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMask(t *testing.T) {
+	value := []byte("s3cr3t-v4lu3-th4t-must-n0t-l34k")
+
+	t.Run("full mask hides value and length", func(t *testing.T) {
+		assert.Equal(t, "**********", Mask(value))
+		assert.Equal(t, Mask([]byte("x")), Mask(value))
+		assert.Equal(t, "**********", FullMasker{}.Mask(nil))
+	})
+	t.Run("partial mask reveals first and last characters", func(t *testing.T) {
+		assert.Equal(t, "s********k", PartialMasker{Reveal: 1}.Mask(value))
+		assert.Equal(t, "s3********4k", PartialMasker{Reveal: 2}.Mask(value))
+		assert.Equal(t, "s********k (31 bytes)", PartialMasker{Reveal: 1, ShowLength: true}.Mask(value))
+	})
+	t.Run("partial mask reveals whole characters", func(t *testing.T) {
+		assert.Equal(t, "é********ü", PartialMasker{Reveal: 1}.Mask([]byte("éabcdü")))
+	})
+	t.Run("partial mask hides short and binary values", func(t *testing.T) {
+		assert.Equal(t, "**********", PartialMasker{Reveal: 1}.Mask([]byte("abc")))
+		assert.Equal(t, "**********", PartialMasker{Reveal: 1}.Mask([]byte{0xff, 0xfe, 0xfd, 0xfc}))
+		assert.Equal(t, "********** (3 bytes)", PartialMasker{ShowLength: true}.Mask([]byte("abc")))
+	})
+	t.Run("no raw value leaks", func(t *testing.T) {
+		maskers := []Masker{
+			FullMasker{},
+			PartialMasker{Reveal: 1},
+			PartialMasker{Reveal: 3, ShowLength: true},
+			PartialMasker{Reveal: 100},
+		}
+		for _, m := range maskers {
+			masked := m.Mask(value)
+			assert.NotContains(t, masked, string(value))
+			assert.NotContains(t, masked, "v4lu3")
+			assert.NotContains(t, masked, string(value[len(value)/4:3*len(value)/4]))
+		}
+	})
+	t.Run("masker func", func(t *testing.T) {
+		m := MaskerFunc(func(v []byte) string { return strings.Repeat("#", len(v)) })
+		assert.Equal(t, "###", m.Mask([]byte("abc")))
+	})
+}
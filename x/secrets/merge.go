@@ -0,0 +1,113 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// PartialError is returned along with the resolved secrets by a resolver
+// created with [MergeResolvers] when some of the underlying resolvers failed
+// while others resolved secrets.
+//
+// Callers that are fine with a partial result can check for it with
+// [errors.As] and use the secrets returned alongside.
+type PartialError struct {
+	Errs []error
+}
+
+func (e *PartialError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("partial result, %d resolver(s) failed: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+func (e *PartialError) Unwrap() []error {
+	return e.Errs
+}
+
+type mergedResolver []Resolver
+
+// MergeResolvers returns a [Resolver] presenting the secrets of all the
+// resolvers as a single view, e.g. a local and a remote engine.
+//
+// GetSecrets queries the resolvers concurrently. When several of them return
+// a secret with the same [ID], the one of the resolver listed first wins. The
+// merged secrets are sorted, see [SortEnvelopes].
+//
+// [ErrNotFound] is returned only if none of the resolvers found a secret.
+// Failures of some resolvers do not hide the secrets of the others: they are
+// returned together with a [*PartialError]. If no secret is found and some
+// resolvers failed, their errors are returned without any secret.
+func MergeResolvers(resolvers ...Resolver) Resolver {
+	return mergedResolver(resolvers)
+}
+
+func (m mergedResolver) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	type result struct {
+		envelopes []Envelope
+		err       error
+	}
+	results := make([]result, len(m))
+	var wg sync.WaitGroup
+	for i, r := range m {
+		wg.Go(func() {
+			envelopes, err := r.GetSecrets(ctx, pattern)
+			results[i] = result{envelopes: envelopes, err: err}
+		})
+	}
+	wg.Wait()
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	var envelopes []Envelope
+	var errs []error
+	for _, r := range results {
+		if errors.Is(r.err, ErrNotFound) {
+			continue
+		}
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		for _, e := range r.envelopes {
+			if _, ok := seen[e.ID.String()]; ok {
+				continue
+			}
+			seen[e.ID.String()] = struct{}{}
+			envelopes = append(envelopes, e)
+		}
+	}
+
+	if len(envelopes) == 0 {
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return nil, ErrNotFound
+	}
+	SortEnvelopes(envelopes)
+	if len(errs) > 0 {
+		return envelopes, &PartialError{Errs: errs}
+	}
+	return envelopes, nil
+}
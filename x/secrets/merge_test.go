@@ -0,0 +1,86 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeResolvers(t *testing.T) {
+	local := staticResolver{
+		{ID: MustParseID("shared"), Value: []byte("local"), Provider: "local"},
+		{ID: MustParseID("local/a"), Value: []byte("a"), Provider: "local"},
+	}
+	remote := staticResolver{
+		{ID: MustParseID("shared"), Value: []byte("remote"), Provider: "remote"},
+		{ID: MustParseID("remote/b"), Value: []byte("b"), Provider: "remote"},
+	}
+	errResolver := errors.New("resolver error")
+	failing := resolverFunc(func(context.Context, Pattern) ([]Envelope, error) {
+		return nil, errResolver
+	})
+
+	t.Run("merges disjoint and dedupes overlapping secrets by order", func(t *testing.T) {
+		result, err := MergeResolvers(local, remote).GetSecrets(t.Context(), MustParsePattern("**"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"local/a", "remote/b", "shared"}, ids(result))
+		assert.Equal(t, "local", result[2].Provider)
+
+		result, err = MergeResolvers(remote, local).GetSecrets(t.Context(), MustParsePattern("shared"))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, []byte("remote"), result[0].Value)
+	})
+	t.Run("not found only when no resolver finds a secret", func(t *testing.T) {
+		result, err := MergeResolvers(local, remote).GetSecrets(t.Context(), MustParsePattern("remote/*"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"remote/b"}, ids(result))
+
+		_, err = MergeResolvers(local, remote).GetSecrets(t.Context(), MustParsePattern("missing"))
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = MergeResolvers().GetSecrets(t.Context(), MustParsePattern("**"))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("partial errors are returned with the secrets", func(t *testing.T) {
+		result, err := MergeResolvers(failing, local).GetSecrets(t.Context(), MustParsePattern("**"))
+		var partial *PartialError
+		require.ErrorAs(t, err, &partial)
+		assert.ErrorIs(t, err, errResolver)
+		assert.Equal(t, []string{"local/a", "shared"}, ids(result))
+	})
+	t.Run("errors are returned when nothing is found", func(t *testing.T) {
+		result, err := MergeResolvers(failing, local).GetSecrets(t.Context(), MustParsePattern("missing"))
+		assert.ErrorIs(t, err, errResolver)
+		assert.NotErrorIs(t, err, ErrNotFound)
+		var partial *PartialError
+		assert.False(t, errors.As(err, &partial))
+		assert.Nil(t, result)
+	})
+	t.Run("context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		blocking := resolverFunc(func(ctx context.Context, _ Pattern) ([]Envelope, error) {
+			cancel()
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		_, err := MergeResolvers(local, blocking).GetSecrets(ctx, MustParsePattern("**"))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -80,10 +80,20 @@ func isValidPatternRune(c rune) bool {
 // Pattern can be used to match secret identifiers.
 // Valid patterns must follow the same validation rules as secret identifiers, with the exception
 // that '*' can be used to match a single component, and '**' can be used to match zero or more components.
+//
+// Examples:
+//   - "a/*" matches "a/b" but neither "a" nor "a/b/c"
+//   - "a/**" matches "a", "a/b" and "a/b/c"
+//   - "a/**/c" matches "a/c" and "a/b/c"
 type Pattern interface {
 	// Match the [Pattern] against an [ID]
 	Match(id ID) bool
 	// Includes returns true if all matches of Pattern [other] are also matches of the current pattern.
+	//
+	// A '*' component never includes a '**' component, as the latter can
+	// span any number of components. A literal component of the current
+	// pattern is considered to include a '*' component of [other] so that
+	// overlapping patterns can be narrowed with [Filter].
 	Includes(other Pattern) bool
 	// String formats the [Pattern] as a string
 	String() string
@@ -92,26 +102,200 @@ type Pattern interface {
 	ExpandPattern(other Pattern) (Pattern, error)
 }
 
-type pattern string
+// pattern is a [Pattern] with wildcards. Its components are split once when
+// it is compiled, so that matching does not split it again. All its fields
+// derive from s, so patterns stay comparable and can be used as map keys.
+type pattern struct {
+	s string
+	// n is the number of components, or -1 if there are more than
+	// [inlineComponents] and they are split when matching instead.
+	n          int
+	components [inlineComponents]string
+}
+
+// compilePattern splits the valid pattern s into its components.
+func compilePattern(s string) pattern {
+	p := pattern{s: s, n: -1}
+	if strings.Count(s, "/") < inlineComponents {
+		p.n = len(splitInto(p.components[:0], s))
+	}
+	return p
+}
+
+// appendComponents appends the components of p to dst.
+func (p *pattern) appendComponents(dst []string) []string {
+	if p.n < 0 {
+		return splitInto(dst, p.s)
+	}
+	return append(dst, p.components[:p.n]...)
+}
+
+// appendPatternComponents appends the components of p to dst, using the
+// compiled form of the patterns created by this package.
+func appendPatternComponents(dst []string, p Pattern) []string {
+	if p, ok := p.(pattern); ok {
+		return p.appendComponents(dst)
+	}
+	return splitInto(dst, p.String())
+}
 
 func (p pattern) Match(id ID) bool {
-	pathParts := split(id.String())
-	patternParts := split(string(p))
+	var pathBuf, patternBuf [inlineComponents]string
+	pathParts := splitInto(pathBuf[:0], id.String())
+	patternParts := p.appendComponents(patternBuf[:0])
 
 	return match(patternParts, pathParts)
 }
 
 func (p pattern) Includes(other Pattern) bool {
-	otherParts := split(other.String())
-	patternParts := split(string(p))
+	var otherBuf, patternBuf [inlineComponents]string
+	otherParts := appendPatternComponents(otherBuf[:0], other)
+	patternParts := p.appendComponents(patternBuf[:0])
+
+	return includes(patternParts, otherParts)
+}
+
+// anyComponent stands in for an arbitrary component when expanding '**'.
+// It can never be part of a valid pattern, so only '*' and '**' match it.
+const anyComponent = ""
+
+// includes checks that pattern matches every expansion of other, where each
+// '**' component of other is expanded into any number of arbitrary
+// components. Empty expansions are ignored as IDs always have at least one
+// component.
+//
+// Pattern is run as an automaton over the components of other, whose states
+// are the positions in pattern: every set of positions an expansion of other
+// can lead to must contain the end of pattern. Runs reaching the same
+// positions are merged, and a '**' of other is expanded until its runs
+// repeat, so that the work is bounded by the distinct sets of positions
+// instead of the number of expansions, which grows exponentially with the
+// number of '**'.
+func includes(pattern, other []string) bool {
+	type run struct {
+		states positions
+		// read is set once a component was read, i.e. the expansion is not
+		// empty
+		read bool
+	}
+	runs := []run{{states: closure(pattern, newPositions(len(pattern), 0))}}
+	for _, component := range other {
+		seen := map[string]bool{}
+		var next []run
+		add := func(r run) bool {
+			key := fmt.Sprintf("%t/%s", r.read, r.states)
+			if seen[key] {
+				return false
+			}
+			seen[key] = true
+			next = append(next, r)
+			return true
+		}
+		for _, r := range runs {
+			if component != "**" {
+				add(run{states: step(pattern, r.states, component), read: true})
+				continue
+			}
+			// '**' expands into zero or more arbitrary components, read
+			// them until the runs repeat
+			for add(r) {
+				r = run{states: step(pattern, r.states, anyComponent), read: true}
+			}
+		}
+		runs = next
+	}
+	for _, r := range runs {
+		if r.read && !r.states[len(pattern)] {
+			return false
+		}
+	}
+	return true
+}
+
+// positions is a set of positions in a pattern, len(pattern) being its end.
+type positions []bool
+
+func newPositions(patternLen int, initial ...int) positions {
+	p := make(positions, patternLen+1)
+	for _, i := range initial {
+		p[i] = true
+	}
+	return p
+}
+
+func (p positions) String() string {
+	b := make([]byte, len(p))
+	for i, set := range p {
+		b[i] = '0'
+		if set {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
+
+// closure adds to states the positions following a '**' component, which
+// can match no component.
+func closure(pattern []string, states positions) positions {
+	for i, component := range pattern {
+		if states[i] && component == "**" {
+			states[i+1] = true
+		}
+	}
+	return states
+}
 
-	return match(patternParts, otherParts)
+// step returns the positions in pattern reached by matching component from
+// states. A literal component of pattern matches a '*' component, see
+// [Pattern.Includes].
+func step(pattern []string, states positions, component string) positions {
+	next := newPositions(len(pattern))
+	for i, p := range pattern {
+		if !states[i] {
+			continue
+		}
+		switch {
+		case p == "**":
+			next[i] = true
+		case p == "*", p == component && component != anyComponent, component == "*":
+			next[i+1] = true
+		}
+	}
+	return closure(pattern, next)
 }
 
 func (p pattern) String() string {
+	return p.s
+}
+
+// exactPattern is a [Pattern] without wildcards, which only matches the [ID]
+// with the same string. It is what single secret lookups use, so it matches
+// with a string comparison instead of evaluating components.
+type exactPattern string
+
+func (p exactPattern) Match(id ID) bool {
+	return id.String() == string(p)
+}
+
+func (p exactPattern) Includes(other Pattern) bool {
+	if other, ok := other.(exactPattern); ok {
+		return p == other
+	}
+	return compilePattern(string(p)).Includes(other)
+}
+
+func (p exactPattern) String() string {
 	return string(p)
 }
 
+func (p exactPattern) ExpandID(other ID) (ID, error) {
+	return compilePattern(string(p)).ExpandID(other)
+}
+
+func (p exactPattern) ExpandPattern(other Pattern) (Pattern, error) {
+	return compilePattern(string(p)).ExpandPattern(other)
+}
+
 // ParsePattern parses a string into a [Pattern]
 // Rules:
 // - Components separated by '/'
@@ -121,24 +305,41 @@ func (p pattern) String() string {
 // - Asterisks rules:
 //   - '*' cannot be mixed with other characters in the same component
 //   - there can be no more than two '*' per component
+//
+// Patterns with wildcards are compiled once and kept in a bounded cache, so
+// that parsing the same pattern again reuses its compiled form. Patterns
+// without wildcards need no compilation: they only match the [ID] equal to
+// them.
 func ParsePattern(s string) (Pattern, error) {
+	if !strings.Contains(s, "*") {
+		if !validIdentifier(s) {
+			return nil, ErrInvalidPattern
+		}
+		return exactPattern(s), nil
+	}
 	if !validPattern(s) {
 		return nil, ErrInvalidPattern
 	}
-	return pattern(s), nil
+	if p, ok := compiledPatterns.get(s); ok {
+		return p, nil
+	}
+	var p Pattern = compilePattern(s)
+	compiledPatterns.add(s, p)
+	return p, nil
 }
 
 // MustParsePattern parses a string into a [Pattern] like with [ParsePattern],
 // however, it panics when a validation error occurs.
 func MustParsePattern(s string) Pattern {
-	if !validPattern(s) {
-		panic(ErrInvalidPattern)
+	p, err := ParsePattern(s)
+	if err != nil {
+		panic(err)
 	}
-	return pattern(s)
+	return p
 }
 
 func (p pattern) ExpandID(other ID) (ID, error) {
-	val, err := replace1(string(p), other.String())
+	val, err := replace1(p.s, other.String())
 	if err != nil {
 		return nil, err
 	}
@@ -146,11 +347,14 @@ func (p pattern) ExpandID(other ID) (ID, error) {
 }
 
 func (p pattern) ExpandPattern(other Pattern) (Pattern, error) {
-	val, err := replace1(string(p), other.String())
+	val, err := replace1(p.s, other.String())
 	if err != nil {
 		return nil, err
 	}
-	return pattern(val), err
+	if !strings.Contains(val, "*") {
+		return exactPattern(val), nil
+	}
+	return compilePattern(val), err
 }
 
 // Filter returns a reduced [Pattern] that is subset equal to [filter].
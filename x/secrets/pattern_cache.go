@@ -0,0 +1,95 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"container/list"
+	"sync"
+)
+
+// patternCacheSize bounds the number of compiled patterns kept by
+// [ParsePattern], evicting the least recently used ones first.
+const patternCacheSize = 512
+
+// patternCache is an LRU cache of compiled patterns, keyed by the pattern
+// string. It is only used when parsing: compiled patterns carry their
+// components, so matching never looks them up.
+type patternCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type patternCacheEntry struct {
+	pattern string
+	// compiled is kept as a [Pattern] so that cache hits do not allocate.
+	compiled Pattern
+}
+
+func newPatternCache(size int) *patternCache {
+	return &patternCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+var compiledPatterns = newPatternCache(patternCacheSize)
+
+func (c *patternCache) get(s string) (Pattern, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[s]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*patternCacheEntry).compiled, true
+}
+
+func (c *patternCache) add(s string, compiled Pattern) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[s]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[s] = c.lru.PushFront(&patternCacheEntry{pattern: s, compiled: compiled})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*patternCacheEntry).pattern)
+	}
+}
+
+func (c *patternCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.lru.Init()
+}
+
+func (c *patternCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// ClearPatternCache drops all the patterns compiled by [ParsePattern] and
+// [MustParsePattern]. It is meant for tests and benchmarks.
+func ClearPatternCache() {
+	compiledPatterns.clear()
+}
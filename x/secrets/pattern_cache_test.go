@@ -0,0 +1,80 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternCache(t *testing.T) {
+	t.Run("parsed patterns are compiled once", func(t *testing.T) {
+		ClearPatternCache()
+		t.Cleanup(ClearPatternCache)
+
+		p := MustParsePattern("foo/**")
+		require.Equal(t, 1, compiledPatterns.len())
+		assert.Zero(t, testing.AllocsPerRun(10, func() {
+			assert.Equal(t, p, MustParsePattern("foo/**"))
+		}))
+		assert.True(t, p.Match(MustParseID("foo/bar/baz")))
+		assert.True(t, MustParseID("foo/bar").Match(p))
+		assert.Equal(t, 1, compiledPatterns.len())
+
+		ClearPatternCache()
+		assert.Zero(t, compiledPatterns.len())
+		assert.True(t, p.Match(MustParseID("foo/bar")), "cleared patterns keep their compiled form")
+		assert.Equal(t, p, MustParsePattern("foo/**"), "recompiled patterns are equal")
+	})
+	t.Run("invalid and exact patterns are not cached", func(t *testing.T) {
+		ClearPatternCache()
+		t.Cleanup(ClearPatternCache)
+
+		_, err := ParsePattern("foo//*")
+		assert.ErrorIs(t, err, ErrInvalidPattern)
+		MustParsePattern("foo/bar")
+		assert.Zero(t, compiledPatterns.len())
+	})
+	t.Run("patterns with many components", func(t *testing.T) {
+		ClearPatternCache()
+		t.Cleanup(ClearPatternCache)
+
+		long := strings.Repeat("a/", inlineComponents) + "**"
+		p := MustParsePattern(long)
+		assert.True(t, p.Match(MustParseID(strings.Repeat("a/", inlineComponents)+"b/c")))
+		assert.False(t, p.Match(MustParseID(strings.Repeat("a/", inlineComponents-1)+"b")))
+		assert.True(t, p.Includes(MustParsePattern(long+"/c")))
+		assert.True(t, MustParsePattern("**").Includes(p))
+	})
+	t.Run("evicts least recently used patterns", func(t *testing.T) {
+		c := newPatternCache(2)
+		c.add("a/*", compilePattern("a/*"))
+		c.add("b/*", compilePattern("b/*"))
+		_, ok := c.get("a/*")
+		require.True(t, ok)
+		c.add("c/*", compilePattern("c/*"))
+
+		_, ok = c.get("b/*")
+		assert.False(t, ok)
+		_, ok = c.get("a/*")
+		assert.True(t, ok)
+		_, ok = c.get("c/*")
+		assert.True(t, ok)
+		assert.Equal(t, 2, c.len())
+	})
+}
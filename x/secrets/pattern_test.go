@@ -16,6 +16,9 @@ package secrets
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -44,6 +47,9 @@ func TestParsePattern(t *testing.T) {
 		{"invalid pattern with empty component", "foo//bar", ErrInvalidPattern},
 		{"invalid empty pattern", "", ErrInvalidPattern},
 		{"invalid pattern only with slash", "/", ErrInvalidPattern},
+		{"invalid pattern with triple asterisk", "a/***", ErrInvalidPattern},
+		{"invalid pattern with empty component next to wildcard", "a//**", ErrInvalidPattern},
+		{"invalid pattern with trailing slash after wildcard", "a/*/", ErrInvalidPattern},
 		{"invalid pattern with components and a mix of asterisks and allowed characters", "foo/*a*/baz", ErrInvalidPattern},
 	}
 	for _, tc := range tests {
@@ -63,6 +69,36 @@ func TestPatternComparable(t *testing.T) {
 	myMap[MustParsePattern("foo")] = bar
 	assert.Equal(t, bar, myMap[a])
 	assert.Equal(t, bar, myMap[b])
+
+	wildcard := MustParsePattern("foo/**")
+	ClearPatternCache()
+	myMap[wildcard] = bar
+	assert.Equal(t, bar, myMap[MustParsePattern("foo/**")])
+}
+
+func TestExactPattern(t *testing.T) {
+	p := MustParsePattern("foo/bar")
+	require.IsType(t, exactPattern(""), p, "patterns without wildcards use the exact matcher")
+	assert.True(t, p.Match(MustParseID("foo/bar")))
+	assert.True(t, MustParseID("foo/bar").Match(p))
+	for _, id := range []string{"foo", "foo/bar/baz", "foo/ba", "Foo/bar", "foo/bar."} {
+		assert.False(t, p.Match(MustParseID(id)), id)
+	}
+
+	// the exact matcher must agree with the glob one
+	for _, other := range []string{"foo/bar", "foo/baz", "foo/*", "foo/**", "*/bar", "**"} {
+		o := MustParsePattern(other)
+		assert.Equal(t, compilePattern("foo/bar").Includes(o), p.Includes(o), other)
+	}
+
+	for _, invalid := range []string{"/foo", "foo/", "foo//bar", "foo bar", "foo@1"} {
+		_, err := ParsePattern(invalid)
+		assert.ErrorIs(t, err, ErrInvalidPattern, invalid)
+	}
+
+	expanded, err := MustParsePattern("foo/**").ExpandPattern(MustParsePattern("bar"))
+	require.NoError(t, err)
+	assert.Equal(t, MustParsePattern("foo/bar"), expanded)
 }
 
 func TestPatternIncludes(t *testing.T) {
@@ -93,6 +129,16 @@ func TestPatternIncludes(t *testing.T) {
 		{"docker/proj1/**", "docker/*/mcp/*", true},
 		{"docker/proj1/**", "docker/**/mcp/**", false},
 		{"docker/**", "docker/**/mcp/**", true},
+		{"*", "**", false},
+		{"*/*", "*/**", false},
+		{"a/*", "a/**", false},
+		{"a/*", "a/*/*", false},
+		{"a/*", "a/b/c", false},
+		{"a/**", "a/*", true},
+		{"a/**", "a/b/c", true},
+		{"a/**", "a", true},
+		{"a/**/c", "a/*/c", true},
+		{"a/*/c", "a/**/c", false},
 	}
 	for idx, tc := range tests {
 		t.Run(fmt.Sprintf("pattern %d", idx+1), func(t *testing.T) {
@@ -105,6 +151,59 @@ func TestPatternIncludes(t *testing.T) {
 	}
 }
 
+func TestPatternIncludesManyDoubleStars(t *testing.T) {
+	many := "a/" + strings.Repeat("**/", 24) + "b"
+	for _, tc := range []struct {
+		pattern, other  string
+		otherIsIncluded bool
+	}{
+		{many, many, true},
+		{"a/**/b", many, true},
+		{many, "a/**/b", true},
+		{"a/*/**/b", many, false},
+		{many, "a/" + strings.Repeat("*/", 24) + "c", false},
+	} {
+		assert.Equal(t, tc.otherIsIncluded, MustParsePattern(tc.pattern).Includes(MustParsePattern(tc.other)), "%s includes %s", tc.pattern, tc.other)
+	}
+}
+
+// includesByExpansion is the reference implementation of includes: it tries
+// every expansion of the '**' components of other into up to limit arbitrary
+// components.
+func includesByExpansion(pattern, other []string, limit int) bool {
+	idx := slices.Index(other, "**")
+	if idx < 0 {
+		return len(other) == 0 || match(pattern, other)
+	}
+	for n := 0; n <= limit; n++ {
+		expanded := slices.Concat(other[:idx], slices.Repeat([]string{anyComponent}, n), other[idx+1:])
+		if !includesByExpansion(pattern, expanded, limit) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPatternIncludesAgreesWithExpansion(t *testing.T) {
+	patterns := [][]string{{}}
+	for range 3 {
+		for _, p := range patterns {
+			if len(p) == 3 {
+				continue
+			}
+			for _, component := range []string{"a", "b", "*", "**"} {
+				patterns = append(patterns, append(slices.Clone(p), component))
+			}
+		}
+	}
+	patterns = slices.DeleteFunc(patterns, func(p []string) bool { return len(p) == 0 })
+	for _, pattern := range patterns {
+		for _, other := range patterns {
+			assert.Equal(t, includesByExpansion(pattern, other, len(pattern)+1), includes(pattern, other), "%v includes %v", pattern, other)
+		}
+	}
+}
+
 func Test_Filter(t *testing.T) {
 	tests := []struct {
 		filter string
@@ -224,3 +323,54 @@ func Test_apply(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkPatternMatch(b *testing.B) {
+	ids := make([]ID, 16)
+	for i := range ids {
+		ids[i] = MustParseID("team/project/secret" + strconv.Itoa(i))
+	}
+
+	b.Run("parsed", func(b *testing.B) {
+		ClearPatternCache()
+		p := MustParsePattern("**")
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			p.Match(ids[i%len(ids)])
+		}
+	})
+	b.Run("parse and match", func(b *testing.B) {
+		ClearPatternCache()
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			MustParsePattern("team/**").Match(ids[i%len(ids)])
+		}
+	})
+	b.Run("compile and match", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			compilePattern("team/**").Match(ids[i%len(ids)])
+		}
+	})
+}
+
+func BenchmarkExactPatternMatch(b *testing.B) {
+	ids := make([]ID, 16)
+	for i := range ids {
+		ids[i] = MustParseID("team/project/secret" + strconv.Itoa(i))
+	}
+
+	b.Run("exact", func(b *testing.B) {
+		p := MustParsePattern("team/project/secret3")
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			p.Match(ids[i%len(ids)])
+		}
+	})
+	b.Run("glob", func(b *testing.B) {
+		p := compilePattern("team/project/secret3")
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			p.Match(ids[i%len(ids)])
+		}
+	})
+}
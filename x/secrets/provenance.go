@@ -0,0 +1,57 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"slices"
+)
+
+// AddProviderHop returns a copy of e with name appended to its
+// [Envelope.ProviderChain]. When the chain is empty, it is first seeded with
+// [Envelope.Provider], so that the chain starts at the provider the secret
+// originates from.
+func (e Envelope) AddProviderHop(name string) Envelope {
+	chain := e.ProviderChain
+	if len(chain) == 0 && e.Provider != "" && e.Provider != name {
+		chain = []string{e.Provider}
+	}
+	e.ProviderChain = append(slices.Clip(chain), name)
+	return e
+}
+
+type provenanceResolver struct {
+	name     string
+	resolver Resolver
+}
+
+// RecordProvenance wraps r so that name is appended to the
+// [Envelope.ProviderChain] of every secret it resolves, see
+// [Envelope.AddProviderHop].
+//
+// Wrapping each layer of a composed topology, e.g. a cache in front of an
+// engine in front of a plugin, makes it possible to tell where a value
+// actually came from.
+func RecordProvenance(name string, r Resolver) Resolver {
+	return provenanceResolver{name: name, resolver: r}
+}
+
+func (p provenanceResolver) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	envelopes, err := p.resolver.GetSecrets(ctx, pattern)
+	for i, envelope := range envelopes {
+		envelopes[i] = envelope.AddProviderHop(p.name)
+	}
+	return envelopes, err
+}
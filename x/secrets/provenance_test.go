@@ -0,0 +1,53 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordProvenance(t *testing.T) {
+	plugin := staticResolver{{ID: MustParseID("foo"), Provider: "plugin-foo"}}
+	engine := RecordProvenance("engine", plugin)
+	cache := RecordProvenance("cache", engine)
+
+	envelopes, err := cache.GetSecrets(t.Context(), MustParsePattern("**"))
+	require.NoError(t, err)
+	require.Len(t, envelopes, 1)
+	assert.Equal(t, []string{"plugin-foo", "engine", "cache"}, envelopes[0].ProviderChain)
+	assert.Equal(t, "plugin-foo", envelopes[0].Provider, "the provider must not change")
+
+	t.Run("the wrapped resolver is not modified", func(t *testing.T) {
+		_, err := cache.GetSecrets(t.Context(), MustParsePattern("**"))
+		require.NoError(t, err)
+		assert.Empty(t, plugin[0].ProviderChain)
+	})
+	t.Run("branches do not share the chain", func(t *testing.T) {
+		seeded := staticResolver{{ID: MustParseID("foo"), ProviderChain: []string{"plugin-foo", "engine"}}}
+		a, err := RecordProvenance("a", seeded).GetSecrets(t.Context(), MustParsePattern("**"))
+		require.NoError(t, err)
+		b, err := RecordProvenance("b", seeded).GetSecrets(t.Context(), MustParsePattern("**"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"plugin-foo", "engine", "a"}, a[0].ProviderChain)
+		assert.Equal(t, []string{"plugin-foo", "engine", "b"}, b[0].ProviderChain)
+	})
+	t.Run("errors are returned", func(t *testing.T) {
+		_, err := RecordProvenance("cache", staticResolver{}).GetSecrets(t.Context(), MustParsePattern("**"))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"sync"
+)
+
+var _ Resolver = &Restricted{}
+
+// Restricted is a [Resolver] that only exposes an explicit set of secret IDs
+// of the underlying resolver.
+//
+// It starts with an empty allow-set, so nothing is resolved until [Restricted.Allow]
+// is called. All methods are safe for concurrent use. The allow-set is
+// consulted once the underlying resolver returns, so an ID revoked while a
+// [Restricted.GetSecrets] call is in flight is not part of its result.
+type Restricted struct {
+	resolver Resolver
+
+	mu      sync.RWMutex
+	allowed map[string]struct{}
+}
+
+// NewRestricted returns a [Restricted] resolver wrapping r.
+func NewRestricted(r Resolver) *Restricted {
+	return &Restricted{
+		resolver: r,
+		allowed:  map[string]struct{}{},
+	}
+}
+
+// Allow adds the IDs to the allow-set.
+func (r *Restricted) Allow(ids ...ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		r.allowed[id.String()] = struct{}{}
+	}
+}
+
+// Revoke removes the IDs from the allow-set. Revoking an ID that is not
+// allowed is a no-op.
+func (r *Restricted) Revoke(ids ...ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		delete(r.allowed, id.String())
+	}
+}
+
+// GetSecrets resolves the pattern through the underlying resolver and only
+// returns the secrets part of the allow-set.
+//
+// It returns [ErrNotFound] when none of the resolved secrets are allowed.
+func (r *Restricted) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	envelopes, err := r.resolver.GetSecrets(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []Envelope
+	for _, envelope := range envelopes {
+		if _, ok := r.allowed[envelope.ID.String()]; ok {
+			result = append(result, envelope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrNotFound
+	}
+	return result, nil
+}
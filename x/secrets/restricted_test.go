@@ -0,0 +1,166 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticResolver []Envelope
+
+func (s staticResolver) GetSecrets(_ context.Context, pattern Pattern) ([]Envelope, error) {
+	var result []Envelope
+	for _, e := range s {
+		if pattern.Match(e.ID) {
+			result = append(result, e)
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrNotFound
+	}
+	return result, nil
+}
+
+func ids(envelopes []Envelope) []string {
+	var result []string
+	for _, e := range envelopes {
+		result = append(result, e.ID.String())
+	}
+	return result
+}
+
+func TestRestricted(t *testing.T) {
+	resolver := staticResolver{
+		{ID: MustParseID("foo/a"), Value: []byte("a")},
+		{ID: MustParseID("foo/b"), Value: []byte("b")},
+		{ID: MustParseID("bar"), Value: []byte("bar")},
+	}
+
+	t.Run("nothing is allowed by default", func(t *testing.T) {
+		r := NewRestricted(resolver)
+		_, err := r.GetSecrets(t.Context(), MustParsePattern("**"))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("only allowed secrets are returned", func(t *testing.T) {
+		r := NewRestricted(resolver)
+		r.Allow(MustParseID("foo/a"), MustParseID("bar"))
+		result, err := r.GetSecrets(t.Context(), MustParsePattern("foo/*"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"foo/a"}, ids(result))
+
+		result, err = r.GetSecrets(t.Context(), MustParsePattern("**"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"foo/a", "bar"}, ids(result))
+	})
+	t.Run("disallowed secret is not found", func(t *testing.T) {
+		r := NewRestricted(resolver)
+		r.Allow(MustParseID("foo/a"))
+		_, err := r.GetSecrets(t.Context(), MustParsePattern("foo/b"))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("revoked secret is not found", func(t *testing.T) {
+		r := NewRestricted(resolver)
+		r.Allow(MustParseID("foo/a"), MustParseID("foo/b"))
+		r.Revoke(MustParseID("foo/a"), MustParseID("unknown"))
+		result, err := r.GetSecrets(t.Context(), MustParsePattern("foo/**"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"foo/b"}, ids(result))
+	})
+	t.Run("resolver errors are returned", func(t *testing.T) {
+		errResolver := errors.New("resolver error")
+		r := NewRestricted(resolverFunc(func(context.Context, Pattern) ([]Envelope, error) {
+			return nil, errResolver
+		}))
+		r.Allow(MustParseID("foo/a"))
+		_, err := r.GetSecrets(t.Context(), MustParsePattern("foo/a"))
+		assert.ErrorIs(t, err, errResolver)
+	})
+	t.Run("revoke while in flight", func(t *testing.T) {
+		resolving := make(chan struct{})
+		revoked := make(chan struct{})
+		r := NewRestricted(resolverFunc(func(ctx context.Context, p Pattern) ([]Envelope, error) {
+			close(resolving)
+			<-revoked
+			return resolver.GetSecrets(ctx, p)
+		}))
+		r.Allow(MustParseID("foo/a"))
+		go func() {
+			<-resolving
+			r.Revoke(MustParseID("foo/a"))
+			close(revoked)
+		}()
+		_, err := r.GetSecrets(t.Context(), MustParsePattern("foo/a"))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+type resolverFunc func(context.Context, Pattern) ([]Envelope, error)
+
+func (f resolverFunc) GetSecrets(ctx context.Context, pattern Pattern) ([]Envelope, error) {
+	return f(ctx, pattern)
+}
+
+// TestRestrictedConcurrency is meant to be run with the race detector. Every
+// result must only contain IDs that were allowed at some point and never the
+// ones that are never allowed.
+func TestRestrictedConcurrency(t *testing.T) {
+	var resolver staticResolver
+	var allowable []ID
+	for i := range 20 {
+		allowed := MustParseID(fmt.Sprintf("allowed/%d", i))
+		allowable = append(allowable, allowed)
+		resolver = append(resolver,
+			Envelope{ID: allowed},
+			Envelope{ID: MustParseID(fmt.Sprintf("denied/%d", i))},
+		)
+	}
+	r := NewRestricted(resolver)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 100 {
+				id := allowable[(i+j)%len(allowable)]
+				if j%2 == 0 {
+					r.Allow(id)
+				} else {
+					r.Revoke(id)
+				}
+			}
+		})
+		wg.Go(func() {
+			for range 100 {
+				result, err := r.GetSecrets(t.Context(), MustParsePattern("**"))
+				if errors.Is(err, ErrNotFound) {
+					continue
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+				for _, e := range result {
+					assert.True(t, e.ID.Match(MustParsePattern("allowed/*")), "unexpected secret %s", e.ID)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
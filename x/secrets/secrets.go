@@ -15,9 +15,13 @@
 package secrets
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -35,10 +39,69 @@ type Envelope struct {
 	CreatedAt  time.Time         `json:"-"`
 	ResolvedAt time.Time         `json:"-"`
 	ExpiresAt  time.Time         `json:"-"`
+	// ProviderChain lists the layers the secret went through, from the
+	// provider it originates from to the outermost decorator. It is only
+	// populated by resolvers recording their provenance, see
+	// [RecordProvenance]. Provider remains the immediate source.
+	ProviderChain []string `json:"-"`
 }
 
 var _ json.Marshaler = Envelope{}
 
+// Clone returns a deep copy of the envelope, so that the value and metadata
+// of the copy can be modified or cleared without affecting the original.
+func (e Envelope) Clone() Envelope {
+	e.Value = slices.Clone(e.Value)
+	e.Metadata = maps.Clone(e.Metadata)
+	e.ProviderChain = slices.Clone(e.ProviderChain)
+	return e
+}
+
+// NewEnvelopeWithTTL returns an envelope for a secret that is valid for ttl
+// from now, e.g. short-lived credentials issued on demand. ResolvedAt is set
+// to now and ExpiresAt to ResolvedAt + ttl; a ttl <= 0 leaves ExpiresAt unset,
+// meaning the secret does not expire.
+//
+// Resolvers caching secrets never serve an envelope past its ExpiresAt, see
+// plugin.CachingResolver.
+func NewEnvelopeWithTTL(id ID, value []byte, ttl time.Duration) Envelope {
+	e := Envelope{
+		ID:         id,
+		Value:      value,
+		ResolvedAt: time.Now(),
+	}
+	if ttl > 0 {
+		e.ExpiresAt = e.ResolvedAt.Add(ttl)
+	}
+	return e
+}
+
+// CompareEnvelopes orders envelopes by [ID] and then by provider, returning
+// -1, 0 or +1 like [strings.Compare]. Envelopes without an ID come first.
+func CompareEnvelopes(a, b Envelope) int {
+	return cmp.Or(
+		strings.Compare(envelopeID(a), envelopeID(b)),
+		strings.Compare(a.Provider, b.Provider),
+	)
+}
+
+func envelopeID(e Envelope) string {
+	if e.ID == nil {
+		return ""
+	}
+	return e.ID.String()
+}
+
+// SortEnvelopes sorts envelopes in place by [ID] and then by provider, see
+// [CompareEnvelopes]. The sort is stable: envelopes with the same ID and
+// provider keep their relative order.
+//
+// Resolvers that merge secrets from several sources should sort their
+// results so that callers get the same order for the same secrets.
+func SortEnvelopes(envelopes []Envelope) {
+	slices.SortStableFunc(envelopes, CompareEnvelopes)
+}
+
 func (e Envelope) MarshalJSON() ([]byte, error) {
 	panic("secrets.Envelope does not support json.Marshal")
 }
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +37,75 @@ func TestEnvelopeJSON(t *testing.T) {
 	}()
 	_, _ = json.Marshal(envelope)
 }
+
+func TestEnvelopeClone(t *testing.T) {
+	original := Envelope{
+		ID:        MustParseID("foo/bar"),
+		Value:     []byte("secret"),
+		Metadata:  map[string]string{"key": "value"},
+		Provider:  "test",
+		ExpiresAt: time.Now(),
+	}
+	clone := original.Clone()
+	assert.Equal(t, original, clone)
+
+	clear(clone.Value)
+	clone.Metadata["key"] = "changed"
+	assert.Equal(t, []byte("secret"), original.Value)
+	assert.Equal(t, map[string]string{"key": "value"}, original.Metadata)
+
+	assert.Equal(t, Envelope{}, Envelope{}.Clone(), "zero value is preserved")
+}
+
+func TestNewEnvelopeWithTTL(t *testing.T) {
+	before := time.Now()
+	e := NewEnvelopeWithTTL(MustParseID("db/creds"), []byte("secret"), time.Minute)
+	assert.Equal(t, "db/creds", e.ID.String())
+	assert.Equal(t, []byte("secret"), e.Value)
+	assert.False(t, e.ResolvedAt.Before(before))
+	assert.Equal(t, e.ResolvedAt.Add(time.Minute), e.ExpiresAt)
+
+	assert.True(t, NewEnvelopeWithTTL(MustParseID("db/creds"), nil, 0).ExpiresAt.IsZero(), "no ttl means no expiry")
+}
+
+func TestSortEnvelopes(t *testing.T) {
+	newEnvelopes := func() []Envelope {
+		return []Envelope{
+			{ID: MustParseID("foo"), Provider: "plugin-foo", Value: []byte("1")},
+			{ID: MustParseID("bar"), Provider: "plugin-b"},
+			{ID: MustParseID("foo"), Provider: "plugin-bar"},
+			{ID: MustParseID("foo"), Provider: "plugin-foo", Value: []byte("2")},
+			{Provider: "no-id"},
+			{ID: MustParseID("bar"), Provider: "plugin-a"},
+		}
+	}
+	type key struct {
+		id, provider, value string
+	}
+	keys := func(envelopes []Envelope) []key {
+		var k []key
+		for _, e := range envelopes {
+			k = append(k, key{envelopeID(e), e.Provider, string(e.Value)})
+		}
+		return k
+	}
+
+	expected := []key{
+		{"", "no-id", ""},
+		{"bar", "plugin-a", ""},
+		{"bar", "plugin-b", ""},
+		{"foo", "plugin-bar", ""},
+		{"foo", "plugin-foo", "1"},
+		{"foo", "plugin-foo", "2"},
+	}
+	for range 10 {
+		envelopes := newEnvelopes()
+		SortEnvelopes(envelopes)
+		assert.Equal(t, expected, keys(envelopes))
+	}
+
+	envelopes := newEnvelopes()
+	SortEnvelopes(envelopes)
+	SortEnvelopes(envelopes)
+	assert.Equal(t, expected, keys(envelopes), "sorting is stable")
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// consoleExporter writes spans and metrics as JSON lines to a writer.
+// It is meant for local debugging without a collector.
+type consoleExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newConsoleExporter(w io.Writer) *consoleExporter {
+	return &consoleExporter{enc: json.NewEncoder(w)}
+}
+
+var (
+	_ sdktrace.SpanExporter = &consoleExporter{}
+	_ sdkmetric.Exporter    = &consoleExporter{}
+)
+
+type consoleSpan struct {
+	Name         string         `json:"name"`
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Kind         string         `json:"kind"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Status       string         `json:"status"`
+	Description  string         `json:"description,omitempty"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+}
+
+func (c *consoleExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range spans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		span := consoleSpan{
+			Name:        s.Name(),
+			TraceID:     s.SpanContext().TraceID().String(),
+			SpanID:      s.SpanContext().SpanID().String(),
+			Kind:        s.SpanKind().String(),
+			Start:       s.StartTime(),
+			End:         s.EndTime(),
+			Status:      s.Status().Code.String(),
+			Description: s.Status().Description,
+			Attributes:  attributesMap(s.Attributes()),
+		}
+		if s.Parent().IsValid() {
+			span.ParentSpanID = s.Parent().SpanID().String()
+		}
+		if err := c.enc.Encode(span); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func attributesMap(attrs []attribute.KeyValue) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	for _, kv := range attrs {
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return m
+}
+
+func (c *consoleExporter) Temporality(k sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(k)
+}
+
+func (c *consoleExporter) Aggregation(k sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(k)
+}
+
+func (c *consoleExporter) Export(ctx context.Context, data *metricdata.ResourceMetrics) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enc.Encode(data.ScopeMetrics)
+}
+
+func (c *consoleExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (c *consoleExporter) Shutdown(context.Context) error {
+	return nil
+}
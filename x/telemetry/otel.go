@@ -15,15 +15,24 @@
 package telemetry
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"os"
 	"path"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/propagation"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -35,12 +44,53 @@ import (
 
 type ShutdownFunc func(ctx context.Context)
 
+const (
+	// TracesExporterEnv selects the exporter used for traces.
+	TracesExporterEnv = "OTEL_TRACES_EXPORTER"
+	// MetricsExporterEnv selects the exporter used for metrics.
+	MetricsExporterEnv = "OTEL_METRICS_EXPORTER"
+	// PrometheusHostEnv sets the host [ExporterPrometheus] listens on, it
+	// defaults to localhost.
+	PrometheusHostEnv = "OTEL_EXPORTER_PROMETHEUS_HOST"
+	// PrometheusPortEnv sets the port [ExporterPrometheus] listens on, it
+	// defaults to 9464.
+	PrometheusPortEnv = "OTEL_EXPORTER_PROMETHEUS_PORT"
+)
+
+// Exporter names supported in [TracesExporterEnv] and [MetricsExporterEnv].
+const (
+	// ExporterOTLP sends telemetry to the OTLP endpoint passed to
+	// [InitializeOTel]. It is used when no exporter is selected.
+	ExporterOTLP = "otlp"
+	// ExporterConsole writes telemetry as JSON lines to stderr.
+	ExporterConsole = "console"
+	// ExporterPrometheus serves the metrics under /metrics for Prometheus to
+	// scrape, see [PrometheusHostEnv] and [PrometheusPortEnv]. It only
+	// supports metrics.
+	ExporterPrometheus = "prometheus"
+	// ExporterNone disables the signal.
+	ExporterNone = "none"
+)
+
 // InitializeOTel sets up OTEL with meter/metrics and tracer providers
+//
+// The exporters are selected with the [TracesExporterEnv] and
+// [MetricsExporterEnv] environment variables, following the OTEL
+// conventions. They default to [ExporterOTLP].
 func InitializeOTel(ctx context.Context, endpoint string) (ShutdownFunc, error) {
 	logger, err := logging.FromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	tracesExporter, err := exporterFromEnv(TracesExporterEnv)
+	if err != nil {
+		return nil, err
+	}
+	metricsExporter, err := exporterFromEnv(MetricsExporterEnv)
+	if err != nil {
+		return nil, err
+	}
+
 	otel.SetErrorHandler(&errorhandler{logger: logger})
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
 		propagation.TraceContext{},
@@ -51,21 +101,121 @@ func InitializeOTel(ctx context.Context, endpoint string) (ShutdownFunc, error)
 
 	var secure bool
 	endpoint, secure = sanitizeEndpoint(endpoint)
-	tracerProvider := createTraceProvider(ctx, res, endpoint, secure)
-	metricsProvider := createMetricProvider(ctx, res, endpoint, secure)
+
+	var metricsProvider *sdkmetric.MeterProvider
+	stopServer := func(context.Context) error { return nil }
+	switch metricsExporter {
+	case ExporterOTLP:
+		metricsProvider = createMetricProvider(ctx, res, endpoint, secure)
+	case ExporterConsole:
+		metricsProvider = newMeterProvider(res, newConsoleExporter(os.Stderr))
+	case ExporterPrometheus:
+		l, err := net.Listen("tcp", prometheusAddress())
+		if err != nil {
+			return nil, fmt.Errorf("serving prometheus metrics: %w", err)
+		}
+		metricsProvider, stopServer, err = newPrometheusProvider(res, l)
+		if err != nil {
+			_ = l.Close()
+			return nil, err
+		}
+	}
+	var tracerProvider *sdktrace.TracerProvider
+	switch tracesExporter {
+	case ExporterOTLP:
+		tracerProvider = createTraceProvider(ctx, res, endpoint, secure)
+	case ExporterConsole:
+		tracerProvider = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(newConsoleExporter(os.Stderr)),
+			sdktrace.WithResource(res),
+		)
+	}
+
 	cleanup := func(ctx context.Context) {
-		if err := tracerProvider.Shutdown(ctx); err != nil {
-			logger.Warnf("Tracer provider did not shut down cleanly: %s", err)
+		if tracerProvider != nil {
+			if err := tracerProvider.Shutdown(ctx); err != nil {
+				logger.Warnf("Tracer provider did not shut down cleanly: %s", err)
+			}
 		}
-		if err := metricsProvider.Shutdown(ctx); err != nil {
-			logger.Warnf("Metrics provider did not shut down cleanly: %s", err)
+		if metricsProvider != nil {
+			if err := metricsProvider.Shutdown(ctx); err != nil {
+				logger.Warnf("Metrics provider did not shut down cleanly: %s", err)
+			}
 		}
+		if err := stopServer(ctx); err != nil {
+			logger.Warnf("Prometheus metrics server did not shut down cleanly: %s", err)
+		}
+	}
+	if tracerProvider != nil {
+		otel.SetTracerProvider(tracerProvider)
+	}
+	if metricsProvider != nil {
+		otel.SetMeterProvider(metricsProvider)
 	}
-	otel.SetTracerProvider(tracerProvider)
-	otel.SetMeterProvider(metricsProvider)
 	return cleanup, nil
 }
 
+// exporterFromEnv returns the exporter selected by the environment variable.
+func exporterFromEnv(env string) (string, error) {
+	exporter := strings.ToLower(strings.TrimSpace(os.Getenv(env)))
+	switch exporter {
+	case "":
+		return ExporterOTLP, nil
+	case ExporterOTLP, ExporterConsole, ExporterNone:
+		return exporter, nil
+	case ExporterPrometheus:
+		if env != MetricsExporterEnv {
+			return "", fmt.Errorf("unsupported %s %q, the %s exporter only supports metrics", env, exporter, ExporterPrometheus)
+		}
+		return exporter, nil
+	default:
+		return "", fmt.Errorf("unsupported %s %q, use one of %s, %s, %s or %s", env, exporter, ExporterOTLP, ExporterConsole, ExporterPrometheus, ExporterNone)
+	}
+}
+
+// prometheusAddress returns the address [ExporterPrometheus] listens on.
+func prometheusAddress() string {
+	host := cmp.Or(os.Getenv(PrometheusHostEnv), "localhost")
+	port := cmp.Or(os.Getenv(PrometheusPortEnv), "9464")
+	return net.JoinHostPort(host, port)
+}
+
+// newPrometheusProvider returns a meter provider whose metrics are served on
+// l under /metrics, and a function stopping the server.
+func newPrometheusProvider(res *resource.Resource, l net.Listener) (*sdkmetric.MeterProvider, func(context.Context) error, error) {
+	registry := prometheus.NewRegistry()
+	exp, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			otel.Handle(err)
+		}
+	}()
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(exp),
+		sdkmetric.WithResource(res),
+	)
+	return provider, server.Shutdown, nil
+}
+
+func newMeterProvider(res *resource.Resource, exp sdkmetric.Exporter) *sdkmetric.MeterProvider {
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(
+			exp,
+			sdkmetric.WithTimeout(5*time.Second),
+			sdkmetric.WithInterval(30*time.Second),
+		)),
+		sdkmetric.WithResource(res),
+	)
+}
+
 func createMetricProvider(ctx context.Context, res *resource.Resource, endpoint string, secure bool) *sdkmetric.MeterProvider {
 	expOpts := []otlpmetricgrpc.Option{
 		otlpmetricgrpc.WithEndpoint(endpoint),
@@ -79,14 +229,7 @@ func createMetricProvider(ctx context.Context, res *resource.Resource, endpoint
 		otel.Handle(err)
 		return nil
 	}
-	return sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(
-			exp,
-			sdkmetric.WithTimeout(5*time.Second),
-			sdkmetric.WithInterval(30*time.Second),
-		)),
-		sdkmetric.WithResource(res),
-	)
+	return newMeterProvider(res, exp)
 }
 
 func createTraceProvider(ctx context.Context, res *resource.Resource, endpoint string, secure bool) *sdktrace.TracerProvider {
@@ -0,0 +1,120 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestExporterFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ExporterOTLP},
+		{"otlp", ExporterOTLP},
+		{" Console ", ExporterConsole},
+		{"none", ExporterNone},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv(TracesExporterEnv, tc.value)
+			exporter, err := exporterFromEnv(TracesExporterEnv)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, exporter)
+		})
+	}
+	t.Run("unsupported", func(t *testing.T) {
+		t.Setenv(MetricsExporterEnv, "zipkin")
+		_, err := exporterFromEnv(MetricsExporterEnv)
+		assert.ErrorContains(t, err, `unsupported OTEL_METRICS_EXPORTER "zipkin"`)
+	})
+	t.Run("prometheus only supports metrics", func(t *testing.T) {
+		t.Setenv(MetricsExporterEnv, "prometheus")
+		exporter, err := exporterFromEnv(MetricsExporterEnv)
+		require.NoError(t, err)
+		assert.Equal(t, ExporterPrometheus, exporter)
+
+		t.Setenv(TracesExporterEnv, "prometheus")
+		_, err = exporterFromEnv(TracesExporterEnv)
+		assert.ErrorContains(t, err, `unsupported OTEL_TRACES_EXPORTER "prometheus"`)
+	})
+}
+
+func TestPrometheusAddress(t *testing.T) {
+	assert.Equal(t, "localhost:9464", prometheusAddress())
+	t.Setenv(PrometheusHostEnv, "0.0.0.0")
+	t.Setenv(PrometheusPortEnv, "9000")
+	assert.Equal(t, "0.0.0.0:9000", prometheusAddress())
+}
+
+func TestPrometheusExporter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	provider, stop, err := newPrometheusProvider(resource.Empty(), l)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, provider.Shutdown(context.Background()))
+		assert.NoError(t, stop(context.Background()))
+	})
+
+	counter, err := provider.Meter("test").Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(t.Context(), 3, metric.WithAttributes(attribute.String("plugin", "foo")))
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://"+l.Addr().String()+"/metrics", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `requests_total{otel_scope_name="test",otel_scope_schema_url="",otel_scope_version="",plugin="foo"} 3`)
+}
+
+func TestConsoleExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(newConsoleExporter(buf)))
+	ctx, parent := tp.Tracer("test").Start(t.Context(), "parent")
+	_, child := tp.Tracer("test").Start(ctx, "child")
+	child.SetAttributes(attribute.String("plugin", "foo"))
+	child.End()
+	parent.End()
+	require.NoError(t, tp.Shutdown(t.Context()))
+
+	dec := json.NewDecoder(buf)
+	var childSpan, parentSpan consoleSpan
+	require.NoError(t, dec.Decode(&childSpan))
+	require.NoError(t, dec.Decode(&parentSpan))
+	assert.Equal(t, "child", childSpan.Name)
+	assert.Equal(t, map[string]any{"plugin": "foo"}, childSpan.Attributes)
+	assert.Equal(t, parentSpan.SpanID, childSpan.ParentSpanID)
+	assert.Equal(t, parentSpan.TraceID, childSpan.TraceID)
+	assert.Empty(t, parentSpan.ParentSpanID)
+}
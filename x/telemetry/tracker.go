@@ -14,10 +14,19 @@
 
 package telemetry
 
+import (
+	"context"
+	"sync"
+)
+
 type Tracker interface {
 	TrackEvent(event any)
 
 	Notify(err error, rawData ...interface{})
+
+	// Flush blocks until all the events tracked so far have been delivered,
+	// or the context is done. It should be called before exiting.
+	Flush(ctx context.Context) error
 }
 
 func NoopTracker() Tracker {
@@ -32,18 +41,78 @@ func (n noopTracker) Notify(error, ...interface{}) {
 func (n noopTracker) TrackEvent(any) {
 }
 
+func (n noopTracker) Flush(context.Context) error {
+	return nil
+}
+
+// AsyncWrapper returns a [Tracker] delivering events and errors to tracker
+// in the background. [Tracker.Flush] waits for the pending deliveries before
+// flushing tracker.
 func AsyncWrapper(tracker Tracker) Tracker {
 	return &asyncTracker{tracker: tracker}
 }
 
 type asyncTracker struct {
 	tracker Tracker
+
+	mu      sync.Mutex
+	pending int
+	// idle is closed once pending drops back to zero.
+	idle chan struct{}
+}
+
+func (a *asyncTracker) Notify(err error, rawData ...interface{}) {
+	a.goAsync(func() {
+		a.tracker.Notify(err, rawData...)
+	})
 }
 
-func (a asyncTracker) Notify(err error, rawData ...interface{}) {
-	go a.tracker.Notify(err, rawData...)
+func (a *asyncTracker) TrackEvent(event any) {
+	a.goAsync(func() {
+		a.tracker.TrackEvent(event)
+	})
 }
 
-func (a asyncTracker) TrackEvent(event any) {
-	go a.tracker.TrackEvent(event)
+// goAsync runs fn in the background and keeps track of it until it
+// returns. Unlike a [sync.WaitGroup], the counter may grow while Flush
+// is waiting on it.
+func (a *asyncTracker) goAsync(fn func()) {
+	a.mu.Lock()
+	if a.pending == 0 {
+		a.idle = make(chan struct{})
+	}
+	a.pending++
+	a.mu.Unlock()
+
+	go func() {
+		defer a.done()
+		fn()
+	}()
+}
+
+func (a *asyncTracker) done() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending--
+	if a.pending == 0 {
+		close(a.idle)
+	}
+}
+
+func (a *asyncTracker) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	idle := a.idle
+	if a.pending == 0 {
+		idle = nil
+	}
+	a.mu.Unlock()
+
+	if idle != nil {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+	return a.tracker.Flush(ctx)
 }
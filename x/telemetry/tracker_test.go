@@ -0,0 +1,84 @@
+// Copyright 2026 Docker, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slowTracker struct {
+	noopTracker
+	release chan struct{}
+	mu      sync.Mutex
+	events  []any
+}
+
+func (s *slowTracker) TrackEvent(event any) {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func TestAsyncWrapperFlush(t *testing.T) {
+	t.Run("delivers pending events", func(t *testing.T) {
+		inner := &slowTracker{release: make(chan struct{})}
+		tracker := AsyncWrapper(inner)
+		tracker.TrackEvent("started")
+		time.AfterFunc(10*time.Millisecond, func() { close(inner.release) })
+		require.NoError(t, tracker.Flush(t.Context()))
+		assert.Equal(t, []any{"started"}, inner.events)
+	})
+	t.Run("returns when the context is done", func(t *testing.T) {
+		inner := &slowTracker{release: make(chan struct{})}
+		t.Cleanup(func() { close(inner.release) })
+		tracker := AsyncWrapper(inner)
+		tracker.TrackEvent("started")
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, tracker.Flush(ctx), context.DeadlineExceeded)
+	})
+	t.Run("events can be tracked while flushing", func(t *testing.T) {
+		inner := &slowTracker{release: make(chan struct{})}
+		close(inner.release)
+		tracker := AsyncWrapper(inner)
+
+		var wg sync.WaitGroup
+		for i := range 10 {
+			wg.Go(func() {
+				for j := range 100 {
+					tracker.TrackEvent(i*100 + j)
+					tracker.Notify(assert.AnError)
+				}
+			})
+			wg.Go(func() {
+				for range 100 {
+					assert.NoError(t, tracker.Flush(t.Context()))
+				}
+			})
+		}
+		wg.Wait()
+		require.NoError(t, tracker.Flush(t.Context()))
+		inner.mu.Lock()
+		defer inner.mu.Unlock()
+		assert.Len(t, inner.events, 1000)
+	})
+}
@@ -117,6 +117,10 @@ func (t *testTracker) GetQueue() []any {
 func (t *testTracker) Notify(error, ...interface{}) {
 }
 
+func (t *testTracker) Flush(context.Context) error {
+	return nil
+}
+
 func (t *testTracker) TrackEvent(event any) {
 	t.m.Lock()
 	defer t.m.Unlock()